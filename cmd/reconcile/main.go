@@ -0,0 +1,117 @@
+// Command reconcile checks every family's cached point balances against the
+// point ledger and prints each discrepancy. With -repair the drifted balances
+// are rewritten from the ledger.
+//
+//	PLATFORM_DATABASE_URL=... reconcile [-family slug] [-repair]
+//
+// Without -repair it exits with status 1 when any balance has drifted, so it
+// can run as a scheduled check.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/jobs"
+	"github.com/JunoAX/housepoints-go/internal/ledger"
+	"github.com/JunoAX/housepoints-go/internal/models"
+)
+
+func main() {
+	slug := flag.String("family", "", "only reconcile this family (slug)")
+	repair := flag.Bool("repair", false, "rewrite drifted balances from the ledger")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	platformDBURL := os.Getenv("PLATFORM_DATABASE_URL")
+	if platformDBURL == "" {
+		log.Fatal("PLATFORM_DATABASE_URL environment variable is required")
+	}
+
+	platformDB, err := database.NewPlatformDB(ctx, platformDBURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to platform database: %v", err)
+	}
+	defer platformDB.Close()
+
+	familyDBs := database.NewFamilyDBManager(platformDB)
+	defer familyDBs.Close()
+
+	families, err := platformDB.ListActiveFamilies(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list families: %v", err)
+	}
+
+	drifted, failed := 0, 0
+	for _, family := range families {
+		if *slug != "" && family.Slug != *slug {
+			continue
+		}
+
+		report, err := reconcileFamily(ctx, platformDB, familyDBs, family, *repair)
+		if err != nil {
+			log.Printf("❌ %s: %v", family.Slug, err)
+			failed++
+			continue
+		}
+
+		for _, d := range report.Discrepancies {
+			fmt.Printf("%s\t%s\t%s\tcached=%d\tledger=%d\tdifference=%+d\n",
+				family.Slug, d.Username, d.Field, d.Cached, d.Ledger, d.Difference)
+		}
+		status := "in balance"
+		if report.UsersDrifted > 0 {
+			status = fmt.Sprintf("%d of %d users drifted", report.UsersDrifted, report.UsersChecked)
+			if report.Repaired {
+				status += ", repaired"
+			}
+		}
+		log.Printf("✅ %s: %s", family.Slug, status)
+		drifted += report.UsersDrifted
+	}
+
+	if failed > 0 || (drifted > 0 && !*repair) {
+		os.Exit(1)
+	}
+}
+
+// reconcileFamily reconciles one family's balances in a single transaction
+func reconcileFamily(ctx context.Context, platformDB *database.PlatformDB, familyDBs *database.FamilyDBManager, family *models.Family, repair bool) (models.BalanceReconcileResponse, error) {
+	var report models.BalanceReconcileResponse
+
+	db, err := familyDBs.GetFamilyDB(ctx, family)
+	if err != nil {
+		return report, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	settings, err := platformDB.GetFamilySettings(ctx, family.ID)
+	if err != nil {
+		return report, fmt.Errorf("failed to load settings: %w", err)
+	}
+	weekStart := settings.WeekStart(jobs.LocalDay(time.Now(), settings.Location()))
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return report, err
+	}
+	defer tx.Rollback(ctx)
+
+	report, err = ledger.Reconcile(ctx, tx, weekStart, nil, repair)
+	if err != nil {
+		return report, err
+	}
+	report.DryRun = !repair
+
+	if repair {
+		if err := tx.Commit(ctx); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
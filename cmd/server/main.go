@@ -13,7 +13,9 @@ import (
 	"github.com/JunoAX/housepoints-go/internal/auth"
 	"github.com/JunoAX/housepoints-go/internal/database"
 	"github.com/JunoAX/housepoints-go/internal/handlers"
+	"github.com/JunoAX/housepoints-go/internal/jobs"
 	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/storage"
 	"github.com/gin-gonic/gin"
 )
 
@@ -43,6 +45,20 @@ func main() {
 	defer familyDBManager.Close()
 	log.Println("✅ Family database manager initialized")
 
+	// Start daily background jobs for every family
+	jobCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+	jobRunner := jobs.NewRunner(platformDB, familyDBManager,
+		jobs.PresenceJob{},
+		jobs.OverdueJob{},
+		jobs.StreakJob{},
+		jobs.WeeklyResetJob{},
+		jobs.AllowanceJob{},
+		jobs.IdempotencyCleanupJob{},
+	)
+	go jobRunner.Start(jobCtx)
+	log.Println("✅ Background job runner started")
+
 	// Initialize JWT service
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
@@ -51,6 +67,17 @@ func main() {
 	jwtService := auth.NewJWTService(jwtSecret, "housepoints-go")
 	log.Println("✅ JWT service initialized")
 
+	// Initialize blob storage for photo uploads
+	signingKey := os.Getenv("STORAGE_SIGNING_KEY")
+	if signingKey == "" {
+		signingKey = jwtSecret
+	}
+	fileStore, err := storage.NewFromEnv(signingKey)
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+	log.Println("✅ Blob storage initialized")
+
 	// Initialize Gin
 	r := gin.Default()
 
@@ -136,7 +163,7 @@ func main() {
 
 	// Protected API routes (require authentication)
 	protected := r.Group("/api")
-	protected.Use(middleware.RequireFamily(), middleware.RequireAuth(jwtService))
+	protected.Use(middleware.RequireFamily(), middleware.RequireAuth(jwtService), middleware.Idempotency())
 	{
 		// Auth endpoints
 		protected.GET("/auth/me", handlers.GetCurrentUser) // Alias for /users/me (frontend compatibility)
@@ -152,26 +179,95 @@ func main() {
 		protected.DELETE("/users/:id", handlers.DeleteUser)
 		protected.GET("/users/:id/points", handlers.GetUserPoints)
 		protected.GET("/users/:id/transactions", handlers.GetUserTransactions)
+		protected.POST("/users/:id/points/adjust", handlers.AdjustUserPoints)
 		protected.GET("/users/:id/stats", handlers.GetUserStats)
 		protected.GET("/users/:id/redeemed-rewards", handlers.GetRedeemedRewards)
+		protected.GET("/users/:id/statement", handlers.GetUserStatement(platformDB))
+
+		// Point ledger endpoints
+		protected.POST("/points/reconcile", handlers.ReconcileBalances(platformDB))
+		protected.POST("/transactions/:id/reverse", handlers.ReverseTransaction)
+		protected.GET("/points/adjustments", handlers.ListPointAdjustments)
+		protected.POST("/points/adjustments/:id/approve", handlers.ApprovePointAdjustment)
+		protected.POST("/points/adjustments/:id/reject", handlers.RejectPointAdjustment)
+
+		// Allowance endpoints
+		protected.GET("/allowance/rate", handlers.GetExchangeRate(platformDB))
+		protected.PUT("/allowance/rate", handlers.UpdateExchangeRate(platformDB))
+		protected.GET("/allowance/balances", handlers.GetAllowanceBalances(platformDB))
+		protected.GET("/allowance/conversions", handlers.ListConversions)
+		protected.POST("/allowance/conversions", handlers.RequestConversion(platformDB))
+		protected.POST("/allowance/conversions/:id/approve", handlers.ApproveConversion)
+		protected.POST("/allowance/conversions/:id/reject", handlers.RejectConversion)
+		protected.POST("/allowance/conversions/:id/cancel", handlers.CancelConversion)
+		protected.GET("/allowance/payouts", handlers.ListPayouts)
+		protected.POST("/allowance/payouts", handlers.CreatePayout(platformDB))
+		protected.GET("/allowance/schedules", handlers.ListAllowanceSchedules(platformDB))
+		protected.POST("/allowance/schedules", handlers.CreateAllowanceSchedule(platformDB))
+		protected.PUT("/allowance/schedules/:id", handlers.UpdateAllowanceSchedule(platformDB))
+		protected.DELETE("/allowance/schedules/:id", handlers.DeleteAllowanceSchedule)
+		protected.GET("/allowance/deposits", handlers.ListAllowanceDeposits)
 
 		// Chores endpoints
 		protected.GET("/chores", handlers.ListChores)
 		protected.POST("/chores", handlers.CreateChore)
+		protected.GET("/chores/export", handlers.ExportChores)
 		protected.GET("/chores/:id", handlers.GetChore)
 		protected.PUT("/chores/:id", handlers.UpdateChore)
 		protected.DELETE("/chores/:id", handlers.DeleteChore)
+		protected.GET("/chores/:id/checklist", handlers.GetChoreChecklist)
+		protected.PUT("/chores/:id/checklist", handlers.UpdateChoreChecklist)
+		protected.GET("/chores/:id/prerequisites", handlers.GetChorePrerequisites)
+		protected.PUT("/chores/:id/prerequisites", handlers.UpdateChorePrerequisites)
+
+		// Chore pack endpoints
+		protected.GET("/chore-packs", handlers.ListChorePacks)
+		protected.GET("/chore-packs/:id", handlers.GetChorePack)
+		protected.POST("/chore-packs/import", handlers.ImportChorePack)
+		protected.POST("/chore-packs/:id/install", handlers.InstallChorePack)
 
 		// Assignments endpoints (read)
 		protected.GET("/assignments", handlers.ListAssignments)
 		protected.GET("/assignments/my-assignments", handlers.GetMyAssignments)
-		protected.GET("/assignments/:id", handlers.GetAssignment)
+		protected.GET("/assignments/:id", handlers.GetAssignment(fileStore))
+		protected.GET("/assignments/:id/history", handlers.GetAssignmentHistory)
+		protected.GET("/assignments/:id/submissions", handlers.GetAssignmentSubmissions)
+		protected.GET("/assignments/:id/photos", handlers.ListAssignmentPhotos(fileStore))
+		protected.POST("/assignments/:id/photos", handlers.UploadAssignmentPhoto(fileStore))
+		protected.GET("/assignments/:id/checklist", handlers.GetAssignmentChecklist(fileStore))
+		protected.POST("/assignments/:id/checklist/:item_id/check", handlers.CheckChecklistItem(fileStore))
+		protected.POST("/assignments/:id/checklist/:item_id/uncheck", handlers.UncheckChecklistItem(fileStore))
 
 		// Assignments endpoints (write)
-		protected.POST("/assignments", handlers.CreateAssignment)
+		protected.POST("/assignments", handlers.CreateAssignment(platformDB))
 		protected.POST("/assignments/:id/claim", handlers.ClaimAssignment)
-		protected.POST("/assignments/:id/complete", handlers.CompleteAssignment)
+		protected.POST("/assignments/:id/complete", handlers.CompleteAssignment(fileStore, platformDB))
 		protected.POST("/assignments/:id/verify", handlers.VerifyAssignment)
+		protected.POST("/assignments/:id/start", handlers.StartAssignment)
+		protected.POST("/assignments/:id/pause", handlers.PauseAssignment)
+		protected.POST("/assignments/:id/resume", handlers.ResumeAssignment)
+		protected.POST("/assignments/:id/stop", handlers.StopAssignment)
+		protected.POST("/assignments/:id/skip", handlers.SkipAssignment)
+		protected.POST("/assignments/:id/cancel", handlers.CancelAssignment)
+		protected.POST("/assignments/:id/trade", handlers.OfferAssignmentTrade)
+
+		// Bulk assignment endpoints
+		protected.POST("/assignments/bulk/create", handlers.BulkCreateAssignments(platformDB))
+		protected.POST("/assignments/bulk/reassign", handlers.BulkReassignAssignments(platformDB))
+		protected.POST("/assignments/bulk/cancel", handlers.BulkCancelAssignments)
+		protected.POST("/assignments/bulk/verify", handlers.BulkVerifyAssignments)
+
+		// Trade endpoints
+		protected.GET("/trades", handlers.ListTrades)
+		protected.GET("/trades/:id", handlers.GetTrade)
+		protected.POST("/trades/:id/accept", handlers.AcceptTrade)
+		protected.POST("/trades/:id/approve", handlers.ApproveTrade)
+		protected.POST("/trades/:id/reject", handlers.RejectTrade)
+		protected.POST("/trades/:id/cancel", handlers.CancelTrade)
+
+		// Chore rotation endpoints
+		protected.POST("/rotation/run", handlers.RunRotation(platformDB))
+		protected.GET("/rotation/decisions", handlers.GetRotationDecisions)
 
 		// Rewards endpoints
 		protected.GET("/rewards", handlers.ListRewards)
@@ -183,20 +279,39 @@ func main() {
 		// Leaderboard endpoints
 		protected.GET("/leaderboard/weekly", handlers.GetWeeklyLeaderboard)
 		protected.GET("/leaderboard/alltime", handlers.GetAllTimeLeaderboard)
+		protected.GET("/leaderboard/history", handlers.GetLeaderboardHistory)
+		protected.GET("/leaderboard/history/:week_start", handlers.GetWeeklyStandings)
 
 		// Family Schedule endpoints
 		protected.GET("/schedule", handlers.GetFamilySchedule)
+		protected.POST("/schedule/reconcile", handlers.ReconcileSchedule(platformDB))
 
 		// Settings endpoints
 		protected.GET("/settings", handlers.GetSettings)
 		protected.GET("/settings/:key", handlers.GetSetting)
 		protected.PUT("/settings/:key", handlers.UpdateSetting)
 
+		// Notification endpoints
+		protected.GET("/notifications", handlers.ListNotifications)
+		protected.POST("/notifications/read-all", handlers.MarkAllNotificationsRead)
+		protected.POST("/notifications/:id/read", handlers.MarkNotificationRead)
+
+		// Background job endpoints
+		protected.GET("/jobs/runs", handlers.ListJobRuns)
+		protected.POST("/jobs/:name/run", handlers.RunJob(jobRunner))
+
 		// Reports endpoints
 		protected.GET("/reports/weekly-summary", handlers.GetWeeklySummary)
 		protected.GET("/reports/child-performance/:child_id", handlers.GetChildPerformance)
 		protected.GET("/reports/category-breakdown", handlers.GetCategoryBreakdown)
 		protected.GET("/reports/performance-trends", handlers.GetPerformanceTrends)
+		protected.GET("/reports/quality-trends", handlers.GetQualityTrends)
+		protected.GET("/reports/time-tracking", handlers.GetTimeTracking)
+	}
+
+	// Signed file downloads for local storage (signature is the access check)
+	if local, ok := fileStore.(*storage.Local); ok {
+		r.GET("/api/files/*key", handlers.ServeFile(local))
 	}
 
 	// Demo-only endpoints (for testing without auth)
@@ -239,6 +354,7 @@ func main() {
 	<-quit
 
 	log.Println("🛑 Server shutting down...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
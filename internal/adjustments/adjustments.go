@@ -0,0 +1,241 @@
+// Package adjustments lets parents correct a user's points by hand. Each
+// adjustment has a reason code and is posted to the ledger as an adjustment
+// transaction; large ones wait for a second parent to approve them.
+package adjustments
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/JunoAX/housepoints-go/internal/audit"
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/ledger"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// Audit actions
+const (
+	ActionCreated  = "adjustment_created"
+	ActionApproved = "adjustment_approved"
+	ActionRejected = "adjustment_rejected"
+)
+
+// Adjustment statuses
+const (
+	StatusPendingApproval = "pending_approval"
+	StatusPosted          = "posted"
+	StatusRejected        = "rejected"
+)
+
+// ReasonOther is the reason code that must come with a note
+const ReasonOther = "other"
+
+// Threshold returns the size above which an adjustment needs a second
+// parent's approval, or 0 if none do
+func Threshold(ctx context.Context, q database.Querier) int {
+	return database.SettingInt(ctx, q, "adjustment_approval_threshold", 100)
+}
+
+// NeedsApproval reports whether an adjustment of points exceeds threshold
+func NeedsApproval(points, threshold int) bool {
+	if threshold <= 0 {
+		return false
+	}
+	if points < 0 {
+		points = -points
+	}
+	return points > threshold
+}
+
+// CoParents returns the active parents other than parentID
+func CoParents(ctx context.Context, q database.Querier, parentID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.Query(ctx,
+		"SELECT id FROM users WHERE is_parent = true AND is_active = true AND id <> $1",
+		parentID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query parents: %w", err)
+	}
+	defer rows.Close()
+
+	parents := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		parents = append(parents, id)
+	}
+	return parents, rows.Err()
+}
+
+const selectAdjustments = `
+	SELECT
+		pa.id, pa.user_id, u.display_name, pa.points, pa.reason_code, pa.note, pa.status,
+		pa.requested_by, ru.display_name, pa.decided_by, du.display_name,
+		pa.decided_at, pa.decision_notes, pa.transaction_id, pa.approval_bypassed,
+		pa.created_at, pa.updated_at
+	FROM point_adjustments pa
+	JOIN users u ON pa.user_id = u.id
+	LEFT JOIN users ru ON pa.requested_by = ru.id
+	LEFT JOIN users du ON pa.decided_by = du.id
+`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAdjustment(row scanner) (models.PointAdjustment, error) {
+	var a models.PointAdjustment
+	err := row.Scan(
+		&a.ID, &a.UserID, &a.UserName, &a.Points, &a.ReasonCode, &a.Note, &a.Status,
+		&a.RequestedBy, &a.RequestedByName, &a.DecidedBy, &a.DecidedByName,
+		&a.DecidedAt, &a.DecisionNotes, &a.TransactionID, &a.ApprovalBypassed,
+		&a.CreatedAt, &a.UpdatedAt,
+	)
+	return a, err
+}
+
+// Load returns an adjustment. A missing adjustment returns the driver's no
+// rows error.
+func Load(ctx context.Context, q database.Querier, id uuid.UUID) (models.PointAdjustment, error) {
+	return scanAdjustment(q.QueryRow(ctx, selectAdjustments+" WHERE pa.id = $1", id))
+}
+
+// List returns adjustments, newest first, optionally only those in status or
+// for userID
+func List(ctx context.Context, q database.Querier, status string, userID *uuid.UUID) ([]models.PointAdjustment, error) {
+	rows, err := q.Query(ctx, selectAdjustments+`
+		WHERE ($1 = '' OR pa.status = $1)
+			AND ($2::uuid IS NULL OR pa.user_id = $2)
+		ORDER BY pa.created_at DESC
+		LIMIT 200
+	`, status, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	list := []models.PointAdjustment{}
+	for rows.Next() {
+		a, err := scanAdjustment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse adjustment: %w", err)
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// Create records a new adjustment, pending until it is posted. bypassed marks
+// one that needs approval but will be posted at once for lack of a co-parent.
+func Create(ctx context.Context, q database.Querier, userID uuid.UUID, req models.PointAdjustmentRequest, requestedBy uuid.UUID, bypassed bool) (uuid.UUID, error) {
+	id := uuid.New()
+	_, err := q.Exec(ctx, `
+		INSERT INTO point_adjustments (
+			id, user_id, points, reason_code, note, status, requested_by,
+			approval_bypassed, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+	`, id, userID, req.Points, req.ReasonCode, req.Note, StatusPendingApproval, requestedBy, bypassed)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create adjustment: %w", err)
+	}
+	return id, nil
+}
+
+// Post writes the adjustment to the ledger and marks it posted. approvedBy is
+// the second parent who signed it off, if it needed one. Negative adjustments
+// may take a balance below zero: a parent is correcting it.
+func Post(ctx context.Context, q database.Querier, a models.PointAdjustment, approvedBy *uuid.UUID) (uuid.UUID, error) {
+	extra := map[string]interface{}{
+		"adjustment_id": a.ID,
+		"reason_code":   a.ReasonCode,
+		"requested_by":  a.RequestedBy,
+	}
+	if a.Note != nil {
+		extra["note"] = *a.Note
+	}
+	if approvedBy != nil {
+		extra["approved_by"] = *approvedBy
+	}
+	if a.ApprovalBypassed {
+		extra["approval_bypassed"] = true
+	}
+
+	description := fmt.Sprintf("Adjustment (%s)", a.ReasonCode)
+	if a.Note != nil && *a.Note != "" {
+		description = fmt.Sprintf("Adjustment (%s): %s", a.ReasonCode, *a.Note)
+	}
+
+	transactionID, err := ledger.Post(ctx, q, ledger.Entry{
+		UserID:        a.UserID,
+		Points:        a.Points,
+		Type:          ledger.TypeAdjustment,
+		Description:   description,
+		RelatedUserID: a.RequestedBy,
+		ExtraData:     extra,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	_, err = q.Exec(ctx, `
+		UPDATE point_adjustments
+		SET status = $1,
+			transaction_id = $2,
+			updated_at = NOW()
+		WHERE id = $3
+	`, StatusPosted, transactionID, a.ID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to update adjustment: %w", err)
+	}
+	return transactionID, nil
+}
+
+// Audit writes an audit_log row for an action on the adjustment by changedBy.
+// The row shows the adjustment's status before and after.
+func Audit(ctx context.Context, q database.Querier, a models.PointAdjustment, action, fromStatus, toStatus string, changedBy uuid.UUID, reason *string, ip string) error {
+	newValue := map[string]interface{}{
+		"user_id":           a.UserID,
+		"points":            a.Points,
+		"reason_code":       a.ReasonCode,
+		"status":            toStatus,
+		"approval_bypassed": a.ApprovalBypassed,
+	}
+	if a.Note != nil {
+		newValue["note"] = *a.Note
+	}
+	var oldValue map[string]interface{}
+	if fromStatus != "" {
+		oldValue = map[string]interface{}{"status": fromStatus}
+	}
+
+	return audit.Record(ctx, q, audit.Entry{
+		Table:     "point_adjustments",
+		RecordID:  a.ID,
+		Action:    action,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+		ChangedBy: changedBy,
+		Reason:    reason,
+		IPAddress: ip,
+	})
+}
+
+// Decide records a parent's decision and moves the adjustment to status
+func Decide(ctx context.Context, q database.Querier, id uuid.UUID, status string, parentID uuid.UUID, notes *string) error {
+	_, err := q.Exec(ctx, `
+		UPDATE point_adjustments
+		SET status = $1,
+			decided_by = $2,
+			decided_at = NOW(),
+			decision_notes = $3,
+			updated_at = NOW()
+		WHERE id = $4
+	`, status, parentID, notes, id)
+	if err != nil {
+		return fmt.Errorf("failed to update adjustment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,432 @@
+// Package allowance is the family bank: children convert points into money
+// at the family exchange rate and parents record what they pay out. Money has
+// its own ledger, money_transactions, in minor units of the family currency;
+// a child's balance is always the sum of their entries in that currency.
+package allowance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/ledger"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// Money transaction types
+const (
+	TypeConversion = "conversion"
+	TypePayout     = "payout"
+	TypeAllowance  = "allowance"
+)
+
+// Conversion statuses
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusRejected  = "rejected"
+	StatusCancelled = "cancelled"
+)
+
+// ErrInsufficientFunds is returned for a payout larger than the child's
+// balance
+var ErrInsufficientFunds = errors.New("payout exceeds the money balance")
+
+// Entry is one money transaction to record
+type Entry struct {
+	UserID       uuid.UUID
+	AmountMinor  int64 // Positive credited, negative paid out
+	Currency     string
+	Type         string
+	Description  string
+	ConversionID *uuid.UUID
+	PayoutID     *uuid.UUID
+	CreatedBy    *uuid.UUID
+}
+
+// Record writes a money transaction
+func Record(ctx context.Context, q database.Querier, e Entry) (uuid.UUID, error) {
+	id := uuid.New()
+	_, err := q.Exec(ctx, `
+		INSERT INTO money_transactions (
+			id, user_id, amount_minor, currency, transaction_type, description,
+			conversion_id, payout_id, created_by, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+	`, id, e.UserID, e.AmountMinor, e.Currency, e.Type, e.Description, e.ConversionID, e.PayoutID, e.CreatedBy)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to record %s: %w", e.Type, err)
+	}
+	return id, nil
+}
+
+// Balance returns a child's money balance in currency, in minor units
+func Balance(ctx context.Context, q database.Querier, userID uuid.UUID, currency string) (int64, error) {
+	var balance int64
+	err := q.QueryRow(ctx,
+		"SELECT COALESCE(SUM(amount_minor), 0) FROM money_transactions WHERE user_id = $1 AND currency = $2",
+		userID, currency,
+	).Scan(&balance)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum money balance: %w", err)
+	}
+	return balance, nil
+}
+
+// Balances returns the money balances of every active child, or only userID,
+// one per currency they hold. A child with no money has a zero balance in
+// currency, the family's.
+func Balances(ctx context.Context, q database.Querier, currency string, userID *uuid.UUID) ([]models.MoneyBalance, error) {
+	rows, err := q.Query(ctx, `
+		SELECT u.id, u.display_name, COALESCE(mt.currency, $2), COALESCE(SUM(mt.amount_minor), 0)
+		FROM users u
+		LEFT JOIN money_transactions mt ON mt.user_id = u.id
+		WHERE u.is_parent = false AND u.is_active = true
+			AND ($1::uuid IS NULL OR u.id = $1)
+		GROUP BY u.id, mt.currency
+		ORDER BY u.display_name, mt.currency <> $2, mt.currency
+	`, userID, currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := []models.MoneyBalance{}
+	for rows.Next() {
+		var b models.MoneyBalance
+		if err := rows.Scan(&b.UserID, &b.DisplayName, &b.Currency, &b.BalanceMinor); err != nil {
+			return nil, fmt.Errorf("failed to parse balance: %w", err)
+		}
+		b.Balance = Format(b.BalanceMinor, b.Currency)
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
+
+const selectConversions = `
+	SELECT
+		pc.id, pc.user_id, u.display_name, pc.points, pc.amount_minor, pc.currency,
+		pc.rate_points, pc.rate_minor_units, pc.note, pc.status,
+		pc.decided_by, pc.decided_at, pc.decision_notes,
+		pc.point_transaction_id, pc.money_transaction_id, pc.created_at, pc.updated_at
+	FROM allowance_conversions pc
+	JOIN users u ON pc.user_id = u.id
+`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanConversion(row scanner) (models.PointConversion, error) {
+	var pc models.PointConversion
+	err := row.Scan(
+		&pc.ID, &pc.UserID, &pc.UserName, &pc.Points, &pc.AmountMinor, &pc.Currency,
+		&pc.RatePoints, &pc.RateMinorUnits, &pc.Note, &pc.Status,
+		&pc.DecidedBy, &pc.DecidedAt, &pc.DecisionNotes,
+		&pc.PointTransactionID, &pc.MoneyTransactionID, &pc.CreatedAt, &pc.UpdatedAt,
+	)
+	pc.Amount = Format(pc.AmountMinor, pc.Currency)
+	return pc, err
+}
+
+// LoadConversion returns a conversion. A missing conversion returns the
+// driver's no rows error.
+func LoadConversion(ctx context.Context, q database.Querier, id uuid.UUID) (models.PointConversion, error) {
+	return scanConversion(q.QueryRow(ctx, selectConversions+" WHERE pc.id = $1", id))
+}
+
+// ListConversions returns conversions, newest first, optionally only those in
+// status or for userID
+func ListConversions(ctx context.Context, q database.Querier, status string, userID *uuid.UUID) ([]models.PointConversion, error) {
+	rows, err := q.Query(ctx, selectConversions+`
+		WHERE ($1 = '' OR pc.status = $1)
+			AND ($2::uuid IS NULL OR pc.user_id = $2)
+		ORDER BY pc.created_at DESC
+		LIMIT 200
+	`, status, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversions: %w", err)
+	}
+	defer rows.Close()
+
+	list := []models.PointConversion{}
+	for rows.Next() {
+		pc, err := scanConversion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse conversion: %w", err)
+		}
+		list = append(list, pc)
+	}
+	return list, rows.Err()
+}
+
+// HeldPoints returns the points a child's pending conversions hold. They stay
+// in available_points until the conversion completes, so new requests and
+// other spending must fit in what is left.
+func HeldPoints(ctx context.Context, q database.Querier, userID uuid.UUID) (int, error) {
+	var held int
+	err := q.QueryRow(ctx,
+		"SELECT COALESCE(SUM(points), 0) FROM allowance_conversions WHERE user_id = $1 AND status = $2",
+		userID, StatusPending,
+	).Scan(&held)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum held points: %w", err)
+	}
+	return held, nil
+}
+
+// CreateConversion records a pending conversion of points priced at the
+// family's current exchange rate
+func CreateConversion(ctx context.Context, q database.Querier, userID uuid.UUID, req models.ConversionRequest, settings *models.FamilySettings) (uuid.UUID, error) {
+	id := uuid.New()
+	_, err := q.Exec(ctx, `
+		INSERT INTO allowance_conversions (
+			id, user_id, points, amount_minor, currency, rate_points, rate_minor_units,
+			note, status, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+	`, id, userID, req.Points, settings.PointsToMinor(req.Points), settings.Currency,
+		settings.ExchangePoints, settings.ExchangeMinorUnits, req.Note, StatusPending)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create conversion: %w", err)
+	}
+	return id, nil
+}
+
+// Convert completes a conversion: the points leave the child's balance as a
+// conversion point transaction and the money is credited. It fails with
+// ledger.ErrInsufficientPoints if the child no longer has the points.
+func Convert(ctx context.Context, q database.Querier, pc models.PointConversion, actorID *uuid.UUID) error {
+	pointTxID, err := ledger.Post(ctx, q, ledger.Entry{
+		UserID:      pc.UserID,
+		Points:      -pc.Points,
+		Type:        ledger.TypeConversion,
+		Description: fmt.Sprintf("Converted to %s", Format(pc.AmountMinor, pc.Currency)),
+		ExtraData: map[string]interface{}{
+			"conversion_id":    pc.ID,
+			"amount_minor":     pc.AmountMinor,
+			"currency":         pc.Currency,
+			"rate_points":      pc.RatePoints,
+			"rate_minor_units": pc.RateMinorUnits,
+		},
+		NoOverdraft: true,
+		Completes:   &pc.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	moneyTxID, err := Record(ctx, q, Entry{
+		UserID:       pc.UserID,
+		AmountMinor:  pc.AmountMinor,
+		Currency:     pc.Currency,
+		Type:         TypeConversion,
+		Description:  fmt.Sprintf("Converted %d points", pc.Points),
+		ConversionID: &pc.ID,
+		CreatedBy:    actorID,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, `
+		UPDATE allowance_conversions
+		SET status = $1,
+			point_transaction_id = $2,
+			money_transaction_id = $3,
+			updated_at = NOW()
+		WHERE id = $4
+	`, StatusCompleted, pointTxID, moneyTxID, pc.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update conversion: %w", err)
+	}
+	return nil
+}
+
+// DecideConversion records who decided a conversion and moves it to status
+func DecideConversion(ctx context.Context, q database.Querier, id uuid.UUID, status string, actorID uuid.UUID, notes *string) error {
+	_, err := q.Exec(ctx, `
+		UPDATE allowance_conversions
+		SET status = $1,
+			decided_by = $2,
+			decided_at = NOW(),
+			decision_notes = $3,
+			updated_at = NOW()
+		WHERE id = $4
+	`, status, actorID, notes, id)
+	if err != nil {
+		return fmt.Errorf("failed to update conversion: %w", err)
+	}
+	return nil
+}
+
+// Payout records money handed to a child and debits their balance, which it
+// cannot exceed
+func Payout(ctx context.Context, q database.Querier, req models.PayoutRequest, currency string, paidBy uuid.UUID) (models.AllowancePayout, error) {
+	payout := models.AllowancePayout{
+		ID:          uuid.New(),
+		UserID:      req.UserID,
+		AmountMinor: req.AmountMinor,
+		Amount:      Format(req.AmountMinor, currency),
+		Currency:    currency,
+		Method:      req.Method,
+		Note:        req.Note,
+		PaidBy:      &paidBy,
+	}
+
+	// Serialize payouts per child so two cannot both spend the same balance
+	if _, err := q.Exec(ctx, "SELECT 1 FROM users WHERE id = $1 FOR UPDATE", req.UserID); err != nil {
+		return payout, fmt.Errorf("failed to lock user: %w", err)
+	}
+	balance, err := Balance(ctx, q, req.UserID, currency)
+	if err != nil {
+		return payout, err
+	}
+	if req.AmountMinor > balance {
+		return payout, ErrInsufficientFunds
+	}
+
+	err = q.QueryRow(ctx, `
+		INSERT INTO allowance_payouts (id, user_id, amount_minor, currency, method, note, paid_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at
+	`, payout.ID, req.UserID, req.AmountMinor, currency, req.Method, req.Note, paidBy).Scan(&payout.CreatedAt)
+	if err != nil {
+		return payout, fmt.Errorf("failed to create payout: %w", err)
+	}
+
+	moneyTxID, err := Record(ctx, q, Entry{
+		UserID:      req.UserID,
+		AmountMinor: -req.AmountMinor,
+		Currency:    currency,
+		Type:        TypePayout,
+		Description: fmt.Sprintf("Paid out (%s)", methodLabel(req.Method)),
+		PayoutID:    &payout.ID,
+		CreatedBy:   &paidBy,
+	})
+	if err != nil {
+		return payout, err
+	}
+	payout.MoneyTransactionID = &moneyTxID
+
+	_, err = q.Exec(ctx,
+		"UPDATE allowance_payouts SET money_transaction_id = $1 WHERE id = $2",
+		moneyTxID, payout.ID,
+	)
+	if err != nil {
+		return payout, fmt.Errorf("failed to update payout: %w", err)
+	}
+	return payout, nil
+}
+
+// ListPayouts returns payouts, newest first, optionally only for userID
+func ListPayouts(ctx context.Context, q database.Querier, userID *uuid.UUID) ([]models.AllowancePayout, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, user_id, amount_minor, currency, method, note, paid_by, money_transaction_id, created_at
+		FROM allowance_payouts
+		WHERE $1::uuid IS NULL OR user_id = $1
+		ORDER BY created_at DESC
+		LIMIT 200
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payouts: %w", err)
+	}
+	defer rows.Close()
+
+	list := []models.AllowancePayout{}
+	for rows.Next() {
+		var p models.AllowancePayout
+		err := rows.Scan(
+			&p.ID, &p.UserID, &p.AmountMinor, &p.Currency, &p.Method, &p.Note,
+			&p.PaidBy, &p.MoneyTransactionID, &p.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse payout: %w", err)
+		}
+		p.Amount = Format(p.AmountMinor, p.Currency)
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// methodLabel names a payout method for descriptions
+func methodLabel(method string) string {
+	switch method {
+	case "gift_card":
+		return "gift card"
+	default:
+		return method
+	}
+}
+
+// Statement lists a child's money transactions in currency from from up to,
+// but not including, to with the balance after each
+func Statement(ctx context.Context, q database.Querier, userID uuid.UUID, currency string, from, to time.Time) (models.MoneyStatement, error) {
+	statement := models.MoneyStatement{
+		UserID:   userID,
+		Currency: currency,
+		From:     from.Format("2006-01-02"),
+		To:       to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Lines:    []models.StatementLine{},
+	}
+
+	err := q.QueryRow(ctx, `
+		SELECT u.display_name, COALESCE((
+			SELECT SUM(amount_minor) FROM money_transactions
+			WHERE user_id = u.id AND currency = $3 AND created_at < $2
+		), 0)
+		FROM users u
+		WHERE u.id = $1
+	`, userID, from, currency).Scan(&statement.DisplayName, &statement.OpeningMinor)
+	if err != nil {
+		return statement, err
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT
+			id, user_id, amount_minor, currency, transaction_type, description,
+			conversion_id, payout_id, created_by, created_at
+		FROM money_transactions
+		WHERE user_id = $1 AND currency = $4 AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at, id
+	`, userID, from, to, currency)
+	if err != nil {
+		return statement, fmt.Errorf("failed to query money transactions: %w", err)
+	}
+	defer rows.Close()
+
+	balance := statement.OpeningMinor
+	for rows.Next() {
+		var line models.StatementLine
+		mt := &line.MoneyTransaction
+		err := rows.Scan(
+			&mt.ID, &mt.UserID, &mt.AmountMinor, &mt.Currency, &mt.TransactionType, &mt.Description,
+			&mt.ConversionID, &mt.PayoutID, &mt.CreatedBy, &mt.CreatedAt,
+		)
+		if err != nil {
+			return statement, fmt.Errorf("failed to parse money transaction: %w", err)
+		}
+		mt.Amount = Format(mt.AmountMinor, currency)
+
+		balance += mt.AmountMinor
+		line.BalanceMinor = balance
+		line.Balance = Format(balance, currency)
+		if mt.AmountMinor > 0 {
+			statement.CreditsMinor += mt.AmountMinor
+		} else {
+			statement.DebitsMinor -= mt.AmountMinor
+		}
+		statement.Lines = append(statement.Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return statement, fmt.Errorf("failed to query money transactions: %w", err)
+	}
+
+	statement.ClosingMinor = balance
+	statement.Opening = Format(statement.OpeningMinor, currency)
+	statement.Credits = Format(statement.CreditsMinor, currency)
+	statement.Debits = Format(statement.DebitsMinor, currency)
+	statement.Closing = Format(statement.ClosingMinor, currency)
+	return statement, nil
+}
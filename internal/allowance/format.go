@@ -0,0 +1,71 @@
+package allowance
+
+import (
+	"fmt"
+	"strings"
+)
+
+// currencyFormat is how amounts in a currency are written
+type currencyFormat struct {
+	symbol   string
+	decimals int  // Minor unit digits
+	suffix   bool // Symbol after the amount
+}
+
+var currencies = map[string]currencyFormat{
+	"USD": {symbol: "$", decimals: 2},
+	"CAD": {symbol: "CA$", decimals: 2},
+	"AUD": {symbol: "A$", decimals: 2},
+	"NZD": {symbol: "NZ$", decimals: 2},
+	"EUR": {symbol: "€", decimals: 2},
+	"GBP": {symbol: "£", decimals: 2},
+	"CHF": {symbol: "CHF ", decimals: 2},
+	"INR": {symbol: "₹", decimals: 2},
+	"JPY": {symbol: "¥", decimals: 0},
+	"KRW": {symbol: "₩", decimals: 0},
+	"SEK": {symbol: " kr", decimals: 2, suffix: true},
+	"NOK": {symbol: " kr", decimals: 2, suffix: true},
+	"DKK": {symbol: " kr", decimals: 2, suffix: true},
+}
+
+// Format writes an amount in minor units for display, e.g. 1234 USD as
+// "$12.34". Currencies without a known symbol use the code, as "12.34 XYZ".
+func Format(minor int64, currency string) string {
+	f, ok := currencies[strings.ToUpper(currency)]
+	if !ok {
+		f = currencyFormat{symbol: " " + currency, decimals: 2, suffix: true}
+	}
+
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	scale := int64(1)
+	for i := 0; i < f.decimals; i++ {
+		scale *= 10
+	}
+	amount := groupThousands(minor / scale)
+	if f.decimals > 0 {
+		amount += fmt.Sprintf(".%0*d", f.decimals, minor%scale)
+	}
+
+	if f.suffix {
+		return sign + amount + f.symbol
+	}
+	return sign + f.symbol + amount
+}
+
+// groupThousands writes n with commas between groups of three digits
+func groupThousands(n int64) string {
+	digits := fmt.Sprintf("%d", n)
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
@@ -0,0 +1,254 @@
+package allowance
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/ledger"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// Schedule kinds
+const (
+	KindPoints = "points"
+	KindMoney  = "money"
+)
+
+// Deposit statuses
+const (
+	DepositPaid    = "paid"
+	DepositSkipped = "skipped"
+)
+
+const selectSchedules = `
+	SELECT
+		s.id, s.user_id, u.display_name, s.kind, s.amount, s.weekday,
+		s.min_completion_rate::float8, s.description, s.active, s.created_by,
+		s.created_at, s.updated_at
+	FROM allowance_schedules s
+	JOIN users u ON s.user_id = u.id
+`
+
+func scanSchedule(row scanner, currency string) (models.AllowanceSchedule, error) {
+	var s models.AllowanceSchedule
+	err := row.Scan(
+		&s.ID, &s.UserID, &s.UserName, &s.Kind, &s.Amount, &s.Weekday,
+		&s.MinCompletionRate, &s.Description, &s.Active, &s.CreatedBy,
+		&s.CreatedAt, &s.UpdatedAt,
+	)
+	s.AmountDisplay = FormatAmount(s.Kind, s.Amount, currency)
+	return s, err
+}
+
+// FormatAmount writes a schedule amount for display: points as "50 points",
+// money in currency
+func FormatAmount(kind string, amount int64, currency string) string {
+	if kind == KindMoney {
+		return Format(amount, currency)
+	}
+	return fmt.Sprintf("%d points", amount)
+}
+
+// LoadSchedule returns a schedule. A missing schedule returns the driver's no
+// rows error.
+func LoadSchedule(ctx context.Context, q database.Querier, id uuid.UUID, currency string) (models.AllowanceSchedule, error) {
+	return scanSchedule(q.QueryRow(ctx, selectSchedules+" WHERE s.id = $1", id), currency)
+}
+
+// ListSchedules returns schedules by child and weekday, optionally only for
+// userID
+func ListSchedules(ctx context.Context, q database.Querier, userID *uuid.UUID, currency string) ([]models.AllowanceSchedule, error) {
+	rows, err := q.Query(ctx, selectSchedules+`
+		WHERE $1::uuid IS NULL OR s.user_id = $1
+		ORDER BY u.display_name, s.weekday, s.created_at
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	list := []models.AllowanceSchedule{}
+	for rows.Next() {
+		s, err := scanSchedule(rows, currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schedule: %w", err)
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// DueSchedules returns the active schedules that pay on day and have not yet
+// been recorded for it. A schedule only pays on days that start after it was
+// created, so a backfill never pays for weeks before it existed.
+func DueSchedules(ctx context.Context, q database.Querier, day time.Time, currency string) ([]models.AllowanceSchedule, error) {
+	rows, err := q.Query(ctx, selectSchedules+`
+		WHERE s.active = true
+			AND u.is_active = true
+			AND s.weekday = $1
+			AND s.created_at < $2
+			AND NOT EXISTS (
+				SELECT 1 FROM allowance_deposits d
+				WHERE d.schedule_id = s.id AND d.deposit_date = $3
+			)
+		ORDER BY s.created_at
+	`, int(day.Weekday()), day, day.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	list := []models.AllowanceSchedule{}
+	for rows.Next() {
+		s, err := scanSchedule(rows, currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schedule: %w", err)
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// CreateSchedule records a new active schedule
+func CreateSchedule(ctx context.Context, q database.Querier, req models.AllowanceScheduleRequest, createdBy uuid.UUID) (uuid.UUID, error) {
+	id := uuid.New()
+	_, err := q.Exec(ctx, `
+		INSERT INTO allowance_schedules (
+			id, user_id, kind, amount, weekday, min_completion_rate, description,
+			active, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, true, $8, NOW(), NOW())
+	`, id, req.UserID, req.Kind, req.Amount, req.Weekday, req.MinCompletionRate, req.Description, createdBy)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create schedule: %w", err)
+	}
+	return id, nil
+}
+
+// UpdateSchedule changes the fields given in req
+func UpdateSchedule(ctx context.Context, q database.Querier, id uuid.UUID, req models.AllowanceScheduleUpdateRequest) error {
+	_, err := q.Exec(ctx, `
+		UPDATE allowance_schedules
+		SET amount = COALESCE($1, amount),
+			weekday = COALESCE($2, weekday),
+			min_completion_rate = CASE WHEN $3 THEN NULL ELSE COALESCE($4, min_completion_rate) END,
+			description = COALESCE($5, description),
+			active = COALESCE($6, active),
+			updated_at = NOW()
+		WHERE id = $7
+	`, req.Amount, req.Weekday, req.ClearMinRate, req.MinCompletionRate, req.Description, req.Active, id)
+	if err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+	return nil
+}
+
+// ListDeposits returns recorded deposits, newest first, optionally only for
+// userID
+func ListDeposits(ctx context.Context, q database.Querier, userID *uuid.UUID) ([]models.AllowanceDeposit, error) {
+	rows, err := q.Query(ctx, `
+		SELECT
+			id, schedule_id, user_id, deposit_date, kind, amount, status,
+			completion_rate::float8, point_transaction_id, money_transaction_id, created_at
+		FROM allowance_deposits
+		WHERE $1::uuid IS NULL OR user_id = $1
+		ORDER BY deposit_date DESC, created_at DESC
+		LIMIT 200
+	`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deposits: %w", err)
+	}
+	defer rows.Close()
+
+	list := []models.AllowanceDeposit{}
+	for rows.Next() {
+		var d models.AllowanceDeposit
+		var date time.Time
+		err := rows.Scan(
+			&d.ID, &d.ScheduleID, &d.UserID, &date, &d.Kind, &d.Amount, &d.Status,
+			&d.CompletionRate, &d.PointTransactionID, &d.MoneyTransactionID, &d.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse deposit: %w", err)
+		}
+		d.DepositDate = date.Format("2006-01-02")
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// Deposit pays a schedule for day, or records it as skipped when rate, the
+// child's completion rate for the last full week, is below the schedule's
+// minimum. A nil rate means the child had nothing to do, which never skips.
+// It reports the recorded status, or "" if the day was already recorded.
+func Deposit(ctx context.Context, q database.Querier, s models.AllowanceSchedule, day time.Time, rate *float64, currency string) (string, error) {
+	status := DepositPaid
+	if s.MinCompletionRate != nil && rate != nil && *rate < *s.MinCompletionRate {
+		status = DepositSkipped
+	}
+
+	var depositID uuid.UUID
+	err := q.QueryRow(ctx, `
+		INSERT INTO allowance_deposits (
+			id, schedule_id, user_id, deposit_date, kind, amount, status, completion_rate, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		ON CONFLICT (schedule_id, deposit_date) DO NOTHING
+		RETURNING id
+	`, uuid.New(), s.ID, s.UserID, day.Format("2006-01-02"), s.Kind, s.Amount, status, rate).Scan(&depositID)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to record deposit: %w", err)
+	}
+	if status == DepositSkipped {
+		return status, nil
+	}
+
+	description := "Weekly allowance"
+	if s.Description != nil && *s.Description != "" {
+		description = *s.Description
+	}
+
+	if s.Kind == KindMoney {
+		moneyTxID, err := Record(ctx, q, Entry{
+			UserID:      s.UserID,
+			AmountMinor: s.Amount,
+			Currency:    currency,
+			Type:        TypeAllowance,
+			Description: description,
+			CreatedBy:   s.CreatedBy,
+		})
+		if err != nil {
+			return "", err
+		}
+		_, err = q.Exec(ctx, "UPDATE allowance_deposits SET money_transaction_id = $1 WHERE id = $2", moneyTxID, depositID)
+		if err != nil {
+			return "", fmt.Errorf("failed to update deposit: %w", err)
+		}
+		return status, nil
+	}
+
+	pointTxID, err := ledger.Post(ctx, q, ledger.Entry{
+		UserID:      s.UserID,
+		Points:      int(s.Amount),
+		Type:        ledger.TypeAllowance,
+		Description: description,
+		ExtraData: map[string]interface{}{
+			"schedule_id":     s.ID,
+			"deposit_id":      depositID,
+			"deposit_date":    day.Format("2006-01-02"),
+			"completion_rate": rate,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to post allowance: %w", err)
+	}
+	_, err = q.Exec(ctx, "UPDATE allowance_deposits SET point_transaction_id = $1 WHERE id = $2", pointTxID, depositID)
+	if err != nil {
+		return "", fmt.Errorf("failed to update deposit: %w", err)
+	}
+	return status, nil
+}
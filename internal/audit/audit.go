@@ -0,0 +1,51 @@
+// Package audit records changes to sensitive records in the family audit_log
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/google/uuid"
+)
+
+// Entry is one audited change to a record
+type Entry struct {
+	Table     string
+	RecordID  uuid.UUID
+	Action    string
+	OldValue  map[string]interface{}
+	NewValue  map[string]interface{}
+	ChangedBy uuid.UUID
+	Reason    *string
+	IPAddress string
+}
+
+// Record writes an audit_log row. It runs in the caller's transaction so the
+// row only exists if the change it describes is committed.
+func Record(ctx context.Context, q database.Querier, e Entry) error {
+	var oldValue, newValue []byte
+	var err error
+	if e.OldValue != nil {
+		if oldValue, err = json.Marshal(e.OldValue); err != nil {
+			return fmt.Errorf("failed to encode audit value: %w", err)
+		}
+	}
+	if e.NewValue != nil {
+		if newValue, err = json.Marshal(e.NewValue); err != nil {
+			return fmt.Errorf("failed to encode audit value: %w", err)
+		}
+	}
+
+	_, err = q.Exec(ctx, `
+		INSERT INTO audit_log (
+			id, table_name, record_id, action, old_value, new_value, changed_by,
+			changed_at, reason, ip_address
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), $8, NULLIF($9, '')::inet)
+	`, uuid.New(), e.Table, e.RecordID, e.Action, oldValue, newValue, e.ChangedBy, e.Reason, e.IPAddress)
+	if err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
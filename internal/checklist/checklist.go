@@ -0,0 +1,105 @@
+// Package checklist copies a chore's checklist onto its assignments and
+// tracks which items have been checked off.
+package checklist
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// Snapshot copies the chore's checklist onto an assignment. It does nothing
+// if the assignment already has items, so it is safe to call again for
+// assignments created before the chore had a checklist.
+func Snapshot(ctx context.Context, q database.Querier, assignmentID uuid.UUID) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO assignment_checklist_items (
+			id, assignment_id, chore_item_id, position, title, description,
+			required, requires_photo
+		)
+		SELECT gen_random_uuid(), a.id, i.id, i.position, i.title, i.description,
+			i.required, i.requires_photo
+		FROM assignments a
+		JOIN chore_checklist_items i ON i.chore_id = a.chore_id
+		WHERE a.id = $1
+			AND NOT EXISTS (SELECT 1 FROM assignment_checklist_items x WHERE x.assignment_id = a.id)
+	`, assignmentID)
+	if err != nil {
+		return fmt.Errorf("failed to copy checklist: %w", err)
+	}
+	return nil
+}
+
+// LoadChore returns a chore's checklist in order
+func LoadChore(ctx context.Context, q database.Querier, choreID uuid.UUID) ([]models.ChoreChecklistItem, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, position, title, description, required, requires_photo
+		FROM chore_checklist_items
+		WHERE chore_id = $1
+		ORDER BY position
+	`, choreID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query checklist: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.ChoreChecklistItem{}
+	for rows.Next() {
+		var item models.ChoreChecklistItem
+		if err := rows.Scan(&item.ID, &item.Position, &item.Title, &item.Description, &item.Required, &item.RequiresPhoto); err != nil {
+			return nil, fmt.Errorf("failed to parse checklist item: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// ReplaceChore sets a chore's checklist to items, in the order given
+func ReplaceChore(ctx context.Context, q database.Querier, choreID uuid.UUID, items []models.ChecklistItemRequest) error {
+	if _, err := q.Exec(ctx, "DELETE FROM chore_checklist_items WHERE chore_id = $1", choreID); err != nil {
+		return fmt.Errorf("failed to clear checklist: %w", err)
+	}
+
+	for i, item := range items {
+		required := true
+		if item.Required != nil {
+			required = *item.Required
+		}
+		_, err := q.Exec(ctx, `
+			INSERT INTO chore_checklist_items (
+				id, chore_id, position, title, description, required, requires_photo, created_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		`, uuid.New(), choreID, i+1, item.Title, item.Description, required, item.RequiresPhoto)
+		if err != nil {
+			return fmt.Errorf("failed to add checklist item: %w", err)
+		}
+	}
+	return nil
+}
+
+// Outstanding returns the titles of required items that are not checked yet,
+// in order
+func Outstanding(ctx context.Context, q database.Querier, assignmentID uuid.UUID) ([]string, error) {
+	rows, err := q.Query(ctx, `
+		SELECT title FROM assignment_checklist_items
+		WHERE assignment_id = $1 AND required AND checked_at IS NULL
+		ORDER BY position
+	`, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query checklist: %w", err)
+	}
+	defer rows.Close()
+
+	titles := []string{}
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, err
+		}
+		titles = append(titles, title)
+	}
+	return titles, rows.Err()
+}
@@ -2,10 +2,13 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -84,6 +87,96 @@ func (db *PlatformDB) GetFamilyBySlug(ctx context.Context, slug string) (*models
 	return &family, nil
 }
 
+// ListActiveFamilies returns every active family, used by background jobs
+func (db *PlatformDB) ListActiveFamilies(ctx context.Context) ([]*models.Family, error) {
+	query := `
+		SELECT id, slug, name, db_host, db_port, db_name, plan, status, created_at, updated_at
+		FROM families
+		WHERE deleted_at IS NULL AND status = 'active'
+		ORDER BY slug
+	`
+
+	rows, err := db.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list families: %w", err)
+	}
+	defer rows.Close()
+
+	families := []*models.Family{}
+	for rows.Next() {
+		var family models.Family
+		err := rows.Scan(
+			&family.ID,
+			&family.Slug,
+			&family.Name,
+			&family.DBHost,
+			&family.DBPort,
+			&family.DBName,
+			&family.Plan,
+			&family.Status,
+			&family.CreatedAt,
+			&family.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan family: %w", err)
+		}
+		families = append(families, &family)
+	}
+
+	return families, rows.Err()
+}
+
+// GetFamilySettings retrieves family settings, falling back to defaults when
+// the family has not configured any
+func (db *PlatformDB) GetFamilySettings(ctx context.Context, familyID uuid.UUID) (*models.FamilySettings, error) {
+	query := `
+		SELECT id, family_id, timezone, currency, exchange_points, exchange_minor_units,
+			week_start_day, theme_color, custom_domain, created_at, updated_at
+		FROM family_settings
+		WHERE family_id = $1
+	`
+
+	var settings models.FamilySettings
+	err := db.pool.QueryRow(ctx, query, familyID).Scan(
+		&settings.ID,
+		&settings.FamilyID,
+		&settings.Timezone,
+		&settings.Currency,
+		&settings.ExchangePoints,
+		&settings.ExchangeMinorUnits,
+		&settings.WeekStartDay,
+		&settings.ThemeColor,
+		&settings.CustomDomain,
+		&settings.CreatedAt,
+		&settings.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.DefaultFamilySettings(familyID), nil
+		}
+		return nil, fmt.Errorf("failed to get family settings for %s: %w", familyID, err)
+	}
+
+	return &settings, nil
+}
+
+// UpdateFamilyExchangeRate sets how many points are worth how many minor
+// units of the family currency, creating the family's settings if needed
+func (db *PlatformDB) UpdateFamilyExchangeRate(ctx context.Context, familyID uuid.UUID, points, minorUnits int) error {
+	_, err := db.pool.Exec(ctx, `
+		INSERT INTO family_settings (family_id, exchange_points, exchange_minor_units)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (family_id) DO UPDATE
+		SET exchange_points = EXCLUDED.exchange_points,
+			exchange_minor_units = EXCLUDED.exchange_minor_units
+	`, familyID, points, minorUnits)
+	if err != nil {
+		return fmt.Errorf("failed to update exchange rate for %s: %w", familyID, err)
+	}
+	return nil
+}
+
 // UpdateFamilyLastActivity updates the last_activity_at timestamp
 func (db *PlatformDB) UpdateFamilyLastActivity(ctx context.Context, familyID string) error {
 	query := `UPDATE families SET last_activity_at = NOW() WHERE id = $1`
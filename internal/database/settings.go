@@ -0,0 +1,30 @@
+package database
+
+import (
+	"context"
+	"strconv"
+)
+
+// SettingInt reads an int from system_settings, returning def when unset
+func SettingInt(ctx context.Context, q Querier, key string, def int) int {
+	var value string
+	err := q.QueryRow(ctx, "SELECT setting_value FROM system_settings WHERE setting_key = $1", key).Scan(&value)
+	if err != nil {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// SettingBool reads a bool from system_settings, returning def when unset
+func SettingBool(ctx context.Context, q Querier, key string, def bool) bool {
+	var value string
+	err := q.QueryRow(ctx, "SELECT setting_value FROM system_settings WHERE setting_key = $1", key).Scan(&value)
+	if err != nil {
+		return def
+	}
+	return value == "true" || value == "1" || value == "yes"
+}
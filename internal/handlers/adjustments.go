@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/JunoAX/housepoints-go/internal/adjustments"
+	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/notify"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AdjustUserPoints adds or removes points by hand, with a reason code (parent
+// only). An adjustment larger than adjustment_approval_threshold waits for
+// another parent to approve it. With no other parent it is posted at once and
+// marked approval_bypassed in the response and the audit log.
+func AdjustUserPoints(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	parentID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can adjust points"})
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+	if userID == parentID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Parents cannot adjust their own points"})
+		return
+	}
+
+	var req models.PointAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if req.ReasonCode == adjustments.ReasonOther && (req.Note == nil || strings.TrimSpace(*req.Note) == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A note is required with reason code other"})
+		return
+	}
+
+	tx, err := db.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var active bool
+	err = tx.QueryRow(c.Request.Context(),
+		"SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND is_active = true)",
+		userID,
+	).Scan(&active)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query user", "details": err.Error()})
+		return
+	}
+	if !active {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var approvers []uuid.UUID
+	needsApproval := adjustments.NeedsApproval(req.Points, adjustments.Threshold(c.Request.Context(), tx))
+	if needsApproval {
+		approvers, err = adjustments.CoParents(c.Request.Context(), tx, parentID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query parents", "details": err.Error()})
+			return
+		}
+	}
+	bypassed := needsApproval && len(approvers) == 0
+
+	id, err := adjustments.Create(c.Request.Context(), tx, userID, req, parentID, bypassed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create adjustment", "details": err.Error()})
+		return
+	}
+	adj, err := adjustments.Load(c.Request.Context(), tx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query adjustment", "details": err.Error()})
+		return
+	}
+
+	if len(approvers) == 0 {
+		if !postAdjustment(c, tx, adj, nil) {
+			return
+		}
+		if !auditAdjustment(c, tx, adj, adjustments.ActionCreated, "", adjustments.StatusPosted, parentID, adj.Note) {
+			return
+		}
+		finishAdjustment(c, tx, id, http.StatusCreated)
+		return
+	}
+
+	if !auditAdjustment(c, tx, adj, adjustments.ActionCreated, "", adjustments.StatusPendingApproval, parentID, adj.Note) {
+		return
+	}
+
+	for _, approver := range approvers {
+		err := notify.Send(c.Request.Context(), tx, notify.Notification{
+			UserID: approver,
+			Type:   notify.TypeAdjustment,
+			Title:  fmt.Sprintf("Approve a %+d point adjustment for %s", adj.Points, adj.UserName),
+			Body:   adjustmentBody(adj.ReasonCode, adj.Note),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send notifications", "details": err.Error()})
+			return
+		}
+	}
+
+	finishAdjustment(c, tx, id, http.StatusAccepted)
+}
+
+// ListPointAdjustments returns manual adjustments, optionally only one
+// ?status= or ?user_id= (parent only)
+func ListPointAdjustments(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can view adjustments"})
+		return
+	}
+
+	var userID *uuid.UUID
+	if value := c.Query("user_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+			return
+		}
+		userID = &id
+	}
+
+	list, err := adjustments.List(c.Request.Context(), db, c.Query("status"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query adjustments", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"adjustments": list,
+		"count":       len(list),
+	})
+}
+
+// ApprovePointAdjustment lets a second parent sign off a pending adjustment,
+// which is then posted
+func ApprovePointAdjustment(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	parentID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can approve adjustments"})
+		return
+	}
+
+	var req models.PointAdjustmentDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Notes are optional
+		req = models.PointAdjustmentDecisionRequest{}
+	}
+
+	tx, adj, ok := lockAdjustment(c, db)
+	if !ok {
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if adj.Status != adjustments.StatusPendingApproval {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot approve adjustment with status: %s", adj.Status)})
+		return
+	}
+	if adj.RequestedBy != nil && *adj.RequestedBy == parentID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Another parent must approve this adjustment"})
+		return
+	}
+
+	if !postAdjustment(c, tx, adj, &parentID) {
+		return
+	}
+	err := adjustments.Decide(c.Request.Context(), tx, adj.ID, adjustments.StatusPosted, parentID, req.Notes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update adjustment", "details": err.Error()})
+		return
+	}
+	if !auditAdjustment(c, tx, adj, adjustments.ActionApproved, adj.Status, adjustments.StatusPosted, parentID, req.Notes) {
+		return
+	}
+
+	finishAdjustment(c, tx, adj.ID, http.StatusOK)
+}
+
+// RejectPointAdjustment turns down a pending adjustment (any parent, so the
+// one who asked can also withdraw it)
+func RejectPointAdjustment(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	parentID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can reject adjustments"})
+		return
+	}
+
+	var req models.PointAdjustmentDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Notes are optional
+		req = models.PointAdjustmentDecisionRequest{}
+	}
+
+	tx, adj, ok := lockAdjustment(c, db)
+	if !ok {
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if adj.Status != adjustments.StatusPendingApproval {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot reject adjustment with status: %s", adj.Status)})
+		return
+	}
+
+	err := adjustments.Decide(c.Request.Context(), tx, adj.ID, adjustments.StatusRejected, parentID, req.Notes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update adjustment", "details": err.Error()})
+		return
+	}
+	if !auditAdjustment(c, tx, adj, adjustments.ActionRejected, adj.Status, adjustments.StatusRejected, parentID, req.Notes) {
+		return
+	}
+
+	if adj.RequestedBy != nil && *adj.RequestedBy != parentID {
+		var body string
+		if req.Notes != nil {
+			body = *req.Notes
+		}
+		err := notify.Send(c.Request.Context(), tx, notify.Notification{
+			UserID: *adj.RequestedBy,
+			Type:   notify.TypeAdjustment,
+			Title:  fmt.Sprintf("Your %+d point adjustment for %s was not approved", adj.Points, adj.UserName),
+			Body:   body,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send notifications", "details": err.Error()})
+			return
+		}
+	}
+
+	finishAdjustment(c, tx, adj.ID, http.StatusOK)
+}
+
+// lockAdjustment starts a transaction and loads the adjustment in :id with
+// its row locked. On failure it has already responded and rolled back.
+func lockAdjustment(c *gin.Context, db *pgxpool.Pool) (pgx.Tx, models.PointAdjustment, bool) {
+	var adj models.PointAdjustment
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid adjustment ID format"})
+		return nil, adj, false
+	}
+
+	tx, err := db.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return nil, adj, false
+	}
+
+	_, err = tx.Exec(c.Request.Context(), "SELECT 1 FROM point_adjustments WHERE id = $1 FOR UPDATE", id)
+	if err == nil {
+		adj, err = adjustments.Load(c.Request.Context(), tx, id)
+	}
+	if err != nil {
+		tx.Rollback(c.Request.Context())
+		if err.Error() == "no rows in result set" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Adjustment not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query adjustment", "details": err.Error()})
+		}
+		return nil, adj, false
+	}
+	return tx, adj, true
+}
+
+// postAdjustment posts the adjustment to the ledger and tells the user whose
+// points changed. On failure it has already responded.
+func postAdjustment(c *gin.Context, tx pgx.Tx, adj models.PointAdjustment, approvedBy *uuid.UUID) bool {
+	if _, err := adjustments.Post(c.Request.Context(), tx, adj, approvedBy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to post adjustment", "details": err.Error()})
+		return false
+	}
+
+	err := notify.Send(c.Request.Context(), tx, notify.Notification{
+		UserID: adj.UserID,
+		Type:   notify.TypeAdjustment,
+		Title:  fmt.Sprintf("Your points were adjusted by %+d", adj.Points),
+		Body:   adjustmentBody(adj.ReasonCode, adj.Note),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send notifications", "details": err.Error()})
+		return false
+	}
+	return true
+}
+
+// auditAdjustment writes the audit_log row for an action on the adjustment.
+// On failure it has already responded.
+func auditAdjustment(c *gin.Context, tx pgx.Tx, adj models.PointAdjustment, action, fromStatus, toStatus string, parentID uuid.UUID, reason *string) bool {
+	err := adjustments.Audit(c.Request.Context(), tx, adj, action, fromStatus, toStatus, parentID, reason, c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write audit log", "details": err.Error()})
+		return false
+	}
+	return true
+}
+
+// finishAdjustment reloads the adjustment, commits and responds with it
+func finishAdjustment(c *gin.Context, tx pgx.Tx, id uuid.UUID, status int) {
+	adj, err := adjustments.Load(c.Request.Context(), tx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query adjustment", "details": err.Error()})
+		return
+	}
+
+	if err = tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(status, adj)
+}
+
+// adjustmentBody describes why points were adjusted
+func adjustmentBody(reasonCode string, note *string) string {
+	reason := strings.ReplaceAll(reasonCode, "_", " ")
+	if note != nil && *note != "" {
+		return fmt.Sprintf("Reason: %s. %s", reason, *note)
+	}
+	return fmt.Sprintf("Reason: %s", reason)
+}
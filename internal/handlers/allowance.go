@@ -0,0 +1,926 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/allowance"
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/jobs"
+	"github.com/JunoAX/housepoints-go/internal/ledger"
+	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/notify"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// GetExchangeRate returns the family's points to money exchange rate
+func GetExchangeRate(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		settings, ok := familySettings(c, platformDB)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, exchangeRate(settings))
+	}
+}
+
+// UpdateExchangeRate sets how many points are worth how many minor units of
+// the family currency (parent only). Zero points turns conversion off.
+// Conversions already requested keep the rate they were priced at.
+func UpdateExchangeRate(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		familyID, ok := middleware.GetFamilyID(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Family context not found"})
+			return
+		}
+
+		isParent, _ := middleware.GetAuthIsParent(c)
+		if !isParent {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can change the exchange rate"})
+			return
+		}
+
+		var req models.ExchangeRateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		err := platformDB.UpdateFamilyExchangeRate(c.Request.Context(), familyID, req.Points, req.MinorUnits)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update exchange rate", "details": err.Error()})
+			return
+		}
+
+		settings, ok := familySettings(c, platformDB)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, exchangeRate(settings))
+	}
+}
+
+// RequestConversion asks to turn points into money at the current exchange
+// rate (children only). Unless conversion_requires_approval is off, parents
+// are notified and the points stay in place until one approves it. Points
+// held by the child's other pending requests cannot be asked for again.
+func RequestConversion(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		userID, ok := middleware.GetAuthUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			return
+		}
+		isParent, _ := middleware.GetAuthIsParent(c)
+		if isParent {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only children can convert points"})
+			return
+		}
+
+		var req models.ConversionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		settings, ok := familySettings(c, platformDB)
+		if !ok {
+			return
+		}
+		if !settings.ConversionEnabled() {
+			c.JSON(http.StatusConflict, gin.H{"error": "Point conversion is not set up for this family"})
+			return
+		}
+		if settings.PointsToMinor(req.Points) <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%d points is worth less than the smallest amount", req.Points)})
+			return
+		}
+
+		tx, err := db.Begin(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+			return
+		}
+		defer tx.Rollback(c.Request.Context())
+
+		minPoints := database.SettingInt(c.Request.Context(), tx, "conversion_min_points", 0)
+		if req.Points < minPoints {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At least %d points must be converted", minPoints)})
+			return
+		}
+
+		var available int
+		err = tx.QueryRow(c.Request.Context(),
+			"SELECT available_points FROM users WHERE id = $1 FOR UPDATE",
+			userID,
+		).Scan(&available)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query user", "details": err.Error()})
+			return
+		}
+		held, err := allowance.HeldPoints(c.Request.Context(), tx, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query conversions", "details": err.Error()})
+			return
+		}
+		if available-held < req.Points {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":     "Insufficient points",
+				"required":  req.Points,
+				"available": available - held,
+				"held":      held,
+			})
+			return
+		}
+
+		id, err := allowance.CreateConversion(c.Request.Context(), tx, userID, req, settings)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create conversion", "details": err.Error()})
+			return
+		}
+		conv, err := allowance.LoadConversion(c.Request.Context(), tx, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query conversion", "details": err.Error()})
+			return
+		}
+
+		if !database.SettingBool(c.Request.Context(), tx, "conversion_requires_approval", true) {
+			if !convertPoints(c, tx, conv, nil) {
+				return
+			}
+			finishConversion(c, tx, id, http.StatusCreated)
+			return
+		}
+
+		err = notify.Parents(c.Request.Context(), tx, notify.Notification{
+			Type:  notify.TypeAllowance,
+			Title: fmt.Sprintf("%s wants to convert %d points to %s", conv.UserName, conv.Points, conv.Amount),
+			Body:  conversionBody(conv.Note),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send notifications", "details": err.Error()})
+			return
+		}
+
+		finishConversion(c, tx, id, http.StatusAccepted)
+	}
+}
+
+// ListConversions returns point conversions, optionally only one ?status=.
+// Parents see everyone's and can filter by ?user_id=; children see their own.
+func ListConversions(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, ok := ownOrQueriedUser(c)
+	if !ok {
+		return
+	}
+
+	list, err := allowance.ListConversions(c.Request.Context(), db, c.Query("status"), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query conversions", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"conversions": list,
+		"count":       len(list),
+	})
+}
+
+// ApproveConversion completes a pending conversion (parent only): the points
+// are spent and the money is credited to the child
+func ApproveConversion(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	parentID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can approve conversions"})
+		return
+	}
+
+	var req models.ConversionDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Notes are optional
+		req = models.ConversionDecisionRequest{}
+	}
+
+	tx, conv, ok := lockConversion(c, db)
+	if !ok {
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if conv.Status != allowance.StatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot approve conversion with status: %s", conv.Status)})
+		return
+	}
+
+	if !convertPoints(c, tx, conv, &parentID) {
+		return
+	}
+	err := allowance.DecideConversion(c.Request.Context(), tx, conv.ID, allowance.StatusCompleted, parentID, req.Notes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update conversion", "details": err.Error()})
+		return
+	}
+
+	finishConversion(c, tx, conv.ID, http.StatusOK)
+}
+
+// RejectConversion turns down a pending conversion (parent only). No points
+// have moved, so there is nothing to undo.
+func RejectConversion(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	parentID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can reject conversions"})
+		return
+	}
+
+	var req models.ConversionDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Notes are optional
+		req = models.ConversionDecisionRequest{}
+	}
+
+	tx, conv, ok := lockConversion(c, db)
+	if !ok {
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if conv.Status != allowance.StatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot reject conversion with status: %s", conv.Status)})
+		return
+	}
+
+	err := allowance.DecideConversion(c.Request.Context(), tx, conv.ID, allowance.StatusRejected, parentID, req.Notes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update conversion", "details": err.Error()})
+		return
+	}
+
+	var body string
+	if req.Notes != nil {
+		body = *req.Notes
+	}
+	err = notify.Send(c.Request.Context(), tx, notify.Notification{
+		UserID: conv.UserID,
+		Type:   notify.TypeAllowance,
+		Title:  fmt.Sprintf("Your request to convert %d points was not approved", conv.Points),
+		Body:   body,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send notifications", "details": err.Error()})
+		return
+	}
+
+	finishConversion(c, tx, conv.ID, http.StatusOK)
+}
+
+// CancelConversion withdraws a pending conversion (the child who asked)
+func CancelConversion(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var req models.ConversionDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Notes are optional
+		req = models.ConversionDecisionRequest{}
+	}
+
+	tx, conv, ok := lockConversion(c, db)
+	if !ok {
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if conv.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only cancel your own conversions"})
+		return
+	}
+	if conv.Status != allowance.StatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot cancel conversion with status: %s", conv.Status)})
+		return
+	}
+
+	err := allowance.DecideConversion(c.Request.Context(), tx, conv.ID, allowance.StatusCancelled, userID, req.Notes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update conversion", "details": err.Error()})
+		return
+	}
+
+	finishConversion(c, tx, conv.ID, http.StatusOK)
+}
+
+// CreatePayout records money a parent handed to a child by cash, transfer or
+// gift card, and debits the child's money balance (parent only)
+func CreatePayout(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		parentID, ok := middleware.GetAuthUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			return
+		}
+		isParent, _ := middleware.GetAuthIsParent(c)
+		if !isParent {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can record payouts"})
+			return
+		}
+
+		var req models.PayoutRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		settings, ok := familySettings(c, platformDB)
+		if !ok {
+			return
+		}
+
+		tx, err := db.Begin(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+			return
+		}
+		defer tx.Rollback(c.Request.Context())
+
+		var child bool
+		err = tx.QueryRow(c.Request.Context(),
+			"SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND is_parent = false AND is_active = true)",
+			req.UserID,
+		).Scan(&child)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query user", "details": err.Error()})
+			return
+		}
+		if !child {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Child not found"})
+			return
+		}
+
+		payout, err := allowance.Payout(c.Request.Context(), tx, req, settings.Currency, parentID)
+		if err != nil {
+			if errors.Is(err, allowance.ErrInsufficientFunds) {
+				balance, _ := allowance.Balance(c.Request.Context(), tx, req.UserID, settings.Currency)
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":     "Payout is more than the balance",
+					"requested": allowance.Format(req.AmountMinor, settings.Currency),
+					"balance":   allowance.Format(balance, settings.Currency),
+				})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record payout", "details": err.Error()})
+			return
+		}
+
+		err = notify.Send(c.Request.Context(), tx, notify.Notification{
+			UserID: req.UserID,
+			Type:   notify.TypeAllowance,
+			Title:  fmt.Sprintf("You were paid %s", payout.Amount),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send notifications", "details": err.Error()})
+			return
+		}
+
+		if err = tx.Commit(c.Request.Context()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, payout)
+	}
+}
+
+// ListPayouts returns recorded payouts. Parents see everyone's and can
+// filter by ?user_id=; children see their own.
+func ListPayouts(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, ok := ownOrQueriedUser(c)
+	if !ok {
+		return
+	}
+
+	list, err := allowance.ListPayouts(c.Request.Context(), db, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query payouts", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"payouts": list,
+		"count":   len(list),
+	})
+}
+
+// GetAllowanceBalances returns money balances: every child's for a parent,
+// or the child's own
+func GetAllowanceBalances(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		userID, ok := ownOrQueriedUser(c)
+		if !ok {
+			return
+		}
+
+		settings, ok := familySettings(c, platformDB)
+		if !ok {
+			return
+		}
+
+		balances, err := allowance.Balances(c.Request.Context(), db, settings.Currency, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query balances", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"balances": balances,
+			"currency": settings.Currency,
+		})
+	}
+}
+
+// GetUserStatement returns a child's money statement between ?from= and ?to=
+// (YYYY-MM-DD, both included, in the family timezone), defaulting to the
+// current month. Children can only see their own.
+func GetUserStatement(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		authUserID, ok := middleware.GetAuthUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			return
+		}
+		isParent, _ := middleware.GetAuthIsParent(c)
+
+		userID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+			return
+		}
+		if !isParent && userID != authUserID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only view your own statement"})
+			return
+		}
+
+		settings, ok := familySettings(c, platformDB)
+		if !ok {
+			return
+		}
+		loc := settings.Location()
+
+		today := jobs.LocalDay(time.Now(), loc)
+		from := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, loc)
+		to := from.AddDate(0, 1, -1)
+		if value := c.Query("from"); value != "" {
+			if from, err = time.ParseInLocation("2006-01-02", value, loc); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, expected YYYY-MM-DD"})
+				return
+			}
+		}
+		if value := c.Query("to"); value != "" {
+			if to, err = time.ParseInLocation("2006-01-02", value, loc); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, expected YYYY-MM-DD"})
+				return
+			}
+		}
+		if to.Before(from) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+			return
+		}
+
+		statement, err := allowance.Statement(c.Request.Context(), db, userID, settings.Currency, from, to.AddDate(0, 0, 1))
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build statement", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, statement)
+	}
+}
+
+// familySettings loads the family's settings. On failure it has already
+// responded.
+func familySettings(c *gin.Context, platformDB *database.PlatformDB) (*models.FamilySettings, bool) {
+	familyID, ok := middleware.GetFamilyID(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Family context not found"})
+		return nil, false
+	}
+
+	settings, err := platformDB.GetFamilySettings(c.Request.Context(), familyID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load family settings", "details": err.Error()})
+		return nil, false
+	}
+	return settings, true
+}
+
+// exchangeRate describes the family's exchange rate
+func exchangeRate(settings *models.FamilySettings) models.ExchangeRate {
+	return models.ExchangeRate{
+		Currency:   settings.Currency,
+		Points:     settings.ExchangePoints,
+		MinorUnits: settings.ExchangeMinorUnits,
+		Amount:     allowance.Format(int64(settings.ExchangeMinorUnits), settings.Currency),
+		Enabled:    settings.ConversionEnabled(),
+	}
+}
+
+// ownOrQueriedUser returns the user a list is limited to: the caller for a
+// child, or ?user_id= (nil for everyone) for a parent. On failure it has
+// already responded.
+func ownOrQueriedUser(c *gin.Context) (*uuid.UUID, bool) {
+	authUserID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, false
+	}
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		return &authUserID, true
+	}
+
+	value := c.Query("user_id")
+	if value == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return nil, false
+	}
+	return &id, true
+}
+
+// lockConversion starts a transaction and loads the conversion in :id with
+// its row locked. On failure it has already responded and rolled back.
+func lockConversion(c *gin.Context, db *pgxpool.Pool) (pgx.Tx, models.PointConversion, bool) {
+	var conv models.PointConversion
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversion ID format"})
+		return nil, conv, false
+	}
+
+	tx, err := db.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return nil, conv, false
+	}
+
+	_, err = tx.Exec(c.Request.Context(), "SELECT 1 FROM allowance_conversions WHERE id = $1 FOR UPDATE", id)
+	if err == nil {
+		conv, err = allowance.LoadConversion(c.Request.Context(), tx, id)
+	}
+	if err != nil {
+		tx.Rollback(c.Request.Context())
+		if err.Error() == "no rows in result set" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Conversion not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query conversion", "details": err.Error()})
+		}
+		return nil, conv, false
+	}
+	return tx, conv, true
+}
+
+// convertPoints completes the conversion and tells the child their money
+// arrived. On failure it has already responded.
+func convertPoints(c *gin.Context, tx pgx.Tx, conv models.PointConversion, approvedBy *uuid.UUID) bool {
+	if err := allowance.Convert(c.Request.Context(), tx, conv, approvedBy); err != nil {
+		if errors.Is(err, ledger.ErrInsufficientPoints) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Not enough points left to convert"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to convert points", "details": err.Error()})
+		return false
+	}
+
+	err := notify.Send(c.Request.Context(), tx, notify.Notification{
+		UserID: conv.UserID,
+		Type:   notify.TypeAllowance,
+		Title:  fmt.Sprintf("%d points converted to %s", conv.Points, conv.Amount),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send notifications", "details": err.Error()})
+		return false
+	}
+	return true
+}
+
+// finishConversion reloads the conversion, commits and responds with it
+func finishConversion(c *gin.Context, tx pgx.Tx, id uuid.UUID, status int) {
+	conv, err := allowance.LoadConversion(c.Request.Context(), tx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query conversion", "details": err.Error()})
+		return
+	}
+
+	if err = tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(status, conv)
+}
+
+// conversionBody is the child's note on a conversion, if any
+func conversionBody(note *string) string {
+	if note == nil {
+		return ""
+	}
+	return *note
+}
+
+// ListAllowanceSchedules returns recurring allowance schedules. Parents see
+// everyone's and can filter by ?user_id=; children see their own.
+func ListAllowanceSchedules(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		userID, ok := ownOrQueriedUser(c)
+		if !ok {
+			return
+		}
+
+		settings, ok := familySettings(c, platformDB)
+		if !ok {
+			return
+		}
+
+		list, err := allowance.ListSchedules(c.Request.Context(), db, userID, settings.Currency)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query schedules", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"schedules": list,
+			"count":     len(list),
+		})
+	}
+}
+
+// CreateAllowanceSchedule sets up a weekly allowance for a child, in points
+// or money, paid on a weekday (parent only). The first deposit is the next
+// such day after today.
+func CreateAllowanceSchedule(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		parentID, ok := middleware.GetAuthUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			return
+		}
+		isParent, _ := middleware.GetAuthIsParent(c)
+		if !isParent {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can set up allowances"})
+			return
+		}
+
+		var req models.AllowanceScheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		settings, ok := familySettings(c, platformDB)
+		if !ok {
+			return
+		}
+
+		var child bool
+		err := db.QueryRow(c.Request.Context(),
+			"SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND is_parent = false AND is_active = true)",
+			req.UserID,
+		).Scan(&child)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query user", "details": err.Error()})
+			return
+		}
+		if !child {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Child not found"})
+			return
+		}
+
+		id, err := allowance.CreateSchedule(c.Request.Context(), db, req, parentID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule", "details": err.Error()})
+			return
+		}
+
+		schedule, err := allowance.LoadSchedule(c.Request.Context(), db, id, settings.Currency)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query schedule", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, schedule)
+	}
+}
+
+// UpdateAllowanceSchedule changes a schedule's amount, weekday, minimum
+// completion rate or description, or pauses it (parent only). Deposits
+// already made are not affected.
+func UpdateAllowanceSchedule(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		isParent, _ := middleware.GetAuthIsParent(c)
+		if !isParent {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can change allowances"})
+			return
+		}
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID format"})
+			return
+		}
+
+		var req models.AllowanceScheduleUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		settings, ok := familySettings(c, platformDB)
+		if !ok {
+			return
+		}
+
+		if _, err := allowance.LoadSchedule(c.Request.Context(), db, id, settings.Currency); err != nil {
+			if err.Error() == "no rows in result set" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query schedule", "details": err.Error()})
+			return
+		}
+
+		if err := allowance.UpdateSchedule(c.Request.Context(), db, id, req); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule", "details": err.Error()})
+			return
+		}
+
+		schedule, err := allowance.LoadSchedule(c.Request.Context(), db, id, settings.Currency)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query schedule", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, schedule)
+	}
+}
+
+// DeleteAllowanceSchedule removes a schedule and its deposit history
+// (parent only). Points and money already deposited stay with the child.
+func DeleteAllowanceSchedule(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can remove allowances"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID format"})
+		return
+	}
+
+	result, err := db.Exec(c.Request.Context(), "DELETE FROM allowance_schedules WHERE id = $1", id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete schedule", "details": err.Error()})
+		return
+	}
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Schedule deleted successfully"})
+}
+
+// ListAllowanceDeposits returns scheduled allowance deposits, paid and
+// skipped. Parents see everyone's and can filter by ?user_id=; children see
+// their own.
+func ListAllowanceDeposits(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, ok := ownOrQueriedUser(c)
+	if !ok {
+		return
+	}
+
+	list, err := allowance.ListDeposits(c.Request.Context(), db, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query deposits", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deposits": list,
+		"count":    len(list),
+	})
+}
@@ -1,37 +1,99 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/JunoAX/housepoints-go/internal/checklist"
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
 	"github.com/JunoAX/housepoints-go/internal/middleware"
 	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/schedule"
+	"github.com/JunoAX/housepoints-go/internal/sequence"
+	"github.com/JunoAX/housepoints-go/internal/teams"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 // CreateAssignment creates a new assignment (parent only)
-func CreateAssignment(c *gin.Context) {
-	db, ok := middleware.GetFamilyDB(c)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
-		return
-	}
+func CreateAssignment(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
 
-	// Check if user is a parent
-	isParent, _ := middleware.GetAuthIsParent(c)
-	if !isParent {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can create assignments"})
-		return
-	}
+		// Check if user is a parent
+		isParent, _ := middleware.GetAuthIsParent(c)
+		if !isParent {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can create assignments"})
+			return
+		}
+
+		userID, _ := middleware.GetAuthUserID(c)
+
+		var req models.AssignmentCreateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		tx, err := db.Begin(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+			return
+		}
+		defer tx.Rollback(c.Request.Context())
+
+		created, err := createAssignment(c.Request.Context(), tx, req, userID, familyLocation(c, platformDB))
+		if err != nil {
+			respondAssignmentError(c, err, "Failed to create assignment")
+			return
+		}
 
-	userID, _ := middleware.GetAuthUserID(c)
+		if err = tx.Commit(c.Request.Context()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+			return
+		}
 
-	var req models.AssignmentCreateRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
-		return
+		c.JSON(http.StatusCreated, gin.H{
+			"id":             created.ID,
+			"chore_id":       req.ChoreID,
+			"assigned_to":    created.AssignedTo,
+			"assigned_by":    userID,
+			"status":         created.Status,
+			"points_offered": created.PointsOffered,
+			"due_date":       created.DueDate.Format(time.RFC3339),
+			"is_team":        created.SplitMode != nil,
+			"split_mode":     created.SplitMode,
+			"participants":   created.Participants,
+			"warnings":       created.Warnings,
+			"message":        "Assignment created successfully",
+		})
 	}
+}
+
+// createdAssignment describes an assignment createAssignment inserted
+type createdAssignment struct {
+	ID            uuid.UUID
+	AssignedTo    *uuid.UUID
+	Status        string
+	PointsOffered int
+	DueDate       time.Time
+	SplitMode     *string
+	Participants  []uuid.UUID
+	Warnings      []string
+}
+
+// createAssignment validates and inserts one assignment. Dates without a time
+// are days in loc, the family's time zone. Problems with the request come
+// back as a *requestError. q should be a transaction.
+func createAssignment(ctx context.Context, q database.Querier, req models.AssignmentCreateRequest, userID uuid.UUID, loc *time.Location) (createdAssignment, error) {
+	var created createdAssignment
 
 	// Set default points if not provided
 	if req.PointsOffered == 0 {
@@ -40,20 +102,58 @@ func CreateAssignment(c *gin.Context) {
 
 	// Check if chore exists
 	var choreName string
-	err := db.QueryRow(c.Request.Context(), "SELECT name FROM chores WHERE id = $1", req.ChoreID).Scan(&choreName)
+	err := q.QueryRow(ctx, "SELECT name FROM chores WHERE id = $1", req.ChoreID).Scan(&choreName)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Chore not found"})
-		return
+		return created, &requestError{message: "Chore not found"}
+	}
+
+	// A team assignment leads with its first participant
+	var splitMode *string
+	if len(req.Participants) > 0 {
+		if len(req.Participants) < 2 {
+			return created, &requestError{message: "A team assignment needs at least two participants"}
+		}
+		seen := map[uuid.UUID]bool{}
+		for _, p := range req.Participants {
+			if seen[p.UserID] {
+				return created, &requestError{message: "Each participant can only be listed once"}
+			}
+			seen[p.UserID] = true
+		}
+		if req.AssignedTo != nil && *req.AssignedTo != req.Participants[0].UserID {
+			return created, &requestError{message: "assigned_to must be omitted or match the first participant"}
+		}
+		lead := req.Participants[0].UserID
+		req.AssignedTo = &lead
+
+		mode := req.SplitMode
+		if mode == "" {
+			mode = teams.SplitEven
+		}
+		splitMode = &mode
+	} else if req.SplitMode != "" {
+		return created, &requestError{message: "split_mode only applies to team assignments"}
+	}
+
+	// Check if assigned users exist (if provided)
+	assignees := []uuid.UUID{}
+	if len(req.Participants) > 0 {
+		for _, p := range req.Participants {
+			assignees = append(assignees, p.UserID)
+		}
+	} else if req.AssignedTo != nil {
+		assignees = append(assignees, *req.AssignedTo)
 	}
 
-	// Check if assigned user exists (if provided)
-	var assigneeName *string
-	if req.AssignedTo != nil {
-		err := db.QueryRow(c.Request.Context(), "SELECT display_name FROM users WHERE id = $1", req.AssignedTo).Scan(&assigneeName)
+	type assignee struct{ name, username string }
+	assigneeInfo := map[uuid.UUID]assignee{}
+	for _, id := range assignees {
+		var a assignee
+		err := q.QueryRow(ctx, "SELECT display_name, username FROM users WHERE id = $1", id).Scan(&a.name, &a.username)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Assigned user not found"})
-			return
+			return created, &requestError{message: "Assigned user not found", fields: gin.H{"user_id": id}}
 		}
+		assigneeInfo[id] = a
 	}
 
 	// Parse due date or default to end of today
@@ -63,26 +163,45 @@ func CreateAssignment(c *gin.Context) {
 		parsed, err := time.Parse(time.RFC3339, *req.DueDate)
 		if err != nil {
 			// Try parsing as date only (YYYY-MM-DD)
-			parsed, err = time.Parse("2006-01-02", *req.DueDate)
+			parsed, err = time.ParseInLocation("2006-01-02", *req.DueDate, loc)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid due_date format. Use YYYY-MM-DD or RFC3339"})
-				return
+				return created, &requestError{message: "Invalid due_date format. Use YYYY-MM-DD or RFC3339"}
 			}
 			// Set to end of day
 			dueDate = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 23, 59, 59, 0, parsed.Location())
 		} else {
-			dueDate = parsed
+			dueDate = parsed.In(loc)
 		}
 	} else {
 		// Default to end of today
-		now := time.Now()
+		now := time.Now().In(loc)
 		dueDate = time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
 	}
 
+	// Warn when assigning to a child who is away on the due date
+	warnings := []string{}
+	if len(assignees) > 0 {
+		presence, err := schedule.LoadPresence(ctx, q, dueDate)
+		if err != nil {
+			return created, fmt.Errorf("failed to query schedule: %w", err)
+		}
+		for _, id := range assignees {
+			a := assigneeInfo[id]
+			if presence.IsPresent(a.username, a.name) {
+				continue
+			}
+			warning, err := absenceWarning(ctx, q, a.username, a.name, dueDate)
+			if err != nil {
+				return created, fmt.Errorf("failed to query schedule: %w", err)
+			}
+			warnings = append(warnings, warning)
+		}
+	}
+
 	// Determine status based on whether it's assigned
-	status := "pending"
+	status := lifecycle.StatusPending
 	if req.AssignedTo == nil {
-		status = "open"
+		status = lifecycle.StatusOpen
 	}
 
 	// Create assignment
@@ -90,31 +209,69 @@ func CreateAssignment(c *gin.Context) {
 	query := `
 		INSERT INTO assignments (
 			id, chore_id, assigned_to, assigned_by, status,
-			points_offered, due_date, created_at
+			points_offered, due_date, is_team, split_mode, created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
 		RETURNING id
 	`
 
 	var returnedID uuid.UUID
-	err = db.QueryRow(c.Request.Context(), query,
+	err = q.QueryRow(ctx, query,
 		assignmentID, req.ChoreID, req.AssignedTo, userID, status,
-		req.PointsOffered, dueDate,
+		req.PointsOffered, dueDate, splitMode != nil, splitMode,
 	).Scan(&returnedID)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create assignment", "details": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusCreated, gin.H{
-		"id":             returnedID,
-		"chore_id":       req.ChoreID,
-		"assigned_to":    req.AssignedTo,
-		"assigned_by":    userID,
-		"status":         status,
-		"points_offered": req.PointsOffered,
-		"due_date":       dueDate.Format(time.RFC3339),
-		"message":        "Assignment created successfully",
+		return created, fmt.Errorf("failed to create assignment: %w", err)
+	}
+
+	if err = teams.Add(ctx, q, returnedID, req.Participants); err != nil {
+		return created, fmt.Errorf("failed to add participants: %w", err)
+	}
+
+	if err = checklist.Snapshot(ctx, q, returnedID); err != nil {
+		return created, fmt.Errorf("failed to copy checklist: %w", err)
+	}
+
+	// Link to (or from) other chores due the same day that this one depends on
+	dayStart := time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, loc)
+	if err = sequence.Link(ctx, q, dayStart); err != nil {
+		return created, fmt.Errorf("failed to link prerequisites: %w", err)
+	}
+
+	err = lifecycle.Record(ctx, q, lifecycle.Change{
+		AssignmentID: returnedID,
+		Event:        lifecycle.EventCreated,
+		To:           status,
+		ActorID:      &userID,
 	})
+	if err != nil {
+		return created, err
+	}
+
+	return createdAssignment{
+		ID:            returnedID,
+		AssignedTo:    req.AssignedTo,
+		Status:        status,
+		PointsOffered: req.PointsOffered,
+		DueDate:       dueDate,
+		SplitMode:     splitMode,
+		Participants:  assignees,
+		Warnings:      warnings,
+	}, nil
+}
+
+// absenceWarning describes a child being away on day, with the next day they
+// are at home if the schedule has one
+func absenceWarning(ctx context.Context, q database.Querier, username, name string, day time.Time) (string, error) {
+	warning := fmt.Sprintf("%s is not present on %s according to the family schedule",
+		name, day.Format("2006-01-02"))
+	next, found, err := schedule.NextPresentDay(ctx, q, username, name, day.AddDate(0, 0, 1))
+	if err != nil {
+		return "", err
+	}
+	if found {
+		warning += fmt.Sprintf("; next day at home is %s", next.Format("2006-01-02"))
+	}
+	return warning, nil
 }
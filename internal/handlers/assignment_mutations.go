@@ -1,10 +1,27 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/rand/v2"
+	"mime/multipart"
 	"net/http"
+	"time"
 
+	"github.com/JunoAX/housepoints-go/internal/checklist"
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
 	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/notify"
+	"github.com/JunoAX/housepoints-go/internal/scoring"
+	"github.com/JunoAX/housepoints-go/internal/storage"
+	"github.com/JunoAX/housepoints-go/internal/teams"
+	"github.com/JunoAX/housepoints-go/internal/timetrack"
+	"github.com/JunoAX/housepoints-go/internal/timing"
+	"github.com/JunoAX/housepoints-go/internal/trades"
+	"github.com/JunoAX/housepoints-go/internal/verification"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -21,9 +38,18 @@ type CompleteAssignmentRequest struct {
 
 // VerifyAssignmentRequest is the request body for verification
 type VerifyAssignmentRequest struct {
-	Approved          bool    `json:"approved"`
-	PointsAwarded     *int    `json:"points_awarded,omitempty"`
-	VerificationNotes *string `json:"verification_notes,omitempty"`
+	Approved             bool    `json:"approved"`
+	PointsAwarded        *int    `json:"points_awarded,omitempty"`
+	QualityRating        *int    `json:"quality_rating,omitempty" binding:"omitempty,min=1,max=5"`
+	PartialCreditPercent *int    `json:"partial_credit_percent,omitempty" binding:"omitempty,min=1,max=100"`
+	EffortBonusPercent   *int    `json:"effort_bonus_percent,omitempty" binding:"omitempty,min=0"`
+	VerificationNotes    *string `json:"verification_notes,omitempty"`
+	// RejectionReason and RedoDueDate apply when sending work back for rework.
+	// RedoDueDate is YYYY-MM-DD or RFC 3339.
+	RejectionReason *string `json:"rejection_reason,omitempty"`
+	RedoDueDate     *string `json:"redo_due_date,omitempty"`
+	// ParticipantPoints splits a team chore by hand, keyed by user ID
+	ParticipantPoints map[uuid.UUID]int `json:"participant_points,omitempty"`
 }
 
 // ClaimAssignment allows a user to claim an open bonus assignment
@@ -56,11 +82,14 @@ func ClaimAssignment(c *gin.Context) {
 	defer tx.Rollback(c.Request.Context())
 
 	// Check assignment exists and is open
-	var status string
+	var (
+		status  string
+		version int
+	)
 	err = tx.QueryRow(c.Request.Context(),
-		"SELECT status FROM assignments WHERE id = $1",
+		"SELECT status, version FROM assignments WHERE id = $1 FOR UPDATE",
 		assignmentID,
-	).Scan(&status)
+	).Scan(&status, &version)
 
 	if err != nil {
 		if err.Error() == "no rows in result set" {
@@ -71,16 +100,36 @@ func ClaimAssignment(c *gin.Context) {
 		return
 	}
 
-	if status != "open" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Assignment is not available to claim"})
+	if !checkIfMatch(c, version) {
+		return
+	}
+
+	// Someone else got there first
+	if status != lifecycle.StatusOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Assignment is not available to claim", "status": status})
+		return
+	}
+
+	if respondIfBlocked(c, tx, assignmentID) {
 		return
 	}
 
 	// Claim the assignment
+	err = lifecycle.Apply(c.Request.Context(), tx, lifecycle.Change{
+		AssignmentID: assignmentID,
+		Event:        lifecycle.EventClaimed,
+		From:         status,
+		To:           lifecycle.StatusPending,
+		ActorID:      &userID,
+	})
+	if err != nil {
+		respondTransitionError(c, err)
+		return
+	}
+
 	_, err = tx.Exec(c.Request.Context(), `
 		UPDATE assignments
 		SET assigned_to = $1,
-			status = 'pending',
 			updated_at = NOW()
 		WHERE id = $2
 	`, userID, assignmentID)
@@ -90,174 +139,448 @@ func ClaimAssignment(c *gin.Context) {
 		return
 	}
 
+	if version, err = assignmentVersion(c.Request.Context(), tx, assignmentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+		return
+	}
+
 	// Commit transaction
 	if err = tx.Commit(c.Request.Context()); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
 		return
 	}
 
+	setETag(c, version)
 	c.JSON(http.StatusOK, gin.H{
 		"message":       "Assignment claimed successfully",
 		"assignment_id": assignmentID,
-		"status":        "pending",
+		"status":        lifecycle.StatusPending,
+		"version":       version,
 	})
 }
 
-// CompleteAssignment marks an assignment as completed
-func CompleteAssignment(c *gin.Context) {
-	db, ok := middleware.GetFamilyDB(c)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
-		return
-	}
+// CompleteAssignment marks an assignment as completed. It accepts JSON or a
+// multipart form with notes plus optional "photo" (after) and "before_photo"
+// files; chores that require a photo cannot be completed without one.
+func CompleteAssignment(store storage.Store, platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
 
-	assignmentIDParam := c.Param("id")
-	assignmentID, err := uuid.Parse(assignmentIDParam)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID format"})
-		return
-	}
+		assignmentIDParam := c.Param("id")
+		assignmentID, err := uuid.Parse(assignmentIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID format"})
+			return
+		}
 
-	userID, ok := middleware.GetAuthUserID(c)
-	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
-		return
-	}
+		userID, ok := middleware.GetAuthUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+			return
+		}
 
-	isParent, _ := middleware.GetAuthIsParent(c)
+		isParent, _ := middleware.GetAuthIsParent(c)
 
-	var req CompleteAssignmentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		// Notes are optional, so empty body is fine
-		req = CompleteAssignmentRequest{}
-	}
+		var req CompleteAssignmentRequest
+		uploads := map[string]*multipart.FileHeader{}
+		if c.ContentType() == "multipart/form-data" {
+			if notes := c.PostForm("notes"); notes != "" {
+				req.Notes = &notes
+			}
+			if file, err := c.FormFile("before_photo"); err == nil {
+				uploads["before"] = file
+			}
+			if file, err := c.FormFile("photo"); err == nil {
+				uploads["after"] = file
+			}
+		} else if err := c.ShouldBindJSON(&req); err != nil {
+			// Notes are optional, so empty body is fine
+			req = CompleteAssignmentRequest{}
+		}
 
-	// Start transaction
-	tx, err := db.Begin(c.Request.Context())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
-		return
-	}
-	defer tx.Rollback(c.Request.Context())
+		// Start transaction
+		tx, err := db.Begin(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+			return
+		}
+		defer tx.Rollback(c.Request.Context())
 
-	// Get assignment details
-	var (
-		assignedTo           *uuid.UUID
-		status               string
-		requiresVerification bool
-		pointsOffered        int
-	)
+		// Get assignment details
+		var (
+			assignedTo           *uuid.UUID
+			status               string
+			requiresVerification bool
+			requiresPhoto        bool
+			pointsOffered        int
+			bonusEligible        bool
+			isTeam               bool
+			version              int
+			dueDate              *time.Time
+			windowStart          *int
+			windowEnd            *int
+			lateCutoff           *int
+		)
 
-	err = tx.QueryRow(c.Request.Context(), `
-		SELECT a.assigned_to, a.status, c.requires_verification, a.points_offered
-		FROM assignments a
-		JOIN chores c ON a.chore_id = c.id
-		WHERE a.id = $1
-	`, assignmentID).Scan(&assignedTo, &status, &requiresVerification, &pointsOffered)
+		err = tx.QueryRow(c.Request.Context(), `
+			SELECT a.assigned_to, a.status, c.requires_verification, COALESCE(c.requires_photo, false), a.points_offered,
+				COALESCE(c.bonus_eligible, false), a.is_team, a.version,
+				a.due_date, c.window_start_minute, c.window_end_minute, c.late_cutoff_minutes
+			FROM assignments a
+			JOIN chores c ON a.chore_id = c.id
+			WHERE a.id = $1
+			FOR UPDATE OF a
+		`, assignmentID).Scan(&assignedTo, &status, &requiresVerification, &requiresPhoto, &pointsOffered, &bonusEligible, &isTeam, &version,
+			&dueDate, &windowStart, &windowEnd, &lateCutoff)
 
-	if err != nil {
-		if err.Error() == "no rows in result set" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+			}
+			return
 		}
-		return
-	}
 
-	// Check permission - only assigned user or parent can complete
-	if !isParent {
-		if assignedTo == nil || *assignedTo != userID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to complete this assignment"})
+		var participants []models.AssignmentParticipant
+		if isTeam {
+			if participants, err = teams.Load(c.Request.Context(), tx, assignmentID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query participants", "details": err.Error()})
+				return
+			}
+		}
+
+		// Check permission - only assigned user (or team member) or parent can complete
+		if !isParent {
+			allowed := assignedTo != nil && *assignedTo == userID
+			if isTeam {
+				allowed = teams.IsParticipant(participants, userID)
+			}
+			if !allowed {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to complete this assignment"})
+				return
+			}
+		}
+
+		if !checkIfMatch(c, version) {
 			return
 		}
-	}
 
-	// Check status
-	if status != "pending" && status != "in_progress" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Cannot complete assignment with status: %s", status)})
-		return
-	}
+		// Check status
+		if !lifecycle.CanTransition(status, lifecycle.StatusPendingVerification) {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot complete assignment with status: %s", status)})
+			return
+		}
+
+		if respondIfBlocked(c, tx, assignmentID) {
+			return
+		}
+
+		if requiresPhoto && uploads["after"] == nil {
+			var hasPhoto bool
+			err = tx.QueryRow(c.Request.Context(),
+				"SELECT EXISTS(SELECT 1 FROM assignment_photos WHERE assignment_id = $1 AND kind = 'after')",
+				assignmentID,
+			).Scan(&hasPhoto)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check photos", "details": err.Error()})
+				return
+			}
+			if !hasPhoto {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "This chore requires a photo of the finished work"})
+				return
+			}
+		}
+
+		// Photos sent with the completion are stored just before committing.
+		// Their blobs are removed again if the transaction does not commit.
+		familySlug, _ := middleware.GetFamilySlug(c)
+		var storedKeys []string
+		committed := false
+		defer func() {
+			if !committed {
+				deletePhotos(context.Background(), store, storedKeys)
+			}
+		}()
+		storeUploads := func() bool {
+			for _, kind := range []string{"before", "after"} {
+				if file, ok := uploads[kind]; ok {
+					_, keys, err := savePhoto(c.Request.Context(), tx, store, familySlug, assignmentID, userID, kind, file)
+					storedKeys = append(storedKeys, keys...)
+					if err != nil {
+						respondPhotoError(c, err)
+						return false
+					}
+				}
+			}
+			return true
+		}
+
+		// A team chore only goes to verification once everyone has done their
+		// part. A parent who is not on the team completes it for everyone.
+		if isTeam {
+			all := isParent && !teams.IsParticipant(participants, userID)
+			done, err := teams.MarkDone(c.Request.Context(), tx, assignmentID, userID, all, req.Notes)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record completion", "details": err.Error()})
+				return
+			}
 
-	// Determine new status
-	var newStatus string
-	var pointsEarned int
+			if !done {
+				if _, err = timetrack.Stop(c.Request.Context(), tx, assignmentID, &userID); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stop timer", "details": err.Error()})
+					return
+				}
 
-	if isParent && assignedTo != nil && *assignedTo == userID {
-		// Parent completing their own chore
-		if requiresVerification {
-			newStatus = "pending_verification"
+				change := lifecycle.Change{
+					AssignmentID: assignmentID,
+					Event:        lifecycle.EventParticipantDone,
+					From:         status,
+					ActorID:      &userID,
+					Notes:        req.Notes,
+				}
+				if status != lifecycle.StatusInProgress {
+					change.To = lifecycle.StatusInProgress
+					err = lifecycle.Apply(c.Request.Context(), tx, change)
+				} else {
+					err = lifecycle.Record(c.Request.Context(), tx, change)
+				}
+				if err != nil {
+					respondTransitionError(c, err)
+					return
+				}
+
+				if participants, err = teams.Load(c.Request.Context(), tx, assignmentID); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query participants", "details": err.Error()})
+					return
+				}
+				if version, err = assignmentVersion(c.Request.Context(), tx, assignmentID); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+					return
+				}
+
+				if !storeUploads() {
+					return
+				}
+				if err = tx.Commit(c.Request.Context()); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+					return
+				}
+				committed = true
+
+				setETag(c, version)
+				c.JSON(http.StatusOK, gin.H{
+					"message":       "Your part is done; waiting for the rest of the team",
+					"assignment_id": assignmentID,
+					"status":        lifecycle.StatusInProgress,
+					"participants":  participants,
+					"version":       version,
+				})
+				return
+			}
+		}
+
+		// Every required checklist item has to be ticked before the chore
+		// goes to a parent
+		outstanding, err := checklist.Outstanding(c.Request.Context(), tx, assignmentID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check checklist", "details": err.Error()})
+			return
+		}
+		if len(outstanding) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":       "Finish the required checklist items first",
+				"outstanding": outstanding,
+			})
+			return
+		}
+
+		// Finished work stops every timer still running on it
+		if _, err = timetrack.Stop(c.Request.Context(), tx, assignmentID, nil); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stop timer", "details": err.Error()})
+			return
+		}
+
+		// Judge the timing now, in the family's timezone; verification later
+		// scores the stored result
+		var (
+			timed         *timing.Result
+			timingLabel   *string
+			timingPercent *int
+		)
+		if dueDate != nil {
+			var window *timing.Window
+			if windowStart != nil && windowEnd != nil {
+				window = &timing.Window{Start: *windowStart, End: *windowEnd}
+			}
+			curve := timing.LoadCurve(c.Request.Context(), tx, lateCutoff)
+			r := timing.Evaluate(curve, *dueDate, window, time.Now(), familyLocation(c, platformDB))
+			timed, timingLabel, timingPercent = &r, &r.Timing, &r.Percent
+		}
+		early := timed != nil && timed.Timing == timing.Early
+
+		// Determine new status
+		var newStatus string
+		var pointsEarned int
+		var decision *verification.Decision
+
+		if isTeam {
+			// Team work always goes to a parent, who decides the split
+			newStatus = lifecycle.StatusPendingVerification
 			pointsEarned = 0
+		} else if isParent && assignedTo != nil && *assignedTo == userID {
+			// Parent completing their own chore
+			if requiresVerification {
+				newStatus = lifecycle.StatusPendingVerification
+				pointsEarned = 0
+			} else {
+				newStatus = lifecycle.StatusVerified
+				pointsEarned = pointsOffered
+			}
 		} else {
-			newStatus = "verified"
-			pointsEarned = pointsOffered
-		}
-	} else {
-		// Child completing chore - always needs verification
-		newStatus = "pending_verification"
-		pointsEarned = 0
-	}
+			newStatus = lifecycle.StatusPendingVerification
+			pointsEarned = 0
 
-	// Update assignment
-	_, err = tx.Exec(c.Request.Context(), `
-		UPDATE assignments
-		SET status = $1,
-			completed_at = NOW(),
-			completion_notes = $2,
-			points_earned = $3,
-			updated_at = NOW()
-		WHERE id = $4
-	`, newStatus, req.Notes, pointsEarned, assignmentID)
+			// Child completing their own chore - trusted children may skip verification
+			if !isParent && assignedTo != nil {
+				subject, err := verification.LoadSubject(c.Request.Context(), tx, *assignedTo)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query user trust", "details": err.Error()})
+					return
+				}
 
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update assignment", "details": err.Error()})
-		return
-	}
+				d := verification.Decide(
+					verification.LoadPolicy(c.Request.Context(), tx),
+					subject,
+					verification.Work{Points: pointsOffered, RequiresVerification: requiresVerification},
+					rand.Float64(),
+				)
+				decision = &d
 
-	// If auto-verified, create point transaction
-	if newStatus == "verified" && assignedTo != nil {
-		_, err = tx.Exec(c.Request.Context(), `
-			INSERT INTO point_transactions (
-				id, user_id, points, transaction_type, description,
-				related_assignment_id, created_at
-			) VALUES ($1, $2, $3, $4, $5, $6, NOW())
-		`, uuid.New(), *assignedTo, pointsOffered, "chore_completion",
-			"Completed chore", assignmentID)
+				if d.AutoApprove {
+					newStatus = lifecycle.StatusVerified
+					pointsEarned = pointsOffered
+				}
+			}
+		}
+
+		// Work that skips verification is scored without a parent's judgement
+		var lines []scoring.Line
+		event := lifecycle.EventSubmitted
+		if newStatus == lifecycle.StatusVerified {
+			event = lifecycle.EventVerified
+			lines, _ = scoring.Score(scoring.LoadRules(c.Request.Context(), tx), scoring.Input{
+				PointsOffered: pointsOffered,
+				TimingPercent: timingPercent,
+				BonusEligible: bonusEligible,
+				Early:         early,
+			})
+			pointsEarned = scoring.Total(lines)
+		}
 
+		metadata := map[string]interface{}{}
+		if decision != nil {
+			metadata["verification"] = decision
+		}
+		if timed != nil {
+			metadata["timing"] = timed
+		}
+
+		err = lifecycle.Apply(c.Request.Context(), tx, lifecycle.Change{
+			AssignmentID: assignmentID,
+			Event:        event,
+			From:         status,
+			To:           newStatus,
+			ActorID:      &userID,
+			Notes:        req.Notes,
+			PointsDelta:  pointsEarned,
+			Metadata:     metadata,
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create point transaction", "details": err.Error()})
+			respondTransitionError(c, err)
 			return
 		}
 
-		// Update user points
+		// Update assignment
 		_, err = tx.Exec(c.Request.Context(), `
-			UPDATE users
-			SET total_points = total_points + $1,
-				available_points = available_points + $1,
-				weekly_points = weekly_points + $1,
-				lifetime_points_earned = lifetime_points_earned + $1,
+			UPDATE assignments
+			SET completed_at = NOW(),
+				verified_at = CASE WHEN $1 THEN NOW() ELSE verified_at END,
+				completion_notes = $2,
+				points_earned = $3,
+				timing = $4,
+				timing_percent = $5,
 				updated_at = NOW()
-			WHERE id = $2
-		`, pointsOffered, *assignedTo)
+			WHERE id = $6
+		`, newStatus == lifecycle.StatusVerified, req.Notes, pointsEarned, timingLabel, timingPercent, assignmentID)
 
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user points", "details": err.Error()})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update assignment", "details": err.Error()})
 			return
 		}
-	}
 
-	// Commit transaction
-	if err = tx.Commit(c.Request.Context()); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
-		return
-	}
+		// Keep every completion round; a redo does not overwrite the last one
+		round, err := recordSubmission(c.Request.Context(), tx, assignmentID, userID, req.Notes)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record submission", "details": err.Error()})
+			return
+		}
+		if newStatus == lifecycle.StatusVerified {
+			err = reviewSubmission(c.Request.Context(), tx, assignmentID, submissionReview{
+				Outcome:       outcomeVerified,
+				PointsAwarded: &pointsEarned,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record submission", "details": err.Error()})
+				return
+			}
+			if err = notifyReleased(c.Request.Context(), tx, assignmentID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to notify dependent chores", "details": err.Error()})
+				return
+			}
+		}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":        "Assignment completed successfully",
-		"assignment_id":  assignmentID,
-		"status":         newStatus,
-		"points_earned":  pointsEarned,
-		"requires_verification": newStatus == "pending_verification",
-	})
+		// If auto-verified, create point transactions
+		if newStatus == lifecycle.StatusVerified && assignedTo != nil {
+			if err = scoring.Award(c.Request.Context(), tx, *assignedTo, assignmentID, lines); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to award points", "details": err.Error()})
+				return
+			}
+		}
+
+		if version, err = assignmentVersion(c.Request.Context(), tx, assignmentID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+			return
+		}
+
+		if !storeUploads() {
+			return
+		}
+
+		// Commit transaction
+		if err = tx.Commit(c.Request.Context()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+			return
+		}
+		committed = true
+
+		setETag(c, version)
+		c.JSON(http.StatusOK, gin.H{
+			"message":        "Assignment completed successfully",
+			"assignment_id":  assignmentID,
+			"status":         newStatus,
+			"points_earned":  pointsEarned,
+			"requires_verification": newStatus == lifecycle.StatusPendingVerification,
+			"verification":   decision,
+			"timing":         timed,
+			"round":          round,
+			"version":        version,
+		})
+	}
 }
 
 // VerifyAssignment verifies a completed assignment (parent only)
@@ -287,6 +610,11 @@ func VerifyAssignment(c *gin.Context) {
 		return
 	}
 
+	if req.PointsAwarded != nil && req.PartialCreditPercent != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use either points_awarded or partial_credit_percent, not both"})
+		return
+	}
+
 	// Start transaction
 	tx, err := db.Begin(c.Request.Context())
 	if err != nil {
@@ -296,18 +624,7 @@ func VerifyAssignment(c *gin.Context) {
 	defer tx.Rollback(c.Request.Context())
 
 	// Get assignment details
-	var (
-		assignedTo    *uuid.UUID
-		status        string
-		pointsOffered int
-	)
-
-	err = tx.QueryRow(c.Request.Context(), `
-		SELECT assigned_to, status, points_offered
-		FROM assignments
-		WHERE id = $1
-	`, assignmentID).Scan(&assignedTo, &status, &pointsOffered)
-
+	t, err := lockVerifyTarget(c.Request.Context(), tx, assignmentID)
 	if err != nil {
 		if err.Error() == "no rows in result set" {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
@@ -316,108 +633,576 @@ func VerifyAssignment(c *gin.Context) {
 		}
 		return
 	}
+	version := t.Version
+
+	if !checkIfMatch(c, version) {
+		return
+	}
 
 	// Check status
-	if status != "completed" && status != "pending_verification" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Cannot verify assignment with status: %s", status)})
+	if t.Status != lifecycle.StatusCompleted && t.Status != lifecycle.StatusPendingVerification {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot verify assignment with status: %s", t.Status)})
 		return
 	}
 
-	if assignedTo == nil {
+	verifierID, _ := middleware.GetAuthUserID(c)
+
+	if t.AssignedTo == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Assignment has no assignee"})
 		return
 	}
 
-	// Handle rejection
+	// Everyone who did the work: the team, or the single assignee
+	var participants []models.AssignmentParticipant
+	workers := []uuid.UUID{*t.AssignedTo}
+	if t.IsTeam {
+		if participants, err = teams.Load(c.Request.Context(), tx, assignmentID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query participants", "details": err.Error()})
+			return
+		}
+		workers = workers[:0]
+		for _, p := range participants {
+			workers = append(workers, p.UserID)
+		}
+	} else if len(req.ParticipantPoints) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "participant_points only applies to team assignments"})
+		return
+	}
+
+	// Handle rejection. The work goes back to the child with a reason and a
+	// redo deadline; earlier rounds are kept in assignment_submissions.
 	if !req.Approved {
-		_, err = tx.Exec(c.Request.Context(), `
+		reason := req.RejectionReason
+		if reason == nil || *reason == "" {
+			reason = req.VerificationNotes
+		}
+		if reason == nil || *reason == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "rejection_reason is required when sending work back"})
+			return
+		}
+
+		var redoDue *time.Time
+		if req.RedoDueDate != nil && *req.RedoDueDate != "" {
+			parsed, err := parseRedoDueDate(*req.RedoDueDate)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid redo_due_date. Use YYYY-MM-DD or RFC 3339"})
+				return
+			}
+			if !parsed.After(time.Now()) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "redo_due_date must be in the future"})
+				return
+			}
+			redoDue = &parsed
+		}
+
+		err = lifecycle.Apply(c.Request.Context(), tx, lifecycle.Change{
+			AssignmentID: assignmentID,
+			Event:        lifecycle.EventRejected,
+			From:         t.Status,
+			To:           lifecycle.StatusNeedsRework,
+			ActorID:      &verifierID,
+			Notes:        reason,
+			Metadata:     map[string]interface{}{"redo_due_date": redoDue},
+		})
+		if err != nil {
+			respondTransitionError(c, err)
+			return
+		}
+
+		// Without an explicit deadline, work whose due date has passed gets
+		// until the end of tomorrow so it is not marked overdue straight away
+		err = tx.QueryRow(c.Request.Context(), `
 			UPDATE assignments
-			SET status = 'pending',
-				verification_notes = $1,
+			SET verification_notes = $1,
+				rework_count = rework_count + 1,
+				rework_reason = $2,
+				due_date = COALESCE($3, CASE
+					WHEN due_date < NOW() THEN DATE_TRUNC('day', NOW()) + INTERVAL '1 day 23:59:59'
+					ELSE due_date
+				END),
 				updated_at = NOW()
-			WHERE id = $2
-		`, req.VerificationNotes, assignmentID)
+			WHERE id = $4
+			RETURNING due_date
+		`, req.VerificationNotes, *reason, redoDue, assignmentID).Scan(&redoDue)
 
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reject assignment", "details": err.Error()})
 			return
 		}
 
+		if _, err = tx.Exec(c.Request.Context(),
+			"UPDATE assignments SET rework_due_date = due_date WHERE id = $1", assignmentID,
+		); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reject assignment", "details": err.Error()})
+			return
+		}
+
+		err = reviewSubmission(c.Request.Context(), tx, assignmentID, submissionReview{
+			Outcome:         outcomeNeedsRework,
+			ReviewedBy:      &verifierID,
+			Notes:           req.VerificationNotes,
+			RejectionReason: reason,
+			RedoDueDate:     redoDue,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record review", "details": err.Error()})
+			return
+		}
+
+		body := fmt.Sprintf("%s: %s", t.ChoreName, *reason)
+		if redoDue != nil {
+			body += fmt.Sprintf(" Please redo it by %s.", redoDue.Format("Mon Jan 2"))
+		}
+		if t.IsTeam {
+			if err = teams.Reset(c.Request.Context(), tx, assignmentID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset team", "details": err.Error()})
+				return
+			}
+		}
+
+		for _, worker := range workers {
+			err = notify.Send(c.Request.Context(), tx, notify.Notification{
+				UserID:       worker,
+				Type:         notify.TypeRework,
+				Title:        "Chore sent back for rework",
+				Body:         body,
+				AssignmentID: &assignmentID,
+			})
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to notify child", "details": err.Error()})
+				return
+			}
+		}
+
+		// Rejected work costs the children their auto-approval
+		trustRevoked := false
+		if verification.LoadPolicy(c.Request.Context(), tx).RevokeOnRejection {
+			for _, worker := range workers {
+				revoked, err := verification.Revoke(c.Request.Context(), tx, worker, fmt.Sprintf("work rejected on assignment %s", assignmentID))
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke trust", "details": err.Error()})
+					return
+				}
+				trustRevoked = trustRevoked || revoked
+			}
+		}
+
+		if version, err = assignmentVersion(c.Request.Context(), tx, assignmentID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+			return
+		}
+
 		if err = tx.Commit(c.Request.Context()); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
 			return
 		}
 
+		setETag(c, version)
 		c.JSON(http.StatusOK, gin.H{
-			"message":       "Assignment rejected - reset to pending",
-			"assignment_id": assignmentID,
-			"status":        "pending",
+			"message":          "Assignment sent back for rework",
+			"assignment_id":    assignmentID,
+			"status":           lifecycle.StatusNeedsRework,
+			"rejection_reason": *reason,
+			"redo_due_date":    redoDue,
+			"trust_revoked":    trustRevoked,
+			"version":          version,
 		})
 		return
 	}
 
-	// Approval - determine points
-	pointsAwarded := pointsOffered
-	if req.PointsAwarded != nil {
-		pointsAwarded = *req.PointsAwarded
+	// Approval - score the work and award the points
+	approved, err := approveAssignment(c.Request.Context(), tx, t, participants, req, verifierID)
+	if err != nil {
+		respondAssignmentError(c, err, "Failed to verify assignment")
+		return
+	}
+
+	if version, err = assignmentVersion(c.Request.Context(), tx, assignmentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+		return
+	}
+
+	// Commit transaction
+	if err = tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	setETag(c, version)
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Assignment verified successfully",
+		"assignment_id":      assignmentID,
+		"status":             lifecycle.StatusVerified,
+		"points_awarded":     approved.Points,
+		"points_breakdown":   approved.Lines,
+		"participant_points": approved.Shares,
+		"version":            version,
+	})
+}
+
+// verifyTarget is an assignment locked for review
+type verifyTarget struct {
+	ID            uuid.UUID
+	AssignedTo    *uuid.UUID
+	Status        string
+	PointsOffered int
+	BonusEligible bool
+	Early         bool
+	TimingPercent *int
+	ChoreName     string
+	IsTeam        bool
+	SplitMode     *string
+	Version       int
+}
+
+// lockVerifyTarget loads and locks an assignment for review
+func lockVerifyTarget(ctx context.Context, q database.Querier, assignmentID uuid.UUID) (verifyTarget, error) {
+	t := verifyTarget{ID: assignmentID}
+
+	// Timing was judged at completion; older completions only know the day
+	err := q.QueryRow(ctx, `
+		SELECT a.assigned_to, a.status, a.points_offered, COALESCE(c.bonus_eligible, false),
+			COALESCE(a.timing = 'early', a.completed_at::date < a.due_date::date, false), a.timing_percent,
+			c.name, a.is_team, a.split_mode, a.version
+		FROM assignments a
+		JOIN chores c ON a.chore_id = c.id
+		WHERE a.id = $1
+		FOR UPDATE OF a
+	`, assignmentID).Scan(&t.AssignedTo, &t.Status, &t.PointsOffered, &t.BonusEligible, &t.Early, &t.TimingPercent,
+		&t.ChoreName, &t.IsTeam, &t.SplitMode, &t.Version)
+	return t, err
+}
+
+// approval is the outcome of approving an assignment
+type approval struct {
+	Points int
+	Lines  []scoring.Line
+	Shares map[uuid.UUID]int // per participant, team chores only
+}
+
+// approveAssignment scores reviewed work, marks the assignment verified and
+// awards the points. participants is the team, if any. Problems with the
+// request come back as a *requestError.
+func approveAssignment(ctx context.Context, q database.Querier, t verifyTarget, participants []models.AssignmentParticipant, req VerifyAssignmentRequest, verifierID uuid.UUID) (approval, error) {
+	var approved approval
+
+	// Score the work into base credit plus bonus lines
+	input := scoring.Input{
+		PointsOffered:  t.PointsOffered,
+		PointsOverride: req.PointsAwarded,
+		TimingPercent:  t.TimingPercent,
+		Quality:        req.QualityRating,
+		BonusEligible:  t.BonusEligible,
+		Early:          t.Early,
+	}
+	if req.PartialCreditPercent != nil {
+		input.PartialPercent = *req.PartialCreditPercent
+	}
+	if req.EffortBonusPercent != nil {
+		input.EffortPercent = *req.EffortBonusPercent
+	}
+
+	// Team chores split every line across the participants
+	var weights []float64
+	if t.IsTeam {
+		mode := teams.SplitEven
+		if t.SplitMode != nil {
+			mode = *t.SplitMode
+		}
+		if len(req.ParticipantPoints) > 0 {
+			mode = teams.SplitManual
+		}
+		if mode == teams.SplitManual {
+			if req.PointsAwarded != nil || req.PartialCreditPercent != nil {
+				return approved, &requestError{message: "participant_points cannot be combined with points_awarded or partial_credit_percent"}
+			}
+			manualTotal := 0
+			for _, points := range req.ParticipantPoints {
+				manualTotal += points
+			}
+			input.PointsOverride = &manualTotal
+		}
+
+		var err error
+		weights, err = teams.Weights(mode, participants, req.ParticipantPoints)
+		if err != nil {
+			return approved, &requestError{message: "Invalid point split", fields: gin.H{"details": err.Error()}}
+		}
+	}
+
+	lines, err := scoring.Score(scoring.LoadRules(ctx, q), input)
+	if err != nil {
+		return approved, &requestError{message: "Invalid bonus", fields: gin.H{"details": err.Error()}}
+	}
+	approved.Lines = lines
+	approved.Points = scoring.Total(lines)
+
+	err = lifecycle.Apply(ctx, q, lifecycle.Change{
+		AssignmentID: t.ID,
+		Event:        lifecycle.EventVerified,
+		From:         t.Status,
+		To:           lifecycle.StatusVerified,
+		ActorID:      &verifierID,
+		Notes:        req.VerificationNotes,
+		PointsDelta:  approved.Points,
+		Metadata: map[string]interface{}{
+			"quality_rating":         req.QualityRating,
+			"partial_credit_percent": req.PartialCreditPercent,
+			"points_breakdown":       lines,
+		},
+	})
+	if err != nil {
+		return approved, err
 	}
 
 	// Update assignment
-	_, err = tx.Exec(c.Request.Context(), `
+	_, err = q.Exec(ctx, `
 		UPDATE assignments
-		SET status = 'verified',
-			verified_at = NOW(),
+		SET verified_at = NOW(),
 			verification_notes = $1,
 			points_earned = $2,
+			quality_rating = $3,
+			partial_credit_percent = $4,
 			updated_at = NOW()
-		WHERE id = $3
-	`, req.VerificationNotes, pointsAwarded, assignmentID)
+		WHERE id = $5
+	`, req.VerificationNotes, approved.Points, req.QualityRating, req.PartialCreditPercent, t.ID)
+	if err != nil {
+		return approved, fmt.Errorf("failed to verify assignment: %w", err)
+	}
 
+	err = reviewSubmission(ctx, q, t.ID, submissionReview{
+		Outcome:              outcomeVerified,
+		ReviewedBy:           &verifierID,
+		Notes:                req.VerificationNotes,
+		QualityRating:        req.QualityRating,
+		PartialCreditPercent: req.PartialCreditPercent,
+		PointsAwarded:        &approved.Points,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify assignment", "details": err.Error()})
+		return approved, fmt.Errorf("failed to record review: %w", err)
+	}
+
+	// Chores that were waiting on this one can start now
+	if err = notifyReleased(ctx, q, t.ID); err != nil {
+		return approved, fmt.Errorf("failed to notify dependent chores: %w", err)
+	}
+
+	// One point transaction per component, and per child on a team
+	if !t.IsTeam {
+		if err = scoring.Award(ctx, q, *t.AssignedTo, t.ID, lines); err != nil {
+			return approved, fmt.Errorf("failed to award points: %w", err)
+		}
+		return approved, nil
+	}
+
+	approved.Shares = map[uuid.UUID]int{}
+	for i, childLines := range teams.Allocate(lines, weights) {
+		userID := participants[i].UserID
+		if err = scoring.Award(ctx, q, userID, t.ID, childLines); err != nil {
+			return approved, fmt.Errorf("failed to award points: %w", err)
+		}
+		approved.Shares[userID] = scoring.Total(childLines)
+		_, err = q.Exec(ctx,
+			"UPDATE assignment_participants SET points_earned = $1 WHERE assignment_id = $2 AND user_id = $3",
+			approved.Shares[userID], t.ID, userID,
+		)
+		if err != nil {
+			return approved, fmt.Errorf("failed to record participant points: %w", err)
+		}
+	}
+	return approved, nil
+}
+
+// CloseAssignmentRequest is the request body for skipping or cancelling
+type CloseAssignmentRequest struct {
+	Notes *string `json:"notes,omitempty"`
+}
+
+// SkipAssignment excuses an assignment without points or penalty (parent only)
+func SkipAssignment(c *gin.Context) {
+	closeAssignment(c, lifecycle.StatusSkipped, lifecycle.EventSkipped)
+}
+
+// CancelAssignment withdraws an assignment (parent only)
+func CancelAssignment(c *gin.Context) {
+	closeAssignment(c, lifecycle.StatusCancelled, lifecycle.EventCancelled)
+}
+
+// closeAssignment moves an assignment into a terminal status
+func closeAssignment(c *gin.Context, to, event string) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
 		return
 	}
 
-	// Create point transaction
-	_, err = tx.Exec(c.Request.Context(), `
-		INSERT INTO point_transactions (
-			id, user_id, points, transaction_type, description,
-			related_assignment_id, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, NOW())
-	`, uuid.New(), *assignedTo, pointsAwarded, "chore_completion",
-		"Completed chore", assignmentID)
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can close assignments"})
+		return
+	}
+
+	userID, _ := middleware.GetAuthUserID(c)
 
+	assignmentID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create point transaction", "details": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID format"})
 		return
 	}
 
-	// Update user points
-	_, err = tx.Exec(c.Request.Context(), `
-		UPDATE users
-		SET total_points = total_points + $1,
-			available_points = available_points + $1,
-			weekly_points = weekly_points + $1,
-			lifetime_points_earned = lifetime_points_earned + $1,
-			updated_at = NOW()
-		WHERE id = $2
-	`, pointsAwarded, *assignedTo)
+	var req CloseAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Notes are optional
+		req = CloseAssignmentRequest{}
+	}
 
+	tx, err := db.Begin(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user points", "details": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var (
+		status  string
+		version int
+	)
+	err = tx.QueryRow(c.Request.Context(),
+		"SELECT status, version FROM assignments WHERE id = $1 FOR UPDATE",
+		assignmentID,
+	).Scan(&status, &version)
+
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+		}
+		return
+	}
+
+	if !checkIfMatch(c, version) {
+		return
+	}
+
+	if err = closeOne(c.Request.Context(), tx, assignmentID, status, to, event, userID, req.Notes); err != nil {
+		respondAssignmentError(c, err, "Failed to close assignment")
+		return
+	}
+
+	if version, err = assignmentVersion(c.Request.Context(), tx, assignmentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
 		return
 	}
 
-	// Commit transaction
 	if err = tx.Commit(c.Request.Context()); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
 		return
 	}
 
+	setETag(c, version)
 	c.JSON(http.StatusOK, gin.H{
-		"message":        "Assignment verified successfully",
-		"assignment_id":  assignmentID,
-		"status":         "verified",
-		"points_awarded": pointsAwarded,
+		"message":       fmt.Sprintf("Assignment %s", to),
+		"assignment_id": assignmentID,
+		"status":        to,
+		"version":       version,
+	})
+}
+
+// closeOne moves a locked assignment from status into a terminal status,
+// stops its timers and releases the chores waiting on it
+func closeOne(ctx context.Context, q database.Querier, assignmentID uuid.UUID, status, to, event string, userID uuid.UUID, notes *string) error {
+	err := lifecycle.Apply(ctx, q, lifecycle.Change{
+		AssignmentID: assignmentID,
+		Event:        event,
+		From:         status,
+		To:           to,
+		ActorID:      &userID,
+		Notes:        notes,
 	})
+	if err != nil {
+		return err
+	}
+
+	if _, err = timetrack.Stop(ctx, q, assignmentID, nil); err != nil {
+		return err
+	}
+
+	if err = trades.CancelFor(ctx, q, assignmentID, &userID, notes); err != nil {
+		return err
+	}
+
+	// A skipped or cancelled chore no longer holds up the ones after it
+	if err = notifyReleased(ctx, q, assignmentID); err != nil {
+		return fmt.Errorf("failed to notify dependent chores: %w", err)
+	}
+	return nil
+}
+
+// respondTransitionError maps lifecycle errors to HTTP responses
+func respondTransitionError(c *gin.Context, err error) {
+	var transitionErr *lifecycle.TransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		c.JSON(http.StatusConflict, gin.H{"error": transitionErr.Error()})
+	case errors.Is(err, lifecycle.ErrStatusChanged), errors.Is(err, lifecycle.ErrAssigneeChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": "Assignment was modified by another request, please retry"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update assignment status", "details": err.Error()})
+	}
+}
+
+// requestError is a problem with what the client asked for, as opposed to a
+// failure while doing it
+type requestError struct {
+	message string
+	fields  gin.H
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+// respondAssignmentError answers a request error with 400, a lifecycle
+// conflict with 409 and anything else with 500 and the given message
+func respondAssignmentError(c *gin.Context, err error, message string) {
+	var reqErr *requestError
+	var transitionErr *lifecycle.TransitionError
+	switch {
+	case errors.As(err, &reqErr):
+		body := gin.H{"error": reqErr.message}
+		for k, v := range reqErr.fields {
+			body[k] = v
+		}
+		c.JSON(http.StatusBadRequest, body)
+	case errors.As(err, &transitionErr), errors.Is(err, lifecycle.ErrStatusChanged), errors.Is(err, lifecycle.ErrAssigneeChanged):
+		respondTransitionError(c, err)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
+
+// parseRedoDueDate accepts a date, meaning the end of that day, or a timestamp
+func parseRedoDueDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	d, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(d.Year(), d.Month(), d.Day(), 23, 59, 59, 0, d.Location()), nil
+}
+
+// familyLocation returns the family's timezone, or UTC if it cannot be read
+func familyLocation(c *gin.Context, platformDB *database.PlatformDB) *time.Location {
+	familyID, ok := middleware.GetFamilyID(c)
+	if !ok {
+		return time.UTC
+	}
+	settings, err := platformDB.GetFamilySettings(c.Request.Context(), familyID)
+	if err != nil {
+		return time.UTC
+	}
+	return settings.Location()
 }
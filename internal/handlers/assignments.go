@@ -1,12 +1,19 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/JunoAX/housepoints-go/internal/database"
 	"github.com/JunoAX/housepoints-go/internal/middleware"
 	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/sequence"
+	"github.com/JunoAX/housepoints-go/internal/storage"
+	"github.com/JunoAX/housepoints-go/internal/teams"
+	"github.com/JunoAX/housepoints-go/internal/timetrack"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -24,6 +31,7 @@ func ListAssignments(c *gin.Context) {
 	status := c.Query("status")                  // Filter by status
 	startDate := c.Query("start_date")           // Filter by due date range
 	endDate := c.Query("end_date")               // Filter by due date range
+	sequenceState := c.Query("sequence")         // blocked or ready
 
 	// Build query
 	query := `
@@ -31,7 +39,8 @@ func ListAssignments(c *gin.Context) {
 			a.id, a.chore_id, a.assigned_to, a.assigned_by,
 			a.status, a.points_offered, COALESCE(a.points_earned, 0) as points_earned,
 			a.due_date, a.created_at, a.updated_at, a.completed_at, a.verified_at,
-			a.completion_notes, a.verification_notes,
+			a.completion_notes, a.verification_notes, a.version,
+			a.is_team, a.split_mode,
 			c.name as chore_name, c.description as chore_description,
 			c.category, c.difficulty, c.estimated_minutes, c.base_points,
 			c.requires_verification, c.requires_photo, c.icon,
@@ -54,7 +63,9 @@ func ListAssignments(c *gin.Context) {
 			return
 		}
 		paramCount++
-		query += fmt.Sprintf(" AND a.assigned_to = $%d", paramCount)
+		query += fmt.Sprintf(` AND (a.assigned_to = $%d OR EXISTS (
+			SELECT 1 FROM assignment_participants p WHERE p.assignment_id = a.id AND p.user_id = $%d
+		))`, paramCount, paramCount)
 		params = append(params, userID)
 	}
 
@@ -64,7 +75,7 @@ func ListAssignments(c *gin.Context) {
 		params = append(params, status)
 	} else {
 		// Default: show active assignments
-		query += ` AND a.status IN ('pending', 'in_progress', 'pending_verification', 'open')`
+		query += ` AND a.status IN ('pending', 'in_progress', 'overdue', 'needs_rework', 'pending_verification', 'open')`
 	}
 
 	if startDate != "" {
@@ -79,6 +90,25 @@ func ListAssignments(c *gin.Context) {
 		params = append(params, endDate)
 	}
 
+	if sequenceState != "" {
+		blocked := fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM assignment_dependencies d
+			JOIN assignments p ON d.prerequisite_id = p.id
+			WHERE d.assignment_id = a.id AND NOT (p.status = ANY($%d))
+		)`, paramCount+1)
+		switch sequenceState {
+		case sequence.StateBlocked:
+			query += " AND " + blocked
+		case sequence.StateReady:
+			query += ` AND EXISTS (SELECT 1 FROM assignment_dependencies d WHERE d.assignment_id = a.id) AND NOT ` + blocked
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sequence must be blocked or ready"})
+			return
+		}
+		paramCount++
+		params = append(params, sequence.ReleasedStatuses)
+	}
+
 	query += ` ORDER BY a.due_date ASC NULLS LAST, a.created_at DESC LIMIT 100`
 
 	rows, err := db.Query(c.Request.Context(), query, params...)
@@ -99,6 +129,9 @@ func ListAssignments(c *gin.Context) {
 			createdAt                              time.Time
 			updatedAt                              *time.Time
 			completionNotes, verificationNotes     *string
+			version                                int
+			isTeam                                 bool
+			splitMode                              *string
 			choreName                              string
 			choreDescription                       *string
 			category, difficulty, icon             string
@@ -112,7 +145,8 @@ func ListAssignments(c *gin.Context) {
 			&id, &choreID, &assignedTo, &assignedBy,
 			&status, &pointsOffered, &pointsEarned,
 			&dueDate, &createdAt, &updatedAt, &completedAt, &verifiedAt,
-			&completionNotes, &verificationNotes,
+			&completionNotes, &verificationNotes, &version,
+			&isTeam, &splitMode,
 			&choreName, &choreDescription, &category, &difficulty,
 			&estimatedMinutes, &basePoints, &requiresVerification, &requiresPhoto, &icon,
 			&assignedUserName, &assignedUsername, &assignedUserColor,
@@ -184,7 +218,10 @@ func ListAssignments(c *gin.Context) {
 			VerifiedAt:        verifiedAtStr,
 			CompletionNotes:   completionNotes,
 			VerificationNotes: verificationNotes,
+			Version:           version,
 			IsBonus:           isBonus,
+			IsTeam:            isTeam,
+			SplitMode:         splitMode,
 			Chore:             chore,
 			AssignedUser:      assignedUser,
 		}
@@ -192,167 +229,222 @@ func ListAssignments(c *gin.Context) {
 		assignments = append(assignments, assignment)
 	}
 
+	if err := attachParticipants(c.Request.Context(), db, assignments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query participants", "details": err.Error()})
+		return
+	}
+
+	if err := attachSequence(c.Request.Context(), db, assignments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query prerequisites", "details": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"assignments": assignments,
 		"count":       len(assignments),
 	})
 }
 
-// GetAssignment returns details for a specific assignment by ID
-func GetAssignment(c *gin.Context) {
-	db, ok := middleware.GetFamilyDB(c)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
-		return
-	}
+// GetAssignment returns details for a specific assignment by ID, including
+// signed URLs for any before/after photos
+func GetAssignment(store storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
 
-	assignmentIDParam := c.Param("id")
-	assignmentID, err := uuid.Parse(assignmentIDParam)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID format"})
-		return
-	}
+		assignmentIDParam := c.Param("id")
+		assignmentID, err := uuid.Parse(assignmentIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID format"})
+			return
+		}
 
-	query := `
-		SELECT
-			a.id, a.chore_id, a.assigned_to, a.assigned_by,
-			a.status, a.points_offered, COALESCE(a.points_earned, 0) as points_earned,
-			a.due_date, a.created_at, a.updated_at, a.completed_at, a.verified_at,
-			a.completion_notes, a.verification_notes,
-			c.name as chore_name, c.description as chore_description,
-			c.category, c.difficulty, c.estimated_minutes, c.base_points,
-			c.requires_verification, c.requires_photo, c.icon,
-			u.display_name as assigned_user_name, u.username as assigned_username,
-			u.color_theme as assigned_user_color,
-			ub.display_name as assigned_by_name, ub.username as assigned_by_username,
-			ub.color_theme as assigned_by_color
-		FROM assignments a
-		JOIN chores c ON a.chore_id = c.id
-		LEFT JOIN users u ON a.assigned_to = u.id
-		LEFT JOIN users ub ON a.assigned_by = ub.id
-		WHERE a.id = $1
-	`
+		query := `
+			SELECT
+				a.id, a.chore_id, a.assigned_to, a.assigned_by,
+				a.status, a.points_offered, COALESCE(a.points_earned, 0) as points_earned,
+				a.due_date, a.created_at, a.updated_at, a.completed_at, a.verified_at,
+				a.completion_notes, a.verification_notes, a.version,
+				a.is_team, a.split_mode,
+				c.name as chore_name, c.description as chore_description,
+				c.category, c.difficulty, c.estimated_minutes, c.base_points,
+				c.requires_verification, c.requires_photo, c.icon,
+				u.display_name as assigned_user_name, u.username as assigned_username,
+				u.color_theme as assigned_user_color,
+				ub.display_name as assigned_by_name, ub.username as assigned_by_username,
+				ub.color_theme as assigned_by_color
+			FROM assignments a
+			JOIN chores c ON a.chore_id = c.id
+			LEFT JOIN users u ON a.assigned_to = u.id
+			LEFT JOIN users ub ON a.assigned_by = ub.id
+			WHERE a.id = $1
+		`
 
-	var (
-		id, choreID                                uuid.UUID
-		assignedTo, assignedBy                     *uuid.UUID
-		status                                      string
-		pointsOffered, pointsEarned                int
-		dueDate, completedAt, verifiedAt           *time.Time
-		createdAt                                  time.Time
-		updatedAt                                  *time.Time
-		completionNotes, verificationNotes         *string
-		choreName                                  string
-		choreDescription                           *string
-		category, difficulty, icon                 string
-		estimatedMinutes, basePoints               *int
-		requiresVerification, requiresPhoto        bool
-		assignedUserName, assignedUsername         *string
-		assignedUserColor                          *string
-		assignedByName, assignedByUsername         *string
-		assignedByColor                            *string
-	)
-
-	err = db.QueryRow(c.Request.Context(), query, assignmentID).Scan(
-		&id, &choreID, &assignedTo, &assignedBy,
-		&status, &pointsOffered, &pointsEarned,
-		&dueDate, &createdAt, &updatedAt, &completedAt, &verifiedAt,
-		&completionNotes, &verificationNotes,
-		&choreName, &choreDescription, &category, &difficulty,
-		&estimatedMinutes, &basePoints, &requiresVerification, &requiresPhoto, &icon,
-		&assignedUserName, &assignedUsername, &assignedUserColor,
-		&assignedByName, &assignedByUsername, &assignedByColor,
-	)
+		var (
+			id, choreID                                uuid.UUID
+			assignedTo, assignedBy                     *uuid.UUID
+			status                                      string
+			pointsOffered, pointsEarned                int
+			dueDate, completedAt, verifiedAt           *time.Time
+			createdAt                                  time.Time
+			updatedAt                                  *time.Time
+			completionNotes, verificationNotes         *string
+			version                                    int
+			isTeam                                     bool
+			splitMode                                  *string
+			choreName                                  string
+			choreDescription                           *string
+			category, difficulty, icon                 string
+			estimatedMinutes, basePoints               *int
+			requiresVerification, requiresPhoto        bool
+			assignedUserName, assignedUsername         *string
+			assignedUserColor                          *string
+			assignedByName, assignedByUsername         *string
+			assignedByColor                            *string
+		)
 
-	if err != nil {
-		if err.Error() == "no rows in result set" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+		err = db.QueryRow(c.Request.Context(), query, assignmentID).Scan(
+			&id, &choreID, &assignedTo, &assignedBy,
+			&status, &pointsOffered, &pointsEarned,
+			&dueDate, &createdAt, &updatedAt, &completedAt, &verifiedAt,
+			&completionNotes, &verificationNotes, &version,
+			&isTeam, &splitMode,
+			&choreName, &choreDescription, &category, &difficulty,
+			&estimatedMinutes, &basePoints, &requiresVerification, &requiresPhoto, &icon,
+			&assignedUserName, &assignedUsername, &assignedUserColor,
+			&assignedByName, &assignedByUsername, &assignedByColor,
+		)
+
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+			}
+			return
 		}
-		return
-	}
 
-	// Build chore info
-	chore := models.AssignmentChoreInfo{
-		ID:                   choreID,
-		Name:                 choreName,
-		Description:          choreDescription,
-		Category:             category,
-		Difficulty:           difficulty,
-		EstimatedMinutes:     estimatedMinutes,
-		RequiresVerification: requiresVerification,
-		RequiresPhoto:        requiresPhoto,
-		Icon:                 icon,
-		BasePoints:           *basePoints,
-	}
+		// Build chore info
+		chore := models.AssignmentChoreInfo{
+			ID:                   choreID,
+			Name:                 choreName,
+			Description:          choreDescription,
+			Category:             category,
+			Difficulty:           difficulty,
+			EstimatedMinutes:     estimatedMinutes,
+			RequiresVerification: requiresVerification,
+			RequiresPhoto:        requiresPhoto,
+			Icon:                 icon,
+			BasePoints:           *basePoints,
+		}
 
-	// Build assigned user info
-	var assignedUser *models.AssignmentUserInfo
-	if assignedTo != nil && assignedUserName != nil {
-		assignedUser = &models.AssignmentUserInfo{
-			ID:          *assignedTo,
-			Username:    *assignedUsername,
-			DisplayName: *assignedUserName,
-			ColorTheme:  *assignedUserColor,
+		// Build assigned user info
+		var assignedUser *models.AssignmentUserInfo
+		if assignedTo != nil && assignedUserName != nil {
+			assignedUser = &models.AssignmentUserInfo{
+				ID:          *assignedTo,
+				Username:    *assignedUsername,
+				DisplayName: *assignedUserName,
+				ColorTheme:  *assignedUserColor,
+			}
 		}
-	}
 
-	// Build assigned by user info
-	var assignedByUser *models.AssignmentUserInfo
-	if assignedBy != nil && assignedByName != nil {
-		assignedByUser = &models.AssignmentUserInfo{
-			ID:          *assignedBy,
-			Username:    *assignedByUsername,
-			DisplayName: *assignedByName,
-			ColorTheme:  *assignedByColor,
+		// Build assigned by user info
+		var assignedByUser *models.AssignmentUserInfo
+		if assignedBy != nil && assignedByName != nil {
+			assignedByUser = &models.AssignmentUserInfo{
+				ID:          *assignedBy,
+				Username:    *assignedByUsername,
+				DisplayName: *assignedByName,
+				ColorTheme:  *assignedByColor,
+			}
 		}
-	}
 
-	// Determine if bonus
-	isBonus := assignedTo == nil || status == "open"
+		// Determine if bonus
+		isBonus := assignedTo == nil || status == "open"
 
-	// Format dates
-	var dueDateStr, completedAtStr, verifiedAtStr, updatedAtStr *string
-	if dueDate != nil {
-		str := dueDate.Format("2006-01-02")
-		dueDateStr = &str
-	}
-	if completedAt != nil {
-		str := completedAt.Format(time.RFC3339)
-		completedAtStr = &str
-	}
-	if verifiedAt != nil {
-		str := verifiedAt.Format(time.RFC3339)
-		verifiedAtStr = &str
-	}
-	if updatedAt != nil {
-		str := updatedAt.Format(time.RFC3339)
-		updatedAtStr = &str
-	}
+		// Format dates
+		var dueDateStr, completedAtStr, verifiedAtStr, updatedAtStr *string
+		if dueDate != nil {
+			str := dueDate.Format("2006-01-02")
+			dueDateStr = &str
+		}
+		if completedAt != nil {
+			str := completedAt.Format(time.RFC3339)
+			completedAtStr = &str
+		}
+		if verifiedAt != nil {
+			str := verifiedAt.Format(time.RFC3339)
+			verifiedAtStr = &str
+		}
+		if updatedAt != nil {
+			str := updatedAt.Format(time.RFC3339)
+			updatedAtStr = &str
+		}
 
-	assignment := models.AssignmentDetailResponse{
-		ID:                id,
-		ChoreID:           choreID,
-		AssignedTo:        assignedTo,
-		AssignedBy:        assignedBy,
-		Status:            status,
-		PointsOffered:     pointsOffered,
-		PointsEarned:      pointsEarned,
-		DueDate:           dueDateStr,
-		CreatedAt:         createdAt.Format(time.RFC3339),
-		UpdatedAt:         updatedAtStr,
-		CompletedAt:       completedAtStr,
-		VerifiedAt:        verifiedAtStr,
-		CompletionNotes:   completionNotes,
-		VerificationNotes: verificationNotes,
-		IsBonus:           isBonus,
-		Chore:             chore,
-		AssignedUser:      assignedUser,
-		AssignedByUser:    assignedByUser,
-	}
+		assignment := models.AssignmentDetailResponse{
+			ID:                id,
+			ChoreID:           choreID,
+			AssignedTo:        assignedTo,
+			AssignedBy:        assignedBy,
+			Status:            status,
+			PointsOffered:     pointsOffered,
+			PointsEarned:      pointsEarned,
+			DueDate:           dueDateStr,
+			CreatedAt:         createdAt.Format(time.RFC3339),
+			UpdatedAt:         updatedAtStr,
+			CompletedAt:       completedAtStr,
+			VerifiedAt:        verifiedAtStr,
+			CompletionNotes:   completionNotes,
+			VerificationNotes: verificationNotes,
+			Version:           version,
+			IsBonus:           isBonus,
+			IsTeam:            isTeam,
+			SplitMode:         splitMode,
+			Chore:             chore,
+			AssignedUser:      assignedUser,
+			AssignedByUser:    assignedByUser,
+		}
+
+		if isTeam {
+			assignment.Participants, err = teams.Load(c.Request.Context(), db, id)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query participants", "details": err.Error()})
+				return
+			}
+		}
+
+		assignment.Prerequisites, err = sequence.Load(c.Request.Context(), db, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query prerequisites", "details": err.Error()})
+			return
+		}
+		assignment.Sequence = sequence.State(assignment.Prerequisites)
+
+		assignment.Time, err = timetrack.Load(c.Request.Context(), db, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query time segments", "details": err.Error()})
+			return
+		}
+
+		assignment.Checklist, err = loadChecklist(c.Request.Context(), db, store, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query checklist", "details": err.Error()})
+			return
+		}
+
+		assignment.Photos, err = loadPhotos(c.Request.Context(), db, store, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query photos", "details": err.Error()})
+			return
+		}
 
-	c.JSON(http.StatusOK, assignment)
+		setETag(c, version)
+		c.JSON(http.StatusOK, assignment)
+	}
 }
 
 // GetMyAssignments returns assignments for the current user (both assigned and open/bonus tasks)
@@ -379,7 +471,8 @@ func GetMyAssignments(c *gin.Context) {
 			a.id, a.chore_id, a.assigned_to, a.assigned_by,
 			a.status, a.points_offered, COALESCE(a.points_earned, 0) as points_earned,
 			a.due_date, a.created_at, a.updated_at, a.completed_at, a.verified_at,
-			a.completion_notes, a.verification_notes,
+			a.completion_notes, a.verification_notes, a.version,
+			a.is_team, a.split_mode,
 			c.name as chore_name, c.description as chore_description,
 			c.category, c.difficulty, c.estimated_minutes, c.base_points,
 			c.requires_verification, c.requires_photo, c.icon,
@@ -388,7 +481,8 @@ func GetMyAssignments(c *gin.Context) {
 		FROM assignments a
 		JOIN chores c ON a.chore_id = c.id
 		LEFT JOIN users u ON a.assigned_to = u.id
-		WHERE (a.assigned_to = $1 OR a.assigned_to IS NULL OR a.status = 'open')
+		WHERE (a.assigned_to = $1 OR a.assigned_to IS NULL OR a.status = 'open'
+			OR EXISTS (SELECT 1 FROM assignment_participants p WHERE p.assignment_id = a.id AND p.user_id = $1))
 	`
 
 	params := []interface{}{userID}
@@ -401,7 +495,7 @@ func GetMyAssignments(c *gin.Context) {
 		params = append(params, status)
 	} else {
 		// Default: show active assignments
-		query += ` AND a.status IN ('pending', 'in_progress', 'pending_verification', 'open')`
+		query += ` AND a.status IN ('pending', 'in_progress', 'overdue', 'needs_rework', 'pending_verification', 'open')`
 	}
 
 	query += ` ORDER BY a.due_date ASC NULLS LAST, a.created_at DESC LIMIT 100`
@@ -424,6 +518,9 @@ func GetMyAssignments(c *gin.Context) {
 			createdAt                              time.Time
 			updatedAt                              *time.Time
 			completionNotes, verificationNotes     *string
+			version                                int
+			isTeam                                 bool
+			splitMode                              *string
 			choreName                              string
 			choreDescription                       *string
 			category, difficulty, icon             string
@@ -437,7 +534,8 @@ func GetMyAssignments(c *gin.Context) {
 			&id, &choreID, &assignedTo, &assignedBy,
 			&status, &pointsOffered, &pointsEarned,
 			&dueDate, &createdAt, &updatedAt, &completedAt, &verifiedAt,
-			&completionNotes, &verificationNotes,
+			&completionNotes, &verificationNotes, &version,
+			&isTeam, &splitMode,
 			&choreName, &choreDescription, &category, &difficulty,
 			&estimatedMinutes, &basePoints, &requiresVerification, &requiresPhoto, &icon,
 			&assignedUserName, &assignedUsername, &assignedUserColor,
@@ -509,7 +607,10 @@ func GetMyAssignments(c *gin.Context) {
 			VerifiedAt:        verifiedAtStr,
 			CompletionNotes:   completionNotes,
 			VerificationNotes: verificationNotes,
+			Version:           version,
 			IsBonus:           isBonus,
+			IsTeam:            isTeam,
+			SplitMode:         splitMode,
 			Chore:             chore,
 			AssignedUser:      assignedUser,
 		}
@@ -517,8 +618,112 @@ func GetMyAssignments(c *gin.Context) {
 		assignments = append(assignments, assignment)
 	}
 
+	if err := attachParticipants(c.Request.Context(), db, assignments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query participants", "details": err.Error()})
+		return
+	}
+
+	if err := attachSequence(c.Request.Context(), db, assignments); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query prerequisites", "details": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"assignments": assignments,
 		"count":       len(assignments),
 	})
 }
+
+// attachParticipants fills in the members of any team assignments in a list
+func attachParticipants(ctx context.Context, q database.Querier, assignments []models.AssignmentListResponse) error {
+	ids := []uuid.UUID{}
+	for _, a := range assignments {
+		if a.IsTeam {
+			ids = append(ids, a.ID)
+		}
+	}
+
+	byAssignment, err := teams.LoadMany(ctx, q, ids)
+	if err != nil {
+		return err
+	}
+	for i := range assignments {
+		if assignments[i].IsTeam {
+			assignments[i].Participants = byAssignment[assignments[i].ID]
+		}
+	}
+	return nil
+}
+
+// GetAssignmentHistory returns every recorded lifecycle event for an assignment
+func GetAssignmentHistory(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	assignmentIDParam := c.Param("id")
+	assignmentID, err := uuid.Parse(assignmentIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID format"})
+		return
+	}
+
+	var status string
+	err = db.QueryRow(c.Request.Context(), "SELECT status FROM assignments WHERE id = $1", assignmentID).Scan(&status)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+		}
+		return
+	}
+
+	query := `
+		SELECT
+			e.id, e.event_type, e.from_status, e.to_status,
+			e.actor_id, u.display_name, e.notes, e.points_delta,
+			e.metadata, e.created_at
+		FROM assignment_events e
+		LEFT JOIN users u ON e.actor_id = u.id
+		WHERE e.assignment_id = $1
+		ORDER BY e.created_at ASC, e.id ASC
+	`
+
+	rows, err := db.Query(c.Request.Context(), query, assignmentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment history", "details": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	events := []models.AssignmentEvent{}
+	for rows.Next() {
+		var (
+			event        models.AssignmentEvent
+			metadataJSON []byte
+		)
+		err := rows.Scan(
+			&event.ID, &event.EventType, &event.FromStatus, &event.ToStatus,
+			&event.ActorID, &event.ActorName, &event.Notes, &event.PointsDelta,
+			&metadataJSON, &event.CreatedAt,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse assignment event", "details": err.Error()})
+			return
+		}
+		if len(metadataJSON) > 0 {
+			json.Unmarshal(metadataJSON, &event.Metadata)
+		}
+		events = append(events, event)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"assignment_id": assignmentID,
+		"status":        status,
+		"events":        events,
+		"count":         len(events),
+	})
+}
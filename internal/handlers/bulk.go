@@ -0,0 +1,384 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
+	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/schedule"
+	"github.com/JunoAX/housepoints-go/internal/teams"
+	"github.com/JunoAX/housepoints-go/internal/timetrack"
+	"github.com/JunoAX/housepoints-go/internal/trades"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// errVersionMismatch is an item whose assignment changed since the client
+// read the version it sent
+var errVersionMismatch = errors.New("assignment version mismatch")
+
+// reassignableStatuses are the statuses a parent can hand to another child.
+// Work in progress or waiting for review stays with whoever did it.
+var reassignableStatuses = map[string]bool{
+	lifecycle.StatusOpen:        true,
+	lifecycle.StatusPending:     true,
+	lifecycle.StatusNeedsRework: true,
+	lifecycle.StatusOverdue:     true,
+}
+
+// BulkCreateAssignments creates many assignments in one request (parent only)
+func BulkCreateAssignments(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.BulkCreateRequest
+		if !bindBulk(c, &req) {
+			return
+		}
+
+		userID, _ := middleware.GetAuthUserID(c)
+		loc := familyLocation(c, platformDB)
+		runBulk(c, req.Mode, req.DryRun, len(req.Items), func(ctx context.Context, tx pgx.Tx, i int) (models.BulkItemResult, error) {
+			var result models.BulkItemResult
+
+			created, err := createAssignment(ctx, tx, req.Items[i], userID, loc)
+			if err != nil {
+				return result, err
+			}
+
+			result.AssignmentID = &created.ID
+			result.Status = created.Status
+			result.AssignedTo = created.AssignedTo
+			result.Warnings = created.Warnings
+			result.Version, err = assignmentVersion(ctx, tx, created.ID)
+			return result, err
+		})
+	}
+}
+
+// BulkReassignAssignments hands many assignments to other children in one
+// request (parent only). Open assignments become pending; live trades on
+// them are cancelled. Items handed to a child who is away on the due date
+// carry a warning, as when creating.
+func BulkReassignAssignments(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.BulkReassignRequest
+		if !bindBulk(c, &req) {
+			return
+		}
+
+		userID, _ := middleware.GetAuthUserID(c)
+		loc := familyLocation(c, platformDB)
+		runBulk(c, req.Mode, req.DryRun, len(req.Items), func(ctx context.Context, tx pgx.Tx, i int) (models.BulkItemResult, error) {
+			item := req.Items[i]
+			result := models.BulkItemResult{AssignmentID: &item.AssignmentID}
+
+			var (
+				assignedTo *uuid.UUID
+				status     string
+				isTeam     bool
+				version    int
+				dueDate    *time.Time
+			)
+			err := tx.QueryRow(ctx,
+				"SELECT assigned_to, status, is_team, version, due_date FROM assignments WHERE id = $1 FOR UPDATE",
+				item.AssignmentID,
+			).Scan(&assignedTo, &status, &isTeam, &version, &dueDate)
+			if err != nil {
+				return result, err
+			}
+			if item.Version != nil && *item.Version != version {
+				return result, errVersionMismatch
+			}
+			if isTeam {
+				return result, &requestError{message: "Team assignments cannot be reassigned"}
+			}
+			if !reassignableStatuses[status] {
+				return result, &requestError{message: fmt.Sprintf("Cannot reassign assignment with status: %s", status)}
+			}
+			if assignedTo != nil && *assignedTo == item.AssignedTo {
+				return result, &requestError{message: "Assignment is already assigned to that user"}
+			}
+
+			var username, name string
+			err = tx.QueryRow(ctx,
+				"SELECT username, display_name FROM users WHERE id = $1 AND is_active = true",
+				item.AssignedTo,
+			).Scan(&username, &name)
+			if err != nil {
+				if err.Error() == "no rows in result set" {
+					return result, &requestError{message: "Assigned user not found"}
+				}
+				return result, err
+			}
+
+			// Warn when handing it to a child who is away on the due date
+			if dueDate != nil {
+				day := dueDate.In(loc)
+				presence, err := schedule.LoadPresence(ctx, tx, day)
+				if err != nil {
+					return result, err
+				}
+				if !presence.IsPresent(username, name) {
+					warning, err := absenceWarning(ctx, tx, username, name, day)
+					if err != nil {
+						return result, err
+					}
+					result.Warnings = append(result.Warnings, warning)
+				}
+			}
+
+			if err = trades.CancelFor(ctx, tx, item.AssignmentID, &userID, item.Notes); err != nil {
+				return result, err
+			}
+			if _, err = timetrack.Stop(ctx, tx, item.AssignmentID, nil); err != nil {
+				return result, err
+			}
+
+			change := lifecycle.Change{
+				AssignmentID: item.AssignmentID,
+				From:         status,
+				ActorID:      &userID,
+				Notes:        item.Notes,
+			}
+			result.Status = status
+			if status == lifecycle.StatusOpen {
+				// Nobody had it yet, so it becomes pending like a claimed chore
+				change.Event = lifecycle.EventReassigned
+				change.To = lifecycle.StatusPending
+				change.Metadata = map[string]interface{}{"from_user_id": nil, "to_user_id": item.AssignedTo}
+				if err = lifecycle.Apply(ctx, tx, change); err != nil {
+					return result, err
+				}
+				_, err = tx.Exec(ctx, `
+					UPDATE assignments
+					SET assigned_to = $1,
+						updated_at = NOW()
+					WHERE id = $2
+				`, item.AssignedTo, item.AssignmentID)
+				if err != nil {
+					return result, err
+				}
+				result.Status = lifecycle.StatusPending
+			} else if err = lifecycle.Reassign(ctx, tx, change, assignedTo, item.AssignedTo); err != nil {
+				return result, err
+			}
+
+			result.AssignedTo = &item.AssignedTo
+			result.Version, err = assignmentVersion(ctx, tx, item.AssignmentID)
+			return result, err
+		})
+	}
+}
+
+// BulkCancelAssignments withdraws many assignments in one request (parent
+// only)
+func BulkCancelAssignments(c *gin.Context) {
+	var req models.BulkCancelRequest
+	if !bindBulk(c, &req) {
+		return
+	}
+
+	userID, _ := middleware.GetAuthUserID(c)
+	runBulk(c, req.Mode, req.DryRun, len(req.Items), func(ctx context.Context, tx pgx.Tx, i int) (models.BulkItemResult, error) {
+		item := req.Items[i]
+		result := models.BulkItemResult{AssignmentID: &item.AssignmentID}
+
+		var (
+			status  string
+			version int
+		)
+		err := tx.QueryRow(ctx,
+			"SELECT status, version FROM assignments WHERE id = $1 FOR UPDATE",
+			item.AssignmentID,
+		).Scan(&status, &version)
+		if err != nil {
+			return result, err
+		}
+		if item.Version != nil && *item.Version != version {
+			return result, errVersionMismatch
+		}
+
+		err = closeOne(ctx, tx, item.AssignmentID, status, lifecycle.StatusCancelled, lifecycle.EventCancelled, userID, item.Notes)
+		if err != nil {
+			return result, err
+		}
+
+		result.Status = lifecycle.StatusCancelled
+		result.Version, err = assignmentVersion(ctx, tx, item.AssignmentID)
+		return result, err
+	})
+}
+
+// BulkVerifyAssignments approves many completed assignments in one request
+// (parent only), scoring each the same way as the verify endpoint
+func BulkVerifyAssignments(c *gin.Context) {
+	var req models.BulkVerifyRequest
+	if !bindBulk(c, &req) {
+		return
+	}
+
+	verifierID, _ := middleware.GetAuthUserID(c)
+	runBulk(c, req.Mode, req.DryRun, len(req.Items), func(ctx context.Context, tx pgx.Tx, i int) (models.BulkItemResult, error) {
+		item := req.Items[i]
+		result := models.BulkItemResult{AssignmentID: &item.AssignmentID}
+
+		t, err := lockVerifyTarget(ctx, tx, item.AssignmentID)
+		if err != nil {
+			return result, err
+		}
+		if item.Version != nil && *item.Version != t.Version {
+			return result, errVersionMismatch
+		}
+		if t.Status != lifecycle.StatusCompleted && t.Status != lifecycle.StatusPendingVerification {
+			return result, &requestError{message: fmt.Sprintf("Cannot verify assignment with status: %s", t.Status)}
+		}
+		if t.AssignedTo == nil {
+			return result, &requestError{message: "Assignment has no assignee"}
+		}
+
+		var participants []models.AssignmentParticipant
+		if t.IsTeam {
+			if participants, err = teams.Load(ctx, tx, t.ID); err != nil {
+				return result, err
+			}
+		}
+
+		approved, err := approveAssignment(ctx, tx, t, participants, VerifyAssignmentRequest{
+			Approved:             true,
+			PointsAwarded:        item.PointsAwarded,
+			QualityRating:        item.QualityRating,
+			PartialCreditPercent: item.PartialCreditPercent,
+			EffortBonusPercent:   item.EffortBonusPercent,
+			VerificationNotes:    item.VerificationNotes,
+		}, verifierID)
+		if err != nil {
+			return result, err
+		}
+
+		result.Status = lifecycle.StatusVerified
+		result.AssignedTo = t.AssignedTo
+		result.PointsAwarded = &approved.Points
+		result.Version, err = assignmentVersion(ctx, tx, t.ID)
+		return result, err
+	})
+}
+
+// bindBulk checks the caller is a parent and reads the request body
+func bindBulk(c *gin.Context, req interface{}) bool {
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can make bulk changes"})
+		return false
+	}
+
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return false
+	}
+	return true
+}
+
+// runBulk applies each item in its own savepoint of one transaction, so a
+// failed item leaves the others untouched. The transaction is committed
+// unless this is a dry run, or an all_or_nothing request had a failure; a dry
+// run reports exactly what would have changed.
+func runBulk(c *gin.Context, mode string, dryRun bool, n int, apply func(ctx context.Context, tx pgx.Tx, i int) (models.BulkItemResult, error)) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	if mode == "" {
+		mode = models.BulkAllOrNothing
+	}
+	ctx := c.Request.Context()
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	response := models.BulkResponse{
+		Mode:    mode,
+		DryRun:  dryRun,
+		Results: make([]models.BulkItemResult, 0, n),
+	}
+
+	for i := 0; i < n; i++ {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start savepoint", "details": err.Error()})
+			return
+		}
+
+		result, err := apply(ctx, savepoint, i)
+		if err == nil {
+			err = savepoint.Commit(ctx)
+		}
+		result.Index = i
+		if err != nil {
+			if rbErr := savepoint.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to roll back item", "details": rbErr.Error()})
+				return
+			}
+			result = models.BulkItemResult{
+				Index:        i,
+				AssignmentID: result.AssignmentID,
+				Error:        bulkErrorMessage(err),
+			}
+			response.Failed++
+		} else {
+			result.Success = true
+			response.Succeeded++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	if mode == models.BulkAllOrNothing && response.Failed > 0 {
+		response.Error = "No changes were saved because some items failed"
+		c.JSON(http.StatusConflict, response)
+		return
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	response.Committed = true
+	c.JSON(http.StatusOK, response)
+}
+
+// bulkErrorMessage describes why an item failed
+func bulkErrorMessage(err error) string {
+	var reqErr *requestError
+	var transitionErr *lifecycle.TransitionError
+	switch {
+	case errors.As(err, &reqErr):
+		return reqErr.message
+	case errors.As(err, &transitionErr):
+		return transitionErr.Error()
+	case errors.Is(err, errVersionMismatch):
+		return "Assignment has changed since it was fetched"
+	case errors.Is(err, lifecycle.ErrStatusChanged), errors.Is(err, lifecycle.ErrAssigneeChanged):
+		return "Assignment was modified by another request, please retry"
+	case err.Error() == "no rows in result set":
+		return "Assignment not found"
+	default:
+		return err.Error()
+	}
+}
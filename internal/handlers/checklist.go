@@ -0,0 +1,426 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/checklist"
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
+	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetChoreChecklist returns a chore's checklist
+func GetChoreChecklist(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	choreID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chore ID"})
+		return
+	}
+
+	items, err := checklist.LoadChore(c.Request.Context(), db, choreID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query checklist", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chore_id": choreID,
+		"items":    items,
+		"count":    len(items),
+	})
+}
+
+// UpdateChoreChecklist replaces a chore's checklist (parent only). Existing
+// assignments keep the checklist they were created with.
+func UpdateChoreChecklist(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can edit checklists"})
+		return
+	}
+
+	choreID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chore ID"})
+		return
+	}
+
+	var req models.ChoreChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	tx, err := db.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var exists bool
+	err = tx.QueryRow(c.Request.Context(), "SELECT EXISTS(SELECT 1 FROM chores WHERE id = $1 AND is_active = true)", choreID).Scan(&exists)
+	if err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chore not found"})
+		return
+	}
+
+	if err = checklist.ReplaceChore(c.Request.Context(), tx, choreID, req.Items); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update checklist", "details": err.Error()})
+		return
+	}
+
+	items, err := checklist.LoadChore(c.Request.Context(), tx, choreID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query checklist", "details": err.Error()})
+		return
+	}
+
+	if err = tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chore_id": choreID,
+		"items":    items,
+		"count":    len(items),
+	})
+}
+
+// GetAssignmentChecklist returns an assignment's checklist and progress
+func GetAssignmentChecklist(store storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		assignmentID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID format"})
+			return
+		}
+
+		var exists bool
+		err = db.QueryRow(c.Request.Context(), "SELECT EXISTS(SELECT 1 FROM assignments WHERE id = $1)", assignmentID).Scan(&exists)
+		if err != nil || !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+			return
+		}
+
+		items, err := loadChecklist(c.Request.Context(), db, store, assignmentID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query checklist", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, checklistResponse(assignmentID, items))
+	}
+}
+
+// CheckChecklistItem ticks off a checklist item. Items that require a photo
+// take it as a multipart "photo" file. Checking the first item of a pending
+// assignment starts it.
+func CheckChecklistItem(store storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setChecklistItem(c, store, true)
+	}
+}
+
+// UncheckChecklistItem clears a checklist item
+func UncheckChecklistItem(store storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setChecklistItem(c, store, false)
+	}
+}
+
+func setChecklistItem(c *gin.Context, store storage.Store, checked bool) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, _ := middleware.GetAuthUserID(c)
+	isParent, _ := middleware.GetAuthIsParent(c)
+
+	assignmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID format"})
+		return
+	}
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid checklist item ID format"})
+		return
+	}
+
+	tx, err := db.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var (
+		assignedTo *uuid.UUID
+		status     string
+	)
+	err = tx.QueryRow(c.Request.Context(),
+		"SELECT assigned_to, status FROM assignments WHERE id = $1 FOR UPDATE",
+		assignmentID,
+	).Scan(&assignedTo, &status)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+		}
+		return
+	}
+
+	if !isParent {
+		allowed, err := isAssignee(c.Request.Context(), tx, assignmentID, assignedTo, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check assignee", "details": err.Error()})
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to update this checklist"})
+			return
+		}
+	}
+
+	// Only work that can still be submitted has an editable checklist
+	if !lifecycle.CanTransition(status, lifecycle.StatusPendingVerification) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot change checklist of assignment with status: %s", status)})
+		return
+	}
+
+	if respondIfBlocked(c, tx, assignmentID) {
+		return
+	}
+
+	var (
+		title         string
+		requiresPhoto bool
+	)
+	err = tx.QueryRow(c.Request.Context(), `
+		SELECT title, requires_photo FROM assignment_checklist_items
+		WHERE id = $1 AND assignment_id = $2
+		FOR UPDATE
+	`, itemID, assignmentID).Scan(&title, &requiresPhoto)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Checklist item not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query checklist item", "details": err.Error()})
+		}
+		return
+	}
+
+	// A stored photo is removed again if the transaction does not commit
+	var storedKeys []string
+	committed := false
+	defer func() {
+		if !committed {
+			deletePhotos(context.Background(), store, storedKeys)
+		}
+	}()
+
+	if !checked {
+		_, err = tx.Exec(c.Request.Context(), `
+			UPDATE assignment_checklist_items
+			SET checked_at = NULL, checked_by = NULL
+			WHERE id = $1
+		`, itemID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update checklist item", "details": err.Error()})
+			return
+		}
+	} else {
+		var photoID *uuid.UUID
+		if file, err := c.FormFile("photo"); err == nil {
+			familySlug, _ := middleware.GetFamilySlug(c)
+			photo, keys, err := savePhoto(c.Request.Context(), tx, store, familySlug, assignmentID, userID, "checklist", file)
+			storedKeys = keys
+			if err != nil {
+				respondPhotoError(c, err)
+				return
+			}
+			photoID = &photo.ID
+		}
+
+		if requiresPhoto && photoID == nil {
+			var hasPhoto bool
+			err = tx.QueryRow(c.Request.Context(),
+				"SELECT photo_id IS NOT NULL FROM assignment_checklist_items WHERE id = $1", itemID,
+			).Scan(&hasPhoto)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query checklist item", "details": err.Error()})
+				return
+			}
+			if !hasPhoto {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("\"%s\" needs a photo", title)})
+				return
+			}
+		}
+
+		_, err = tx.Exec(c.Request.Context(), `
+			UPDATE assignment_checklist_items
+			SET checked_at = NOW(),
+				checked_by = $1,
+				photo_id = COALESCE($2, photo_id)
+			WHERE id = $3
+		`, userID, photoID, itemID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update checklist item", "details": err.Error()})
+			return
+		}
+
+		if status == lifecycle.StatusPending {
+			err = lifecycle.Apply(c.Request.Context(), tx, lifecycle.Change{
+				AssignmentID: assignmentID,
+				Event:        lifecycle.EventStarted,
+				From:         status,
+				To:           lifecycle.StatusInProgress,
+				ActorID:      &userID,
+				Metadata:     map[string]interface{}{"checklist_item": title},
+			})
+			if err != nil {
+				respondTransitionError(c, err)
+				return
+			}
+		}
+	}
+
+	items, err := loadChecklist(c.Request.Context(), tx, store, assignmentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query checklist", "details": err.Error()})
+		return
+	}
+
+	if err = tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+	committed = true
+
+	c.JSON(http.StatusOK, checklistResponse(assignmentID, items))
+}
+
+// loadChecklist returns an assignment's checklist with signed photo URLs
+func loadChecklist(ctx context.Context, q database.Querier, store storage.Store, assignmentID uuid.UUID) ([]models.AssignmentChecklistItem, error) {
+	rows, err := q.Query(ctx, `
+		SELECT
+			i.id, i.position, i.title, i.description, i.required, i.requires_photo,
+			i.checked_at, i.checked_by, u.display_name,
+			p.id, p.kind, p.storage_key, p.thumbnail_key, p.width, p.height,
+			p.size_bytes, p.uploaded_by, p.created_at
+		FROM assignment_checklist_items i
+		LEFT JOIN users u ON i.checked_by = u.id
+		LEFT JOIN assignment_photos p ON i.photo_id = p.id
+		WHERE i.assignment_id = $1
+		ORDER BY i.position
+	`, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.AssignmentChecklistItem{}
+	for rows.Next() {
+		var (
+			item               models.AssignmentChecklistItem
+			photoID            *uuid.UUID
+			kind               *string
+			photoKey, thumbKey *string
+			width, height      *int
+			sizeBytes          *int
+			uploadedBy         *uuid.UUID
+			photoCreatedAt     *time.Time
+		)
+		err := rows.Scan(
+			&item.ID, &item.Position, &item.Title, &item.Description, &item.Required, &item.RequiresPhoto,
+			&item.CheckedAt, &item.CheckedBy, &item.CheckedByName,
+			&photoID, &kind, &photoKey, &thumbKey, &width, &height,
+			&sizeBytes, &uploadedBy, &photoCreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		item.Checked = item.CheckedAt != nil
+
+		if photoID != nil {
+			photo := &models.AssignmentPhoto{
+				ID:         *photoID,
+				Kind:       *kind,
+				Width:      *width,
+				Height:     *height,
+				SizeBytes:  *sizeBytes,
+				UploadedBy: uploadedBy,
+				CreatedAt:  *photoCreatedAt,
+			}
+			if err := signPhoto(ctx, store, photo, *photoKey, *thumbKey); err != nil {
+				return nil, err
+			}
+			item.Photo = photo
+		}
+
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func checklistResponse(assignmentID uuid.UUID, items []models.AssignmentChecklistItem) gin.H {
+	checked, outstanding := 0, 0
+	for _, item := range items {
+		if item.Checked {
+			checked++
+		} else if item.Required {
+			outstanding++
+		}
+	}
+	return gin.H{
+		"assignment_id":        assignmentID,
+		"items":                items,
+		"count":                len(items),
+		"checked":              checked,
+		"required_outstanding": outstanding,
+	}
+}
+
+// isAssignee reports whether a user is the assignee of an assignment or one
+// of its team members
+func isAssignee(ctx context.Context, q database.Querier, assignmentID uuid.UUID, assignedTo *uuid.UUID, userID uuid.UUID) (bool, error) {
+	if assignedTo != nil && *assignedTo == userID {
+		return true, nil
+	}
+	var member bool
+	err := q.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM assignment_participants WHERE assignment_id = $1 AND user_id = $2)",
+		assignmentID, userID,
+	).Scan(&member)
+	return member, err
+}
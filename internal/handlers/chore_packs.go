@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/packs"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+)
+
+// ListChorePacks returns the built-in chore packs
+func ListChorePacks(c *gin.Context) {
+	all, err := packs.Builtin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load chore packs", "details": err.Error()})
+		return
+	}
+
+	summaries := make([]models.ChorePackSummary, 0, len(all))
+	for _, pack := range all {
+		summaries = append(summaries, packs.Summarize(pack))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"packs": summaries,
+		"count": len(summaries),
+	})
+}
+
+// GetChorePack returns a built-in pack with its chores, as JSON or, with
+// ?format=yaml, YAML
+func GetChorePack(c *gin.Context) {
+	pack, found, err := packs.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load chore packs", "details": err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chore pack not found"})
+		return
+	}
+
+	respondPack(c, pack)
+}
+
+// InstallChorePack adds a built-in pack's chores to the family (parent only).
+// See importPack for the conflict and dry_run query parameters.
+func InstallChorePack(c *gin.Context) {
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can import chores"})
+		return
+	}
+
+	pack, found, err := packs.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load chore packs", "details": err.Error()})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chore pack not found"})
+		return
+	}
+
+	importPack(c, pack)
+}
+
+// ImportChorePack adds the chores of a pack in the request body, JSON or YAML
+// by Content-Type (parent only)
+func ImportChorePack(c *gin.Context) {
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can import chores"})
+		return
+	}
+
+	var pack models.ChorePack
+	b := binding.JSON
+	if strings.Contains(c.ContentType(), "yaml") {
+		b = binding.YAML
+	}
+	if err := c.ShouldBindWith(&pack, b); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chore pack", "details": err.Error()})
+		return
+	}
+	if err := packs.Validate(pack); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chore pack", "details": err.Error()})
+		return
+	}
+
+	importPack(c, pack)
+}
+
+// ExportChores returns the family's active chores as a pack, optionally only
+// one ?category=, as JSON or, with ?format=yaml, YAML
+func ExportChores(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	family, _ := middleware.GetFamily(c)
+
+	var category *string
+	if value := c.Query("category"); value != "" {
+		category = &value
+	}
+
+	rows, err := db.Query(c.Request.Context(), `
+		SELECT
+			name, description, instructions, category, COALESCE(icon, ''), tags,
+			base_points, estimated_minutes, difficulty, COALESCE(min_age, 0), frequency,
+			rotation_eligible, requires_photo, requires_verification
+		FROM chores
+		WHERE is_active = true
+			AND ($1::text IS NULL OR category = $1)
+		ORDER BY category, name
+	`, category)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query chores", "details": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	pack := models.ChorePack{
+		Format:      packs.Format,
+		Name:        "Family chores",
+		Description: "Exported chores",
+		Chores:      []models.PackChore{},
+	}
+	if family != nil {
+		pack.Name = fmt.Sprintf("%s chores", family.Name)
+	}
+
+	for rows.Next() {
+		var chore models.Chore
+		err := rows.Scan(
+			&chore.Name, &chore.Description, &chore.Instructions, &chore.Category, &chore.Icon, &chore.Tags,
+			&chore.BasePoints, &chore.EstimatedMinutes, &chore.Difficulty, &chore.MinAge, &chore.Frequency,
+			&chore.RotationEligible, &chore.RequiresPhoto, &chore.RequiresVerification,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse chore data", "details": err.Error()})
+			return
+		}
+		pack.Chores = append(pack.Chores, packs.FromChore(chore))
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query chores", "details": err.Error()})
+		return
+	}
+
+	respondPack(c, pack)
+}
+
+// importPack creates a pack's chores through insertChore. A chore whose name
+// matches an active chore is handled by ?conflict=: skip leaves the existing
+// chore alone (the default), rename creates the new one as "Name (2)", and
+// replace overwrites the existing chore with the pack's values.
+// ?dry_run=true reports what would happen without saving.
+func importPack(c *gin.Context, pack models.ChorePack) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	conflict := c.DefaultQuery("conflict", packs.ConflictSkip)
+	if conflict != packs.ConflictSkip && conflict != packs.ConflictRename && conflict != packs.ConflictReplace {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "conflict must be skip, rename or replace"})
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+
+	tx, err := db.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	rows, err := tx.Query(c.Request.Context(), "SELECT id, name FROM chores WHERE is_active = true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query chores", "details": err.Error()})
+		return
+	}
+	existing := map[string]uuid.UUID{}
+	taken := map[string]bool{}
+	for rows.Next() {
+		var (
+			id   uuid.UUID
+			name string
+		)
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse chore data", "details": err.Error()})
+			return
+		}
+		existing[packs.NameKey(name)] = id
+		taken[packs.NameKey(name)] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query chores", "details": err.Error()})
+		return
+	}
+
+	response := models.PackImportResponse{
+		Pack:     pack.Name,
+		Conflict: conflict,
+		DryRun:   dryRun,
+		Results:  make([]models.PackImportResult, 0, len(pack.Chores)),
+	}
+
+	for _, packChore := range pack.Chores {
+		req := packs.CreateRequest(packChore)
+		result := models.PackImportResult{Name: req.Name}
+
+		existingID, duplicate := existing[packs.NameKey(req.Name)]
+		switch {
+		case duplicate && conflict == packs.ConflictSkip:
+			result.Action = "skipped"
+			result.ChoreID = &existingID
+			response.Skipped++
+
+		case duplicate && conflict == packs.ConflictReplace:
+			_, err = tx.Exec(c.Request.Context(), `
+				UPDATE chores
+				SET description = $1,
+					instructions = $2,
+					category = COALESCE(NULLIF($3, ''), category),
+					icon = COALESCE(NULLIF($4, ''), icon),
+					tags = $5,
+					base_points = CASE WHEN $6 > 0 THEN $6 ELSE base_points END,
+					estimated_minutes = $7,
+					difficulty = COALESCE(NULLIF($8, ''), difficulty),
+					min_age = $9,
+					frequency = $10,
+					rotation_eligible = $11,
+					requires_photo = $12,
+					requires_verification = $13,
+					updated_at = NOW()
+				WHERE id = $14
+			`, req.Description, req.Instructions, req.Category, packChore.Icon, req.Tags,
+				req.BasePoints, req.EstimatedMinutes, req.Difficulty, req.MinAge, req.Frequency,
+				req.RotationEligible, req.RequiresPhoto, req.RequiresVerification, existingID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replace chore", "details": err.Error(), "chore": req.Name})
+				return
+			}
+			result.Action = "replaced"
+			result.ChoreID = &existingID
+			response.Replaced++
+
+		default:
+			result.Action = "created"
+			if duplicate {
+				req.Name = packs.UniqueName(req.Name, taken)
+				result.Action = "renamed"
+				result.CreatedAs = &req.Name
+			}
+
+			chore, err := insertChore(c.Request.Context(), tx, req)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chore", "details": err.Error(), "chore": req.Name})
+				return
+			}
+			taken[packs.NameKey(req.Name)] = true
+			if !dryRun {
+				result.ChoreID = &chore.ID
+			}
+			response.Created++
+		}
+
+		response.Results = append(response.Results, result)
+	}
+
+	if !dryRun {
+		if err = tx.Commit(c.Request.Context()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// respondPack writes a pack as JSON or, with ?format=yaml, YAML
+func respondPack(c *gin.Context, pack models.ChorePack) {
+	if c.Query("format") == "yaml" {
+		c.YAML(http.StatusOK, pack)
+		return
+	}
+	c.JSON(http.StatusOK, pack)
+}
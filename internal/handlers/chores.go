@@ -1,17 +1,40 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/JunoAX/housepoints-go/internal/database"
 	"github.com/JunoAX/housepoints-go/internal/middleware"
 	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/timing"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-// ListChores returns all active chores for the family
+// choreSorts maps ?sort= to an ORDER BY clause. Popularity is how many
+// assignments the chore has had.
+var choreSorts = map[string]string{
+	"name":       "c.name ASC",
+	"points":     "c.base_points DESC, c.name",
+	"time":       "c.estimated_minutes ASC NULLS LAST, c.name",
+	"popularity": "assignment_count DESC, c.name",
+}
+
+// ListChores returns the family's chores. Optional filters:
+//   - q: full-text search over name, description and instructions
+//   - tags: comma-separated; chores must have all of them (tag_match=any for
+//     at least one)
+//   - category, difficulty
+//   - age: chores whose minimum age is at most this
+//   - active: true or false
+//
+// sort is name, points, time or popularity. Searches are ranked by relevance
+// unless a sort is given; otherwise chores are ordered by category and name.
 func ListChores(c *gin.Context) {
 	// Get family database connection from context
 	db, ok := middleware.GetFamilyDB(c)
@@ -22,17 +45,80 @@ func ListChores(c *gin.Context) {
 
 	family, _ := middleware.GetFamily(c)
 
+	conditions := []string{"c.is_active = true"}
+	args := []interface{}{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	orderBy := "c.category, c.name"
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		query := fmt.Sprintf("websearch_to_tsquery('english', %s)", arg(q))
+		conditions = append(conditions, "c.search_vector @@ "+query)
+		orderBy = fmt.Sprintf("ts_rank(c.search_vector, %s) DESC, c.name", query)
+	}
+
+	if value := c.Query("tags"); value != "" {
+		tags := []string{}
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		operator := "@>"
+		if c.Query("tag_match") == "any" {
+			operator = "&&"
+		}
+		conditions = append(conditions, fmt.Sprintf("c.tags %s %s::text[]", operator, arg(tags)))
+	}
+	if value := c.Query("category"); value != "" {
+		conditions = append(conditions, "c.category = "+arg(value))
+	}
+	if value := c.Query("difficulty"); value != "" {
+		conditions = append(conditions, "c.difficulty = "+arg(value))
+	}
+	if value := c.Query("age"); value != "" {
+		age, err := strconv.Atoi(value)
+		if err != nil || age < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "age must be a whole number"})
+			return
+		}
+		conditions = append(conditions, "COALESCE(c.min_age, 0) <= "+arg(age))
+	}
+	if value := c.Query("active"); value != "" {
+		active, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "active must be true or false"})
+			return
+		}
+		conditions = append(conditions, "c.active = "+arg(active))
+	}
+
+	if value := c.Query("sort"); value != "" {
+		sort, ok := choreSorts[value]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be name, points, time or popularity"})
+			return
+		}
+		orderBy = sort
+	}
+
 	// Query chores from family database
-	query := `
+	query := fmt.Sprintf(`
 		SELECT
-			id, name, category, base_points, estimated_minutes,
-			difficulty, icon, active, assignment_type
-		FROM chores
-		WHERE is_active = true
-		ORDER BY category, name
-	`
-
-	rows, err := db.Query(c.Request.Context(), query)
+			c.id, c.name, c.category, c.base_points, c.estimated_minutes,
+			c.difficulty, c.icon, c.tags, COALESCE(c.min_age, 0), c.active, c.assignment_type,
+			COALESCE(counts.total, 0) AS assignment_count
+		FROM chores c
+		LEFT JOIN (
+			SELECT chore_id, COUNT(*) AS total FROM assignments GROUP BY chore_id
+		) counts ON counts.chore_id = c.id
+		WHERE %s
+		ORDER BY %s
+	`, strings.Join(conditions, " AND "), orderBy)
+
+	rows, err := db.Query(c.Request.Context(), query, args...)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to query chores",
@@ -52,8 +138,11 @@ func ListChores(c *gin.Context) {
 			&chore.EstimatedMinutes,
 			&chore.Difficulty,
 			&chore.Icon,
+			&chore.Tags,
+			&chore.MinAge,
 			&chore.Active,
 			&chore.AssignmentType,
+			&chore.AssignmentCount,
 		)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -93,6 +182,25 @@ func CreateChore(c *gin.Context) {
 		return
 	}
 
+	chore, err := insertChore(c.Request.Context(), db, req)
+	if err != nil {
+		var reqErr *requestError
+		if errors.As(err, &reqErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": reqErr.message})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chore", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, chore)
+}
+
+// insertChore fills in defaults and inserts a chore. An invalid window comes
+// back as a *requestError.
+func insertChore(ctx context.Context, q database.Querier, req models.ChoreCreateRequest) (models.Chore, error) {
+	var chore models.Chore
+
 	// Set defaults
 	if req.Category == "" {
 		req.Category = "other"
@@ -104,6 +212,11 @@ func CreateChore(c *gin.Context) {
 		req.BasePoints = 10
 	}
 
+	windowStart, windowEnd, err := parseChoreWindow(req.WindowStart, req.WindowEnd)
+	if err != nil {
+		return chore, &requestError{message: err.Error()}
+	}
+
 	choreID := uuid.New()
 
 	query := `
@@ -111,35 +224,43 @@ func CreateChore(c *gin.Context) {
 			id, name, description, instructions, category, base_points,
 			bonus_eligible, penalty_points, estimated_minutes, difficulty,
 			frequency, active, tags, rotation_eligible, requires_photo,
-			requires_verification, created_at, updated_at
+			requires_verification, window_start_minute, window_end_minute,
+			late_cutoff_minutes, min_age, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW(), NOW()
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, NOW(), NOW()
 		)
 		RETURNING id, name, description, instructions, category, base_points,
 			bonus_eligible, penalty_points, estimated_minutes, difficulty,
 			frequency, active, created_at, updated_at, tags, rotation_eligible,
-			requires_photo, requires_verification
+			requires_photo, requires_verification, window_start_minute, window_end_minute,
+			late_cutoff_minutes, COALESCE(min_age, 0), COALESCE(icon, '')
 	`
 
-	var chore models.Chore
-	err := db.QueryRow(c.Request.Context(), query,
+	err = q.QueryRow(ctx, query,
 		choreID, req.Name, req.Description, req.Instructions, req.Category, req.BasePoints,
 		req.BonusEligible, req.PenaltyPoints, req.EstimatedMinutes, req.Difficulty,
 		req.Frequency, true, req.Tags, req.RotationEligible, req.RequiresPhoto,
-		req.RequiresVerification,
+		req.RequiresVerification, windowStart, windowEnd, req.LateCutoffMinutes, req.MinAge,
 	).Scan(
 		&chore.ID, &chore.Name, &chore.Description, &chore.Instructions, &chore.Category,
 		&chore.BasePoints, &chore.BonusEligible, &chore.PenaltyPoints, &chore.EstimatedMinutes,
 		&chore.Difficulty, &chore.Frequency, &chore.Active, &chore.CreatedAt, &chore.UpdatedAt,
 		&chore.Tags, &chore.RotationEligible, &chore.RequiresPhoto, &chore.RequiresVerification,
+		&windowStart, &windowEnd, &chore.LateCutoffMinutes, &chore.MinAge, &chore.Icon,
 	)
-
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chore", "details": err.Error()})
-		return
+		return chore, err
 	}
+	chore.WindowStart, chore.WindowEnd = formatChoreWindow(windowStart, windowEnd)
 
-	c.JSON(http.StatusCreated, chore)
+	// The column default stands unless an icon was given
+	if req.Icon != nil && *req.Icon != "" {
+		err = q.QueryRow(ctx,
+			"UPDATE chores SET icon = $1 WHERE id = $2 RETURNING icon",
+			*req.Icon, chore.ID,
+		).Scan(&chore.Icon)
+	}
+	return chore, err
 }
 
 // UpdateChore updates an existing chore (requires parent permissions)
@@ -249,6 +370,25 @@ func UpdateChore(c *gin.Context) {
 		args = append(args, *req.RequiresVerification)
 		argIndex++
 	}
+	if req.WindowStart != nil || req.WindowEnd != nil {
+		if req.WindowStart == nil || req.WindowEnd == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "window_start and window_end must be set together"})
+			return
+		}
+		windowStart, windowEnd, err := parseChoreWindow(*req.WindowStart, *req.WindowEnd)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates = append(updates, fmt.Sprintf("window_start_minute = $%d", argIndex), fmt.Sprintf("window_end_minute = $%d", argIndex+1))
+		args = append(args, windowStart, windowEnd)
+		argIndex += 2
+	}
+	if req.LateCutoffMinutes != nil {
+		updates = append(updates, fmt.Sprintf("late_cutoff_minutes = $%d", argIndex))
+		args = append(args, *req.LateCutoffMinutes)
+		argIndex++
+	}
 
 	if len(updates) == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
@@ -266,21 +406,27 @@ func UpdateChore(c *gin.Context) {
 		RETURNING id, name, description, instructions, category, base_points,
 			bonus_eligible, penalty_points, estimated_minutes, difficulty,
 			frequency, active, created_at, updated_at, tags, rotation_eligible,
-			requires_photo, requires_verification
+			requires_photo, requires_verification, window_start_minute, window_end_minute,
+			late_cutoff_minutes
 	`, strings.Join(updates, ", "), argIndex)
 
-	var chore models.Chore
+	var (
+		chore                  models.Chore
+		windowStart, windowEnd *int
+	)
 	err = db.QueryRow(c.Request.Context(), query, args...).Scan(
 		&chore.ID, &chore.Name, &chore.Description, &chore.Instructions, &chore.Category,
 		&chore.BasePoints, &chore.BonusEligible, &chore.PenaltyPoints, &chore.EstimatedMinutes,
 		&chore.Difficulty, &chore.Frequency, &chore.Active, &chore.CreatedAt, &chore.UpdatedAt,
 		&chore.Tags, &chore.RotationEligible, &chore.RequiresPhoto, &chore.RequiresVerification,
+		&windowStart, &windowEnd, &chore.LateCutoffMinutes,
 	)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update chore", "details": err.Error()})
 		return
 	}
+	chore.WindowStart, chore.WindowEnd = formatChoreWindow(windowStart, windowEnd)
 
 	c.JSON(http.StatusOK, chore)
 }
@@ -346,18 +492,22 @@ func GetChore(c *gin.Context) {
 			id, name, description, instructions, category, base_points,
 			bonus_eligible, penalty_points, estimated_minutes, difficulty,
 			frequency, active, tags, rotation_eligible, requires_photo,
-			requires_verification, icon, assignment_type, created_at, updated_at
+			requires_verification, icon, assignment_type, created_at, updated_at,
+			window_start_minute, window_end_minute, late_cutoff_minutes
 		FROM chores
 		WHERE id = $1 AND is_active = true
 	`
 
-	var chore models.Chore
+	var (
+		chore                  models.Chore
+		windowStart, windowEnd *int
+	)
 	err = db.QueryRow(c.Request.Context(), query, choreID).Scan(
 		&chore.ID, &chore.Name, &chore.Description, &chore.Instructions, &chore.Category,
 		&chore.BasePoints, &chore.BonusEligible, &chore.PenaltyPoints, &chore.EstimatedMinutes,
 		&chore.Difficulty, &chore.Frequency, &chore.Active, &chore.Tags, &chore.RotationEligible,
 		&chore.RequiresPhoto, &chore.RequiresVerification, &chore.Icon, &chore.AssignmentType,
-		&chore.CreatedAt, &chore.UpdatedAt,
+		&chore.CreatedAt, &chore.UpdatedAt, &windowStart, &windowEnd, &chore.LateCutoffMinutes,
 	)
 
 	if err != nil {
@@ -368,6 +518,39 @@ func GetChore(c *gin.Context) {
 		}
 		return
 	}
+	chore.WindowStart, chore.WindowEnd = formatChoreWindow(windowStart, windowEnd)
 
 	c.JSON(http.StatusOK, chore)
 }
+
+// parseChoreWindow turns an HH:MM window into minutes after midnight. Two
+// empty strings mean no window.
+func parseChoreWindow(start, end string) (*int, *int, error) {
+	if start == "" && end == "" {
+		return nil, nil, nil
+	}
+	if start == "" || end == "" {
+		return nil, nil, fmt.Errorf("window_start and window_end must be set together")
+	}
+	startMinute, err := timing.ParseClock(start)
+	if err != nil {
+		return nil, nil, err
+	}
+	endMinute, err := timing.ParseClock(end)
+	if err != nil {
+		return nil, nil, err
+	}
+	if startMinute >= endMinute {
+		return nil, nil, fmt.Errorf("window_end must be after window_start")
+	}
+	return &startMinute, &endMinute, nil
+}
+
+// formatChoreWindow is the reverse of parseChoreWindow
+func formatChoreWindow(start, end *int) (*string, *string) {
+	if start == nil || end == nil {
+		return nil, nil
+	}
+	startClock, endClock := timing.FormatClock(*start), timing.FormatClock(*end)
+	return &startClock, &endClock
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// etag formats a row version as a strong ETag
+func etag(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// setETag sets the ETag header for a row version
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", etag(version))
+}
+
+// checkIfMatch compares the If-Match header with the current row version. It
+// responds 412 and returns false when the client acted on a stale copy. A
+// missing header or "*" always matches.
+func checkIfMatch(c *gin.Context, version int) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag(version) {
+			return true
+		}
+	}
+
+	c.Header("ETag", etag(version))
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error":           "Resource has changed since it was fetched",
+		"current_version": version,
+	})
+	return false
+}
+
+// assignmentVersion returns an assignment's current version
+func assignmentVersion(ctx context.Context, q database.Querier, assignmentID uuid.UUID) (int, error) {
+	var version int
+	err := q.QueryRow(ctx, "SELECT version FROM assignments WHERE id = $1", assignmentID).Scan(&version)
+	return version, err
+}
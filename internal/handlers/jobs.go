@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/jobs"
+	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ListJobRuns returns recent background job runs for the family (parent only)
+func ListJobRuns(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can view job runs"})
+		return
+	}
+
+	query := `
+		SELECT job_name, run_date, status, started_at, completed_at, error
+		FROM family_job_runs
+	`
+	params := []interface{}{}
+	if name := c.Query("job"); name != "" {
+		query += " WHERE job_name = $1"
+		params = append(params, name)
+	}
+	query += " ORDER BY run_date DESC, job_name ASC LIMIT 100"
+
+	rows, err := db.Query(c.Request.Context(), query, params...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query job runs", "details": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	runs := []models.JobRun{}
+	for rows.Next() {
+		var (
+			run     models.JobRun
+			runDate time.Time
+		)
+		if err := rows.Scan(&run.JobName, &runDate, &run.Status, &run.StartedAt, &run.CompletedAt, &run.Error); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse job run", "details": err.Error()})
+			return
+		}
+		run.RunDate = runDate.Format("2006-01-02")
+		runs = append(runs, run)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"runs":  runs,
+		"count": len(runs),
+	})
+}
+
+// RunJob runs or backfills a daily job for a range of dates (parent only)
+func RunJob(runner *jobs.Runner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		familyID, ok := middleware.GetFamilyID(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Family context not found"})
+			return
+		}
+
+		isParent, _ := middleware.GetAuthIsParent(c)
+		if !isParent {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can run jobs"})
+			return
+		}
+
+		job, ok := runner.Lookup(c.Param("name"))
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found", "jobs": runner.Names()})
+			return
+		}
+
+		var req models.JobRunRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		startDate, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format. Use YYYY-MM-DD"})
+			return
+		}
+
+		endDate := startDate
+		if req.EndDate != "" {
+			endDate, err = time.Parse("2006-01-02", req.EndDate)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format. Use YYYY-MM-DD"})
+				return
+			}
+		}
+
+		if endDate.Before(startDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must not be before start_date"})
+			return
+		}
+		if endDate.Sub(startDate) >= jobs.MaxBackfillDays*24*time.Hour {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Date range cannot exceed 31 days"})
+			return
+		}
+		if endDate.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot run jobs for future dates"})
+			return
+		}
+
+		days, err := runner.Backfill(c.Request.Context(), db, familyID, job, startDate, endDate, req.Force)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Job run failed", "details": err.Error(), "days": days})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"job":   job.Name(),
+			"days":  days,
+			"force": req.Force,
+		})
+	}
+}
@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/JunoAX/housepoints-go/internal/middleware"
 	"github.com/JunoAX/housepoints-go/internal/models"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // GetWeeklyLeaderboard returns the weekly points leaderboard
@@ -147,3 +150,174 @@ func GetAllTimeLeaderboard(c *gin.Context) {
 		TotalUsers:  len(leaderboard),
 	})
 }
+
+// GetLeaderboardHistory returns completed weeks with their winners
+func GetLeaderboardHistory(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	limit := 12
+	if l := c.Query("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 104 {
+			limit = parsed
+		}
+	}
+
+	query := `
+		WITH weeks AS (
+			SELECT week_start, week_end, SUM(points) AS total_points
+			FROM weekly_standings
+			GROUP BY week_start, week_end
+			ORDER BY week_start DESC
+			LIMIT $1
+		)
+		SELECT
+			w.week_start, w.week_end, w.total_points,
+			ws.rank, ws.user_id, u.username, u.display_name, u.avatar_url, u.color_theme,
+			ws.points, ws.chores_completed, ws.is_winner
+		FROM weeks w
+		LEFT JOIN weekly_standings ws ON ws.week_start = w.week_start AND ws.is_winner = true
+		LEFT JOIN users u ON ws.user_id = u.id
+		ORDER BY w.week_start DESC, u.username ASC
+	`
+
+	rows, err := db.Query(c.Request.Context(), query, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query leaderboard history", "details": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	weeks := []models.WeeklyHistoryEntry{}
+	for rows.Next() {
+		var (
+			weekStart, weekEnd time.Time
+			totalPoints        int
+			rank               *int
+			userID             *uuid.UUID
+			username           *string
+			displayName        *string
+			avatarURL          *string
+			colorTheme         *string
+			points             *int
+			choresCompleted    *int
+			isWinner           *bool
+		)
+
+		err := rows.Scan(
+			&weekStart, &weekEnd, &totalPoints,
+			&rank, &userID, &username, &displayName, &avatarURL, &colorTheme,
+			&points, &choresCompleted, &isWinner,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse leaderboard history", "details": err.Error()})
+			return
+		}
+
+		key := weekStart.Format("2006-01-02")
+		if len(weeks) == 0 || weeks[len(weeks)-1].WeekStart != key {
+			weeks = append(weeks, models.WeeklyHistoryEntry{
+				WeekStart:   key,
+				WeekEnd:     weekEnd.Format("2006-01-02"),
+				TotalPoints: totalPoints,
+				Winners:     []models.WeeklyStanding{},
+			})
+		}
+
+		if userID == nil || username == nil {
+			continue
+		}
+
+		week := &weeks[len(weeks)-1]
+		week.Winners = append(week.Winners, models.WeeklyStanding{
+			Rank:            *rank,
+			UserID:          *userID,
+			Username:        *username,
+			DisplayName:     *displayName,
+			AvatarURL:       avatarURL,
+			ColorTheme:      *colorTheme,
+			Points:          *points,
+			ChoresCompleted: *choresCompleted,
+			IsWinner:        *isWinner,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"weeks": weeks,
+		"count": len(weeks),
+	})
+}
+
+// GetWeeklyStandings returns the final leaderboard for a completed week
+func GetWeeklyStandings(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	weekStart, err := time.Parse("2006-01-02", c.Param("week_start"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid week_start format. Use YYYY-MM-DD"})
+		return
+	}
+
+	query := `
+		SELECT
+			ws.rank, ws.user_id, u.username, u.display_name, u.avatar_url, u.color_theme,
+			ws.points, ws.chores_completed, ws.is_winner, ws.week_end
+		FROM weekly_standings ws
+		JOIN users u ON ws.user_id = u.id
+		WHERE ws.week_start = $1
+		ORDER BY ws.rank ASC, u.username ASC
+	`
+
+	rows, err := db.Query(c.Request.Context(), query, weekStart.Format("2006-01-02"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query weekly standings", "details": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	response := models.WeeklyStandingsResponse{
+		WeekStart:   weekStart.Format("2006-01-02"),
+		Leaderboard: []models.WeeklyStanding{},
+	}
+
+	for rows.Next() {
+		var (
+			standing models.WeeklyStanding
+			weekEnd  time.Time
+		)
+		err := rows.Scan(
+			&standing.Rank,
+			&standing.UserID,
+			&standing.Username,
+			&standing.DisplayName,
+			&standing.AvatarURL,
+			&standing.ColorTheme,
+			&standing.Points,
+			&standing.ChoresCompleted,
+			&standing.IsWinner,
+			&weekEnd,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse weekly standings", "details": err.Error()})
+			return
+		}
+
+		response.WeekEnd = weekEnd.Format("2006-01-02")
+		response.Leaderboard = append(response.Leaderboard, standing)
+	}
+
+	if len(response.Leaderboard) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No standings recorded for this week"})
+		return
+	}
+
+	response.TotalUsers = len(response.Leaderboard)
+	c.JSON(http.StatusOK, response)
+}
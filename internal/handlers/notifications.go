@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ListNotifications returns the current user's notifications, newest first
+func ListNotifications(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	query := `
+		SELECT id, type, title, NULLIF(message, ''), (metadata->>'assignment_id')::uuid,
+			COALESCE(read, false), created_at
+		FROM notifications
+		WHERE user_id = $1
+			AND (expires_at IS NULL OR expires_at > NOW())
+	`
+	if c.Query("unread") == "true" {
+		query += ` AND read IS NOT TRUE`
+	}
+	query += ` ORDER BY created_at DESC LIMIT 100`
+
+	rows, err := db.Query(c.Request.Context(), query, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query notifications", "details": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.Type, &n.Title, &n.Body, &n.AssignmentID, &n.Read, &n.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse notification", "details": err.Error()})
+			return
+		}
+		notifications = append(notifications, n)
+	}
+
+	var unread int
+	err = db.QueryRow(c.Request.Context(),
+		`SELECT COUNT(*) FROM notifications
+		WHERE user_id = $1 AND read IS NOT TRUE AND (expires_at IS NULL OR expires_at > NOW())`, userID,
+	).Scan(&unread)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"count":         len(notifications),
+		"unread":        unread,
+	})
+}
+
+// MarkNotificationRead marks one of the current user's notifications as read
+func MarkNotificationRead(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, _ := middleware.GetAuthUserID(c)
+
+	notificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID format"})
+		return
+	}
+
+	result, err := db.Exec(c.Request.Context(), `
+		UPDATE notifications
+		SET read = true
+		WHERE id = $1 AND user_id = $2
+	`, notificationID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification", "details": err.Error()})
+		return
+	}
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+// MarkAllNotificationsRead marks all of the current user's notifications as read
+func MarkAllNotificationsRead(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, _ := middleware.GetAuthUserID(c)
+
+	result, err := db.Exec(c.Request.Context(),
+		"UPDATE notifications SET read = true WHERE user_id = $1 AND read IS NOT TRUE", userID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notifications marked as read",
+		"updated": result.RowsAffected(),
+	})
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
+	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/photos"
+	"github.com/JunoAX/housepoints-go/internal/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// photoURLTTL is how long signed photo URLs stay valid
+const photoURLTTL = 15 * time.Minute
+
+// UploadAssignmentPhoto attaches a before or after photo to an assignment
+func UploadAssignmentPhoto(store storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		familySlug, _ := middleware.GetFamilySlug(c)
+		userID, _ := middleware.GetAuthUserID(c)
+		isParent, _ := middleware.GetAuthIsParent(c)
+
+		assignmentID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID format"})
+			return
+		}
+
+		kind := c.DefaultPostForm("kind", "after")
+		if kind != "before" && kind != "after" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be 'before' or 'after'"})
+			return
+		}
+
+		file, err := c.FormFile("photo")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "photo file is required"})
+			return
+		}
+
+		var (
+			assignedTo *uuid.UUID
+			status     string
+		)
+		err = db.QueryRow(c.Request.Context(),
+			"SELECT assigned_to, status FROM assignments WHERE id = $1",
+			assignmentID,
+		).Scan(&assignedTo, &status)
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+			}
+			return
+		}
+
+		if !isParent {
+			allowed, err := isAssignee(c.Request.Context(), db, assignmentID, assignedTo, userID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check assignee", "details": err.Error()})
+				return
+			}
+			if !allowed {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Not authorized to add photos to this assignment"})
+				return
+			}
+		}
+
+		if lifecycle.IsTerminal(status) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Cannot add photos to assignment with status: %s", status)})
+			return
+		}
+
+		photo, _, err := savePhoto(c.Request.Context(), db, store, familySlug, assignmentID, userID, kind, file)
+		if err != nil {
+			respondPhotoError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusCreated, photo)
+	}
+}
+
+// ListAssignmentPhotos returns an assignment's photos with signed URLs
+func ListAssignmentPhotos(store storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		assignmentID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID format"})
+			return
+		}
+
+		list, err := loadPhotos(c.Request.Context(), db, store, assignmentID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query photos", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"photos": list,
+			"count":  len(list),
+		})
+	}
+}
+
+// ServeFile serves a locally stored file through a signed URL. It needs no
+// authentication; the signature and expiry are the access check.
+func ServeFile(local *storage.Local) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.Param("key")
+		if len(key) > 0 && key[0] == '/' {
+			key = key[1:]
+		}
+
+		if !local.Verify(key, c.Query("expires"), c.Query("signature")) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired link"})
+			return
+		}
+
+		path, err := local.Path(key)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file key"})
+			return
+		}
+
+		c.Header("Cache-Control", "private, max-age=900")
+		c.File(path)
+	}
+}
+
+// savePhoto sanitizes an uploaded image, stores it with a thumbnail and
+// records it against the assignment. It also returns the stored keys, so a
+// caller whose transaction does not commit can remove them.
+func savePhoto(ctx context.Context, q database.Querier, store storage.Store, familySlug string, assignmentID, uploaderID uuid.UUID, kind string, file *multipart.FileHeader) (*models.AssignmentPhoto, []string, error) {
+	if file.Size > photos.MaxUploadBytes {
+		return nil, nil, photos.ErrTooLarge
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	processed, err := photos.Process(f)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	photoID := uuid.New()
+	prefix := fmt.Sprintf("%s/assignments/%s/%s", familySlug, assignmentID, photoID)
+	photoKey, thumbKey := prefix+".jpg", prefix+"_thumb.jpg"
+
+	if err := store.Put(ctx, photoKey, "image/jpeg", processed.Photo); err != nil {
+		return nil, nil, fmt.Errorf("failed to store photo: %w", err)
+	}
+	if err := store.Put(ctx, thumbKey, "image/jpeg", processed.Thumbnail); err != nil {
+		store.Delete(ctx, photoKey)
+		return nil, nil, fmt.Errorf("failed to store thumbnail: %w", err)
+	}
+	keys := []string{photoKey, thumbKey}
+
+	var createdAt time.Time
+	err = q.QueryRow(ctx, `
+		INSERT INTO assignment_photos (
+			id, assignment_id, kind, storage_key, thumbnail_key,
+			content_type, width, height, size_bytes, uploaded_by, created_at
+		) VALUES ($1, $2, $3, $4, $5, 'image/jpeg', $6, $7, $8, $9, NOW())
+		RETURNING created_at
+	`, photoID, assignmentID, kind, photoKey, thumbKey,
+		processed.Width, processed.Height, len(processed.Photo), uploaderID,
+	).Scan(&createdAt)
+	if err != nil {
+		deletePhotos(ctx, store, keys)
+		return nil, nil, fmt.Errorf("failed to record photo: %w", err)
+	}
+
+	photo := &models.AssignmentPhoto{
+		ID:         photoID,
+		Kind:       kind,
+		Width:      processed.Width,
+		Height:     processed.Height,
+		SizeBytes:  len(processed.Photo),
+		UploadedBy: &uploaderID,
+		CreatedAt:  createdAt,
+	}
+	if err := signPhoto(ctx, store, photo, photoKey, thumbKey); err != nil {
+		return nil, keys, err
+	}
+	return photo, keys, nil
+}
+
+// deletePhotos removes stored photo blobs, best effort
+func deletePhotos(ctx context.Context, store storage.Store, keys []string) {
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete photo %s: %v", key, err)
+		}
+	}
+}
+
+// loadPhotos returns an assignment's photos, before photos first
+func loadPhotos(ctx context.Context, q database.Querier, store storage.Store, assignmentID uuid.UUID) ([]models.AssignmentPhoto, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, kind, storage_key, thumbnail_key, width, height, size_bytes, uploaded_by, created_at
+		FROM assignment_photos
+		WHERE assignment_id = $1
+		ORDER BY kind DESC, created_at ASC
+	`, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.AssignmentPhoto{}
+	for rows.Next() {
+		var (
+			photo              models.AssignmentPhoto
+			photoKey, thumbKey string
+		)
+		err := rows.Scan(&photo.ID, &photo.Kind, &photoKey, &thumbKey,
+			&photo.Width, &photo.Height, &photo.SizeBytes, &photo.UploadedBy, &photo.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := signPhoto(ctx, store, &photo, photoKey, thumbKey); err != nil {
+			return nil, err
+		}
+		list = append(list, photo)
+	}
+	return list, rows.Err()
+}
+
+func signPhoto(ctx context.Context, store storage.Store, photo *models.AssignmentPhoto, photoKey, thumbKey string) error {
+	var err error
+	if photo.URL, err = store.SignedURL(ctx, photoKey, photoURLTTL); err != nil {
+		return err
+	}
+	photo.ThumbnailURL, err = store.SignedURL(ctx, thumbKey, photoURLTTL)
+	return err
+}
+
+// respondPhotoError maps upload errors to HTTP responses
+func respondPhotoError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, photos.ErrTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Photo exceeds the 10 MB limit"})
+	case errors.Is(err, photos.ErrTooManyPixels):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Photo exceeds the 40 megapixel limit"})
+	case errors.Is(err, photos.ErrInvalidImage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Photo must be a JPEG, PNG or GIF image"})
+	default:
+		log.Printf("❌ Photo upload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save photo", "details": err.Error()})
+	}
+}
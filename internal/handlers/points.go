@@ -1,11 +1,18 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/jobs"
+	"github.com/JunoAX/housepoints-go/internal/ledger"
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
 	"github.com/JunoAX/housepoints-go/internal/middleware"
 	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/reversals"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -85,7 +92,7 @@ func GetUserTransactions(c *gin.Context) {
 	query := `
 		SELECT
 			id, user_id, points, transaction_type, description,
-			related_assignment_id, related_user_id, created_at
+			related_assignment_id, related_user_id, extra_data::text, reverses_transaction_id, created_at
 		FROM point_transactions
 		WHERE user_id = $1
 	`
@@ -129,6 +136,8 @@ func GetUserTransactions(c *gin.Context) {
 			&pt.Description,
 			&pt.RelatedAssignmentID,
 			&pt.RelatedUserID,
+			&pt.ExtraData,
+			&pt.ReversesTransactionID,
 			&pt.CreatedAt,
 		)
 		if err != nil {
@@ -144,3 +153,158 @@ func GetUserTransactions(c *gin.Context) {
 		"count":        len(transactions),
 	})
 }
+
+// ReconcileBalances compares users' cached point balances with the point
+// ledger and rewrites any that drifted, reporting every discrepancy (parent
+// only). With dry_run the discrepancies are reported but not repaired.
+func ReconcileBalances(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		familyID, ok := middleware.GetFamilyID(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Family context not found"})
+			return
+		}
+
+		isParent, _ := middleware.GetAuthIsParent(c)
+		if !isParent {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can reconcile balances"})
+			return
+		}
+
+		var req models.BalanceReconcileRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			// All fields are optional
+			req = models.BalanceReconcileRequest{}
+		}
+
+		settings, err := platformDB.GetFamilySettings(c.Request.Context(), familyID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load family settings", "details": err.Error()})
+			return
+		}
+		weekStart := settings.WeekStart(jobs.LocalDay(time.Now(), settings.Location()))
+
+		tx, err := db.Begin(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+			return
+		}
+		defer tx.Rollback(c.Request.Context())
+
+		report, err := ledger.Reconcile(c.Request.Context(), tx, weekStart, req.UserID, !req.DryRun)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile balances", "details": err.Error()})
+			return
+		}
+		report.DryRun = req.DryRun
+
+		if !req.DryRun {
+			if err = tx.Commit(c.Request.Context()); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// ReverseTransaction undoes a point transaction with compensating entries and
+// restores the assignment, redemption or reward stock it changed. Parents can
+// reverse any transaction, and with force even if a balance would go below
+// zero; a child can only undo their own redemption while it is still pending.
+func ReverseTransaction(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	isParent, _ := middleware.GetAuthIsParent(c)
+
+	transactionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction ID format"})
+		return
+	}
+
+	var req models.TransactionReversalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// All fields are optional
+		req = models.TransactionReversalRequest{}
+	}
+	if req.Force && !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can force a reversal"})
+		return
+	}
+
+	tx, err := db.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if !isParent {
+		original, err := reversals.Load(c.Request.Context(), tx, transactionID)
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query transaction", "details": err.Error()})
+			}
+			return
+		}
+		if original.UserID != userID || original.TransactionType != ledger.TypeRewardRedemption {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You can only undo your own reward redemptions"})
+			return
+		}
+	}
+
+	reversal, err := reversals.Reverse(c.Request.Context(), tx, reversals.Request{
+		TransactionID: transactionID,
+		ActorID:       userID,
+		Reason:        req.Reason,
+		Force:         req.Force,
+		PendingOnly:   !isParent,
+	})
+	if err != nil {
+		switch {
+		case err.Error() == "no rows in result set":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		case errors.Is(err, reversals.ErrIsReversal):
+			c.JSON(http.StatusConflict, gin.H{"error": "A reversal cannot be reversed"})
+		case errors.Is(err, reversals.ErrAlreadyReversed):
+			c.JSON(http.StatusConflict, gin.H{"error": "Transaction has already been reversed"})
+		case errors.Is(err, reversals.ErrNotReversible):
+			c.JSON(http.StatusConflict, gin.H{"error": "Conversions and scheduled allowance deposits cannot be reversed"})
+		case errors.Is(err, reversals.ErrRedemptionProcessed):
+			c.JSON(http.StatusConflict, gin.H{"error": "The redemption has already been processed, ask a parent to reverse it"})
+		case errors.Is(err, ledger.ErrInsufficientPoints):
+			c.JSON(http.StatusConflict, gin.H{"error": "Reversal would make a balance negative; a parent can force it"})
+		case errors.Is(err, lifecycle.ErrStatusChanged):
+			c.JSON(http.StatusConflict, gin.H{"error": "The assignment is no longer verified"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reverse transaction", "details": err.Error()})
+		}
+		return
+	}
+
+	if err = tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reversal)
+}
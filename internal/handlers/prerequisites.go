@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/notify"
+	"github.com/JunoAX/housepoints-go/internal/sequence"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetChorePrerequisites returns the chores that have to be done before a chore
+func GetChorePrerequisites(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	choreID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chore ID"})
+		return
+	}
+
+	prerequisites, err := sequence.LoadChore(c.Request.Context(), db, choreID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query prerequisites", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chore_id":      choreID,
+		"prerequisites": prerequisites,
+		"count":         len(prerequisites),
+	})
+}
+
+// UpdateChorePrerequisites replaces a chore's prerequisites (parent only).
+// Assignments already handed out keep their links; new ones are linked when
+// they are created.
+func UpdateChorePrerequisites(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can edit chore prerequisites"})
+		return
+	}
+
+	choreID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chore ID"})
+		return
+	}
+
+	var req models.ChorePrerequisitesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	seen := map[uuid.UUID]bool{}
+	ids := []uuid.UUID{}
+	for _, id := range req.ChoreIDs {
+		if id == choreID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A chore cannot be its own prerequisite"})
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	tx, err := db.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var exists bool
+	err = tx.QueryRow(c.Request.Context(), "SELECT EXISTS(SELECT 1 FROM chores WHERE id = $1 AND is_active = true)", choreID).Scan(&exists)
+	if err != nil || !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chore not found"})
+		return
+	}
+
+	var found int
+	err = tx.QueryRow(c.Request.Context(), "SELECT COUNT(*) FROM chores WHERE id = ANY($1) AND is_active = true", ids).Scan(&found)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query chores", "details": err.Error()})
+		return
+	}
+	if found != len(ids) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "One or more prerequisite chores were not found"})
+		return
+	}
+
+	if err = sequence.ReplaceChore(c.Request.Context(), tx, choreID, ids); err != nil {
+		if errors.Is(err, sequence.ErrCycle) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A prerequisite already depends on this chore"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update prerequisites", "details": err.Error()})
+		}
+		return
+	}
+
+	prerequisites, err := sequence.LoadChore(c.Request.Context(), tx, choreID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query prerequisites", "details": err.Error()})
+		return
+	}
+
+	if err = tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chore_id":      choreID,
+		"prerequisites": prerequisites,
+		"count":         len(prerequisites),
+	})
+}
+
+// respondIfBlocked answers 409 and returns true when the assignment is still
+// waiting on prerequisites
+func respondIfBlocked(c *gin.Context, q database.Querier, assignmentID uuid.UUID) bool {
+	blockers, err := sequence.Blockers(c.Request.Context(), q, assignmentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check prerequisites", "details": err.Error()})
+		return true
+	}
+	if len(blockers) == 0 {
+		return false
+	}
+	c.JSON(http.StatusConflict, gin.H{
+		"error":      "This chore is waiting on other chores to be finished first",
+		"sequence":   sequence.StateBlocked,
+		"blocked_by": blockers,
+	})
+	return true
+}
+
+// notifyReleased tells the children whose assignments were waiting on
+// prerequisiteID that they can start
+func notifyReleased(ctx context.Context, q database.Querier, prerequisiteID uuid.UUID) error {
+	dependents, err := sequence.Released(ctx, q, prerequisiteID)
+	if err != nil {
+		return err
+	}
+	for _, d := range dependents {
+		if d.AssignedTo == nil {
+			continue
+		}
+		err := notify.Send(ctx, q, notify.Notification{
+			UserID:       *d.AssignedTo,
+			Type:         notify.TypeReady,
+			Title:        fmt.Sprintf("%s is ready to start", d.ChoreName),
+			AssignmentID: &d.AssignmentID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachSequence fills in the prerequisites and blocked/ready state of a list
+// of assignments
+func attachSequence(ctx context.Context, q database.Querier, assignments []models.AssignmentListResponse) error {
+	ids := make([]uuid.UUID, len(assignments))
+	for i, a := range assignments {
+		ids[i] = a.ID
+	}
+
+	byAssignment, err := sequence.LoadMany(ctx, q, ids)
+	if err != nil {
+		return err
+	}
+	for i := range assignments {
+		assignments[i].Prerequisites = byAssignment[assignments[i].ID]
+		assignments[i].Sequence = sequence.State(assignments[i].Prerequisites)
+	}
+	return nil
+}
@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/JunoAX/housepoints-go/internal/middleware"
 	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/reports"
+	"github.com/JunoAX/housepoints-go/internal/timetrack"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -46,7 +49,7 @@ func GetWeeklySummary(c *gin.Context) {
 			COUNT(DISTINCT a.id) as total_assignments,
 			COUNT(DISTINCT CASE WHEN a.status = 'completed' THEN a.id END) as completed,
 			COUNT(DISTINCT CASE WHEN a.status = 'verified' THEN a.id END) as verified,
-			COUNT(DISTINCT CASE WHEN a.status = 'pending' AND a.due_date < CURRENT_DATE THEN a.id END) as overdue,
+			COUNT(DISTINCT CASE WHEN a.status = 'overdue' OR (a.status = 'pending' AND a.due_date < CURRENT_DATE) THEN a.id END) as overdue,
 			COALESCE(SUM(CASE WHEN a.status = 'verified' THEN c.base_points ELSE 0 END), 0) as total_points,
 			COUNT(DISTINCT a.assigned_to) as active_children
 		FROM assignments a
@@ -69,64 +72,16 @@ func GetWeeklySummary(c *gin.Context) {
 	}
 
 	// Calculate completion rate
-	if summary.TotalAssignments > 0 {
-		summary.CompletionRate = float64(summary.Completed) / float64(summary.TotalAssignments) * 100
-		summary.CompletionRate = float64(int(summary.CompletionRate*10)) / 10 // Round to 1 decimal
-	}
+	summary.CompletionRate = reports.CompletionRate(summary.Completed, summary.TotalAssignments)
 
 	// Get child performance
-	childQuery := `
-		SELECT
-			u.id,
-			u.username,
-			u.display_name,
-			u.color_theme,
-			COUNT(a.id) as total_assignments,
-			COUNT(CASE WHEN a.status = 'completed' OR a.status = 'verified' THEN 1 END) as completed,
-			COUNT(CASE WHEN a.status = 'verified' THEN 1 END) as verified,
-			COALESCE(SUM(CASE WHEN a.status = 'verified' THEN c.base_points ELSE 0 END), 0) as points_earned
-		FROM users u
-		LEFT JOIN assignments a ON u.id = a.assigned_to
-			AND DATE(a.due_date) BETWEEN $1 AND $2
-		LEFT JOIN chores c ON a.chore_id = c.id
-		WHERE u.is_parent = false AND u.is_active = true
-		GROUP BY u.id, u.username, u.display_name, u.color_theme
-		ORDER BY points_earned DESC
-	`
-
-	rows, err := db.Query(c.Request.Context(), childQuery, weekStart, weekEnd)
+	from, _ := time.Parse("2006-01-02", weekStart)
+	to, _ := time.Parse("2006-01-02", weekEnd)
+	children, err := reports.ChildWeekly(c.Request.Context(), db, from, to.AddDate(0, 0, 1))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query children", "details": err.Error()})
 		return
 	}
-	defer rows.Close()
-
-	children := []models.ChildWeeklyPerformance{}
-	for rows.Next() {
-		var child models.ChildWeeklyPerformance
-		err := rows.Scan(
-			&child.ID,
-			&child.Username,
-			&child.DisplayName,
-			&child.ColorTheme,
-			&child.TotalAssignments,
-			&child.Completed,
-			&child.Verified,
-			&child.PointsEarned,
-		)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse child", "details": err.Error()})
-			return
-		}
-
-		// Calculate completion rate
-		if child.TotalAssignments > 0 {
-			child.CompletionRate = float64(child.Completed) / float64(child.TotalAssignments) * 100
-			child.CompletionRate = float64(int(child.CompletionRate*10)) / 10
-		}
-
-		children = append(children, child)
-	}
 
 	c.JSON(http.StatusOK, models.WeeklySummaryResponse{
 		WeekStart: weekStart,
@@ -169,7 +124,7 @@ func GetChildPerformance(c *gin.Context) {
 			COUNT(a.id) as total_assignments,
 			COUNT(CASE WHEN a.status = 'completed' OR a.status = 'verified' THEN 1 END) as completed,
 			COUNT(CASE WHEN a.status = 'verified' THEN 1 END) as verified,
-			COUNT(CASE WHEN a.status = 'pending' AND a.due_date < CURRENT_DATE THEN 1 END) as overdue,
+			COUNT(CASE WHEN a.status = 'overdue' OR (a.status = 'pending' AND a.due_date < CURRENT_DATE) THEN 1 END) as overdue,
 			COALESCE(SUM(CASE WHEN a.status = 'verified' THEN c.base_points ELSE 0 END), 0) as total_points
 		FROM assignments a
 		LEFT JOIN chores c ON a.chore_id = c.id
@@ -437,3 +392,245 @@ func GetPerformanceTrends(c *gin.Context) {
 		ChildPerformance: childPerformance,
 	})
 }
+
+// GetQualityTrends returns each child's quality ratings and rework rounds by week
+func GetQualityTrends(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	// Get days parameter (default 90, max 365)
+	days := 90
+	if daysParam := c.Query("days"); daysParam != "" {
+		if parsedDays, err := strconv.Atoi(daysParam); err == nil {
+			if parsedDays >= 1 && parsedDays <= 365 {
+				days = parsedDays
+			}
+		}
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -days)
+
+	// Every reviewed round counts: ratings come from verified rounds, and
+	// rounds sent back for rework count against the child
+	query := `
+		SELECT
+			u.id,
+			u.display_name,
+			DATE_TRUNC('week', s.reviewed_at)::date as week_start,
+			COALESCE(AVG(s.quality_rating), 0)::float8 as average_rating,
+			COUNT(s.quality_rating) as rated,
+			COALESCE(AVG(COALESCE(s.partial_credit_percent, 100)) FILTER (WHERE s.outcome = 'verified'), 100)::float8 as average_partial_credit,
+			COUNT(*) FILTER (WHERE s.outcome = 'verified') as verified,
+			COUNT(*) FILTER (WHERE s.outcome = 'needs_rework') as reworks
+		FROM assignment_submissions s
+		JOIN assignments a ON s.assignment_id = a.id
+		JOIN users u ON a.assigned_to = u.id
+		WHERE s.reviewed_at IS NOT NULL
+		  AND DATE(s.reviewed_at) BETWEEN $1 AND $2
+	`
+	params := []interface{}{startDate.Format("2006-01-02"), endDate.Format("2006-01-02")}
+
+	if childID := c.Query("child_id"); childID != "" {
+		if _, err := uuid.Parse(childID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid child ID format"})
+			return
+		}
+		query += ` AND a.assigned_to = $3`
+		params = append(params, childID)
+	}
+
+	query += `
+		GROUP BY u.id, u.display_name, week_start
+		ORDER BY u.display_name, week_start
+	`
+
+	rows, err := db.Query(c.Request.Context(), query, params...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query quality trends", "details": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	children := []models.ChildQualityTrend{}
+	for rows.Next() {
+		var (
+			id, name  string
+			weekStart time.Time
+			point     models.QualityTrendPoint
+		)
+		err := rows.Scan(&id, &name, &weekStart, &point.AverageRating, &point.Rated, &point.AveragePartialCredit,
+			&point.Verified, &point.Reworks)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse quality trend", "details": err.Error()})
+			return
+		}
+		point.WeekStart = weekStart.Format("2006-01-02")
+
+		if len(children) == 0 || children[len(children)-1].ID != id {
+			children = append(children, models.ChildQualityTrend{ID: id, Name: name, Weeks: []models.QualityTrendPoint{}})
+		}
+		child := &children[len(children)-1]
+		child.Weeks = append(child.Weeks, point)
+	}
+
+	for i := range children {
+		child := &children[i]
+
+		ratingSum, creditSum := 0.0, 0.0
+		rated := []models.QualityTrendPoint{}
+		for _, week := range child.Weeks {
+			child.Rated += week.Rated
+			child.Verified += week.Verified
+			child.Reworks += week.Reworks
+			ratingSum += week.AverageRating * float64(week.Rated)
+			creditSum += week.AveragePartialCredit * float64(week.Verified)
+			if week.Rated > 0 {
+				rated = append(rated, week)
+			}
+		}
+		if child.Rated > 0 {
+			child.AverageRating = ratingSum / float64(child.Rated)
+		}
+		child.AveragePartialCredit = 100
+		if child.Verified > 0 {
+			child.AveragePartialCredit = creditSum / float64(child.Verified)
+		}
+		if reviewed := child.Verified + child.Reworks; reviewed > 0 {
+			child.ReworkRate = float64(child.Reworks) / float64(reviewed) * 100
+		}
+
+		// Compare the latest rated week against the first one in range
+		child.Trend = "neutral"
+		if n := len(rated); n > 1 {
+			delta := rated[n-1].AverageRating - rated[0].AverageRating
+			if delta >= 0.25 {
+				child.Trend = "up"
+			} else if delta <= -0.25 {
+				child.Trend = "down"
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, models.QualityTrendsResponse{
+		Period: models.DateRange{
+			Start: startDate.Format("2006-01-02"),
+			End:   endDate.Format("2006-01-02"),
+		},
+		Children: children,
+	})
+}
+
+// GetTimeTracking compares actual and estimated minutes per chore and per
+// child, and suggests new estimates from the actual times
+func GetTimeTracking(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	// Get days parameter (default 90, max 365)
+	days := 90
+	if daysParam := c.Query("days"); daysParam != "" {
+		if parsedDays, err := strconv.Atoi(daysParam); err == nil {
+			if parsedDays >= 1 && parsedDays <= 365 {
+				days = parsedDays
+			}
+		}
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -days)
+
+	var childID *uuid.UUID
+	if childIDParam := c.Query("child_id"); childIDParam != "" {
+		parsed, err := uuid.Parse(childIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid child ID format"})
+			return
+		}
+		childID = &parsed
+	}
+
+	samples, err := timetrack.LoadSamples(c.Request.Context(), db, startDate, endDate, childID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query time tracking", "details": err.Error()})
+		return
+	}
+
+	// Samples come ordered by chore, so each chore's times are contiguous
+	chores := []models.ChoreTimeStats{}
+	var minutes []float64
+	finishChore := func() {
+		if len(chores) == 0 {
+			return
+		}
+		chore := &chores[len(chores)-1]
+		total := 0.0
+		for _, m := range minutes {
+			total += m
+		}
+		chore.Timed = len(minutes)
+		chore.AverageMinutes = timetrack.Round(total / float64(len(minutes)))
+		chore.MedianMinutes = timetrack.Round(timetrack.Median(minutes))
+		if chore.EstimatedMinutes != nil && *chore.EstimatedMinutes > 0 {
+			variance := timetrack.Round((chore.MedianMinutes - float64(*chore.EstimatedMinutes)) / float64(*chore.EstimatedMinutes) * 100)
+			chore.VariancePercent = &variance
+		}
+		chore.SuggestedMinutes = timetrack.Suggest(minutes)
+	}
+
+	children := []models.ChildTimeStats{}
+	childIndex := map[uuid.UUID]int{}
+	// Actual minutes on the chores that have an estimate, to compare like with like
+	estimatedActual := []float64{}
+
+	for _, s := range samples {
+		if len(chores) == 0 || chores[len(chores)-1].ChoreID != s.ChoreID.String() {
+			finishChore()
+			chores = append(chores, models.ChoreTimeStats{
+				ChoreID:          s.ChoreID.String(),
+				Name:             s.ChoreName,
+				EstimatedMinutes: s.EstimatedMinutes,
+			})
+			minutes = nil
+		}
+		minutes = append(minutes, s.Minutes)
+
+		i, ok := childIndex[s.UserID]
+		if !ok {
+			i = len(children)
+			childIndex[s.UserID] = i
+			children = append(children, models.ChildTimeStats{ID: s.UserID.String(), Name: s.UserName})
+			estimatedActual = append(estimatedActual, 0)
+		}
+		children[i].Timed++
+		children[i].ActualMinutes += s.Minutes
+		if s.EstimatedMinutes != nil && *s.EstimatedMinutes > 0 {
+			children[i].EstimatedMinutes += *s.EstimatedMinutes
+			estimatedActual[i] += s.Minutes
+		}
+	}
+	finishChore()
+
+	for i := range children {
+		child := &children[i]
+		child.ActualMinutes = timetrack.Round(child.ActualMinutes)
+		if child.EstimatedMinutes > 0 {
+			child.Ratio = math.Round(estimatedActual[i]/float64(child.EstimatedMinutes)*100) / 100
+		}
+	}
+
+	c.JSON(http.StatusOK, models.TimeTrackingResponse{
+		Period: models.DateRange{
+			Start: startDate.Format("2006-01-02"),
+			End:   endDate.Format("2006-01-02"),
+		},
+		Chores:   chores,
+		Children: children,
+	})
+}
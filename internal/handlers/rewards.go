@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/JunoAX/housepoints-go/internal/allowance"
+	"github.com/JunoAX/housepoints-go/internal/ledger"
 	"github.com/JunoAX/housepoints-go/internal/middleware"
 	"github.com/JunoAX/housepoints-go/internal/models"
 	"github.com/gin-gonic/gin"
@@ -34,7 +37,7 @@ func ListRewards(c *gin.Context) {
 		SELECT
 			r.id, r.name, r.description, r.cost_points, r.category, r.icon,
 			r.max_per_week, r.requires_parent_approval, r.active, r.availability,
-			r.stock_remaining,
+			r.stock_remaining, r.version,
 			COALESCE(COUNT(CASE WHEN rr.user_id = $1 THEN 1 END), 0)::int as user_redemption_count
 		FROM rewards r
 		LEFT JOIN reward_redemptions rr ON r.id = rr.reward_id
@@ -68,6 +71,7 @@ func ListRewards(c *gin.Context) {
 			&reward.Active,
 			&reward.Availability,
 			&reward.StockRemaining,
+			&reward.Version,
 			&reward.UserRedemptionCount,
 		)
 		if err != nil {
@@ -126,13 +130,16 @@ func RedeemReward(c *gin.Context) {
 		requiresParentApproval bool
 		active                 bool
 		stockRemaining         *int
+		version                int
 	)
 
+	// Lock the reward so concurrent redemptions see each other's stock changes
 	err = tx.QueryRow(c.Request.Context(), `
-		SELECT name, cost_points, requires_parent_approval, active, stock_remaining
+		SELECT name, cost_points, requires_parent_approval, active, stock_remaining, version
 		FROM rewards
 		WHERE id = $1
-	`, rewardID).Scan(&rewardName, &costPoints, &requiresParentApproval, &active, &stockRemaining)
+		FOR UPDATE
+	`, rewardID).Scan(&rewardName, &costPoints, &requiresParentApproval, &active, &stockRemaining, &version)
 
 	if err != nil {
 		if err.Error() == "no rows in result set" {
@@ -143,6 +150,10 @@ func RedeemReward(c *gin.Context) {
 		return
 	}
 
+	if !checkIfMatch(c, version) {
+		return
+	}
+
 	if !active {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Reward is not active"})
 		return
@@ -157,7 +168,7 @@ func RedeemReward(c *gin.Context) {
 	// Check user has enough points
 	var availablePoints int
 	err = tx.QueryRow(c.Request.Context(),
-		"SELECT available_points FROM users WHERE id = $1",
+		"SELECT available_points FROM users WHERE id = $1 FOR UPDATE",
 		userID,
 	).Scan(&availablePoints)
 
@@ -166,12 +177,21 @@ func RedeemReward(c *gin.Context) {
 		return
 	}
 
+	// Points held by pending conversions cannot be spent
+	held, err := allowance.HeldPoints(c.Request.Context(), tx, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query conversions", "details": err.Error()})
+		return
+	}
+	availablePoints -= held
+
 	if availablePoints < costPoints {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":             "Insufficient points",
 			"points_available":  availablePoints,
 			"points_required":   costPoints,
 			"points_short":      costPoints - availablePoints,
+			"points_held":       held,
 		})
 		return
 	}
@@ -195,44 +215,41 @@ func RedeemReward(c *gin.Context) {
 		return
 	}
 
-	// Deduct points from user (available_points only - trigger handles the rest)
-	_, err = tx.Exec(c.Request.Context(), `
-		UPDATE users
-		SET available_points = available_points - $1,
-			updated_at = NOW()
-		WHERE id = $2
-	`, costPoints, userID)
-
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deduct points", "details": err.Error()})
+	// Post the spend to the ledger, which deducts it from available points
+	_, err = ledger.Post(c.Request.Context(), tx, ledger.Entry{
+		UserID:      userID,
+		Points:      -costPoints,
+		Type:        ledger.TypeRewardRedemption,
+		Description: fmt.Sprintf("Redeemed: %s", rewardName),
+		ExtraData:   map[string]interface{}{"redemption_id": redemptionID, "reward_id": rewardID},
+		NoOverdraft: true,
+	})
+	if errors.Is(err, ledger.ErrInsufficientPoints) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Points balance changed, please retry"})
 		return
 	}
-
-	// Create negative point transaction
-	_, err = tx.Exec(c.Request.Context(), `
-		INSERT INTO point_transactions (
-			id, user_id, points, transaction_type, description, created_at
-		) VALUES ($1, $2, $3, $4, $5, NOW())
-	`, uuid.New(), userID, -costPoints, "reward_redemption", fmt.Sprintf("Redeemed: %s", rewardName))
-
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create transaction", "details": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to deduct points", "details": err.Error()})
 		return
 	}
 
 	// Update stock if limited
 	if stockRemaining != nil {
-		_, err = tx.Exec(c.Request.Context(), `
+		result, err := tx.Exec(c.Request.Context(), `
 			UPDATE rewards
 			SET stock_remaining = stock_remaining - 1,
 				updated_at = NOW()
-			WHERE id = $1
+			WHERE id = $1 AND stock_remaining > 0
 		`, rewardID)
 
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update stock", "details": err.Error()})
 			return
 		}
+		if result.RowsAffected() == 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "Reward is out of stock"})
+			return
+		}
 	}
 
 	// Commit transaction
@@ -337,13 +354,17 @@ func UpdateReward(c *gin.Context) {
 	}
 
 	// Check if reward exists
-	var exists bool
-	err = db.QueryRow(c.Request.Context(), "SELECT EXISTS(SELECT 1 FROM rewards WHERE id = $1)", rewardID).Scan(&exists)
-	if err != nil || !exists {
+	var version int
+	err = db.QueryRow(c.Request.Context(), "SELECT version FROM rewards WHERE id = $1", rewardID).Scan(&version)
+	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Reward not found"})
 		return
 	}
 
+	if !checkIfMatch(c, version) {
+		return
+	}
+
 	// Build dynamic UPDATE query
 	updates := []string{}
 	args := []interface{}{}
@@ -415,29 +436,36 @@ func UpdateReward(c *gin.Context) {
 	}
 
 	updates = append(updates, "updated_at = NOW()")
-	args = append(args, rewardID)
+	args = append(args, rewardID, version)
 
+	// Only update the version that was checked, so a concurrent edit is not
+	// silently overwritten
 	query := fmt.Sprintf(`
 		UPDATE rewards
 		SET %s
-		WHERE id = $%d
+		WHERE id = $%d AND version = $%d
 		RETURNING id, name, description, cost_points, category, availability,
-			stock_remaining, icon, value_in_cents, requires_parent_approval, active
-	`, strings.Join(updates, ", "), argIndex)
+			stock_remaining, icon, value_in_cents, requires_parent_approval, active, version
+	`, strings.Join(updates, ", "), argIndex, argIndex+1)
 
 	var reward models.Reward
 	err = db.QueryRow(c.Request.Context(), query, args...).Scan(
 		&reward.ID, &reward.Name, &reward.Description, &reward.CostPoints,
 		&reward.Category, &reward.Availability, &reward.StockRemaining,
 		&reward.Icon, &reward.ValueInCents, &reward.RequiresParentApproval,
-		&reward.Active,
+		&reward.Active, &reward.Version,
 	)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reward", "details": err.Error()})
+		if err.Error() == "no rows in result set" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Reward was modified by another request, please retry"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reward", "details": err.Error()})
+		}
 		return
 	}
 
+	setETag(c, reward.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"reward":  reward,
 		"message": "Reward updated successfully",
@@ -466,12 +494,23 @@ func DeleteReward(c *gin.Context) {
 		return
 	}
 
+	var version int
+	err = db.QueryRow(c.Request.Context(), "SELECT version FROM rewards WHERE id = $1", rewardID).Scan(&version)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reward not found"})
+		return
+	}
+
+	if !checkIfMatch(c, version) {
+		return
+	}
+
 	// Soft delete by setting active to false
 	result, err := db.Exec(c.Request.Context(), `
 		UPDATE rewards
 		SET active = false, updated_at = NOW()
-		WHERE id = $1
-	`, rewardID)
+		WHERE id = $1 AND version = $2
+	`, rewardID, version)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete reward", "details": err.Error()})
@@ -480,7 +519,7 @@ func DeleteReward(c *gin.Context) {
 
 	rowsAffected := result.RowsAffected()
 	if rowsAffected == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Reward not found"})
+		c.JSON(http.StatusConflict, gin.H{"error": "Reward was modified by another request, please retry"})
 		return
 	}
 
@@ -0,0 +1,334 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/checklist"
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
+	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/rotation"
+	"github.com/JunoAX/housepoints-go/internal/schedule"
+	"github.com/JunoAX/housepoints-go/internal/sequence"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RunRotation distributes rotation-eligible chores among children (parent only)
+func RunRotation(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		isParent, _ := middleware.GetAuthIsParent(c)
+		if !isParent {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can run chore rotation"})
+			return
+		}
+
+		userID, _ := middleware.GetAuthUserID(c)
+
+		var req models.RotationRunRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			// All fields are optional
+			req = models.RotationRunRequest{}
+		}
+
+		// Set defaults
+		if req.Days <= 0 {
+			req.Days = 1
+		}
+		if req.Days > 31 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days cannot exceed 31"})
+			return
+		}
+		if req.WindowDays <= 0 {
+			req.WindowDays = 14
+		}
+
+		// Dates are family-local days
+		loc := familyLocation(c, platformDB)
+		startDate := time.Now().In(loc)
+		if req.StartDate != nil && *req.StartDate != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", *req.StartDate, loc)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format. Use YYYY-MM-DD"})
+				return
+			}
+			startDate = parsed
+		}
+		startDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+
+		ctx := c.Request.Context()
+
+		tx, err := db.Begin(ctx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		// Chores picked by ID bring their prerequisites along
+		if len(req.ChoreIDs) > 0 {
+			if req.ChoreIDs, err = sequence.WithPrerequisites(ctx, tx, req.ChoreIDs); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query prerequisites", "details": err.Error()})
+				return
+			}
+		}
+
+		chores, err := rotation.LoadChores(ctx, tx, req.ChoreIDs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query chores", "details": err.Error()})
+			return
+		}
+
+		children, err := rotation.LoadChildren(ctx, tx)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query children", "details": err.Error()})
+			return
+		}
+
+		loads, err := rotation.LoadWindow(ctx, tx, startDate.AddDate(0, 0, -req.WindowDays), startDate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query rotation window", "details": err.Error()})
+			return
+		}
+
+		response := models.RotationRunResponse{
+			RunID:      uuid.New(),
+			DryRun:     req.DryRun,
+			WindowDays: req.WindowDays,
+			Days:       []models.RotationDay{},
+		}
+
+		for i := 0; i < req.Days; i++ {
+			date := startDate.AddDate(0, 0, i)
+
+			presence, err := schedule.LoadPresence(ctx, tx, date)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query schedule", "details": err.Error()})
+				return
+			}
+
+			// Skip chores that already have an assignment on this date so reruns are safe
+			var pending []rotation.Chore
+			day := models.RotationDay{
+				Date:          date.Format("2006-01-02"),
+				KidsPresent:   presence.KidsPresent,
+				ScheduleKnown: presence.Known,
+				Decisions:     []models.RotationDayResult{},
+			}
+			dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+			for _, chore := range chores {
+				var exists bool
+				err := tx.QueryRow(ctx, `
+					SELECT EXISTS(
+						SELECT 1 FROM assignments
+						WHERE chore_id = $1 AND due_date >= $2 AND due_date < $3
+					)
+				`, chore.ID, dayStart, dayStart.AddDate(0, 0, 1)).Scan(&exists)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing assignments", "details": err.Error()})
+					return
+				}
+				if exists {
+					day.Skipped = append(day.Skipped, chore.Name)
+					continue
+				}
+				pending = append(pending, chore)
+			}
+
+			present := rotation.OnDay(children, date, presence.IsPresent)
+
+			dueDate := time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
+
+			for _, decision := range rotation.Assign(present, pending, loads) {
+				result := models.RotationDayResult{RotationDecision: decision}
+
+				if !req.DryRun {
+					if decision.AssignedTo != nil {
+						assignmentID := uuid.New()
+						_, err = tx.Exec(ctx, `
+							INSERT INTO assignments (
+								id, chore_id, assigned_to, assigned_by, status,
+								points_offered, due_date, created_at
+							)
+							SELECT $1, id, $2, $3, 'pending', base_points, $4, NOW()
+							FROM chores WHERE id = $5
+						`, assignmentID, *decision.AssignedTo, userID, dueDate, decision.ChoreID)
+						if err != nil {
+							c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create assignment", "details": err.Error()})
+							return
+						}
+						err = lifecycle.Record(ctx, tx, lifecycle.Change{
+							AssignmentID: assignmentID,
+							Event:        lifecycle.EventCreated,
+							To:           lifecycle.StatusPending,
+							ActorID:      &userID,
+							Notes:        &decision.Reason,
+							Metadata:     map[string]interface{}{"rotation_run_id": response.RunID},
+						})
+						if err != nil {
+							c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record assignment event", "details": err.Error()})
+							return
+						}
+						if err = checklist.Snapshot(ctx, tx, assignmentID); err != nil {
+							c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to copy checklist", "details": err.Error()})
+							return
+						}
+						result.AssignmentID = &assignmentID
+						response.Created++
+					}
+
+					candidatesJSON, _ := json.Marshal(decision.Candidates)
+					_, err = tx.Exec(ctx, `
+						INSERT INTO rotation_decisions (
+							id, run_id, assignment_id, chore_id, assigned_to,
+							assignment_date, window_days, reason, candidates, created_by, created_at
+						) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+					`, uuid.New(), response.RunID, result.AssignmentID, decision.ChoreID,
+						decision.AssignedTo, day.Date, req.WindowDays, decision.Reason,
+						candidatesJSON, userID)
+					if err != nil {
+						c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record rotation decision", "details": err.Error()})
+						return
+					}
+				}
+
+				day.Decisions = append(day.Decisions, result)
+			}
+
+			if !req.DryRun {
+				if err = sequence.Link(ctx, tx, dayStart); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link prerequisites", "details": err.Error()})
+					return
+				}
+			}
+
+			response.Days = append(response.Days, day)
+		}
+
+		if !req.DryRun {
+			if err = tx.Commit(ctx); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// GetRotationDecisions explains past rotation assignments (parent only)
+func GetRotationDecisions(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can view rotation decisions"})
+		return
+	}
+
+	// Optional filters
+	date := c.Query("date")
+	userIDParam := c.Query("user_id")
+	runIDParam := c.Query("run_id")
+
+	query := `
+		SELECT
+			rd.id, rd.run_id, rd.assignment_id, rd.chore_id, c.name,
+			rd.assigned_to, u.display_name, rd.assignment_date, rd.window_days,
+			rd.reason, rd.candidates, rd.created_at
+		FROM rotation_decisions rd
+		JOIN chores c ON rd.chore_id = c.id
+		LEFT JOIN users u ON rd.assigned_to = u.id
+		WHERE 1=1
+	`
+
+	params := []interface{}{}
+	paramCount := 0
+
+	if date != "" {
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			return
+		}
+		paramCount++
+		query += fmt.Sprintf(" AND rd.assignment_date = $%d", paramCount)
+		params = append(params, date)
+	}
+
+	if userIDParam != "" {
+		userID, err := uuid.Parse(userIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id format"})
+			return
+		}
+		paramCount++
+		query += fmt.Sprintf(" AND rd.assigned_to = $%d", paramCount)
+		params = append(params, userID)
+	}
+
+	if runIDParam != "" {
+		runID, err := uuid.Parse(runIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid run_id format"})
+			return
+		}
+		paramCount++
+		query += fmt.Sprintf(" AND rd.run_id = $%d", paramCount)
+		params = append(params, runID)
+	}
+
+	query += ` ORDER BY rd.assignment_date DESC, c.name ASC LIMIT 200`
+
+	rows, err := db.Query(c.Request.Context(), query, params...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query rotation decisions", "details": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	decisions := []models.RotationDecisionRecord{}
+	for rows.Next() {
+		var (
+			record         models.RotationDecisionRecord
+			assignmentDate time.Time
+			candidatesJSON []byte
+		)
+
+		err := rows.Scan(
+			&record.ID, &record.RunID, &record.AssignmentID, &record.ChoreID, &record.ChoreName,
+			&record.AssignedTo, &record.AssignedToName, &assignmentDate, &record.WindowDays,
+			&record.Reason, &candidatesJSON, &record.CreatedAt,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse rotation decision", "details": err.Error()})
+			return
+		}
+
+		record.AssignmentDate = assignmentDate.Format("2006-01-02")
+		record.Candidates = []models.RotationCandidate{}
+		if len(candidatesJSON) > 0 {
+			json.Unmarshal(candidatesJSON, &record.Candidates)
+		}
+
+		decisions = append(decisions, record)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"decisions": decisions,
+		"count":     len(decisions),
+	})
+}
@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/JunoAX/housepoints-go/internal/database"
 	"github.com/JunoAX/housepoints-go/internal/middleware"
 	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/schedule"
 	"github.com/gin-gonic/gin"
 )
 
@@ -137,3 +139,77 @@ func GetFamilySchedule(c *gin.Context) {
 		TotalDays: len(schedule),
 	})
 }
+
+// ReconcileSchedule reassigns or defers pending assignments whose assignee is
+// away according to the family schedule (parent only)
+func ReconcileSchedule(platformDB *database.PlatformDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db, ok := middleware.GetFamilyDB(c)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+			return
+		}
+
+		isParent, _ := middleware.GetAuthIsParent(c)
+		if !isParent {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can reconcile the schedule"})
+			return
+		}
+
+		userID, _ := middleware.GetAuthUserID(c)
+
+		var req models.ScheduleReconcileRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			// All fields are optional
+			req = models.ScheduleReconcileRequest{}
+		}
+
+		if req.Days <= 0 {
+			req.Days = 7
+		}
+		if req.Days > 31 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days cannot exceed 31"})
+			return
+		}
+
+		loc := familyLocation(c, platformDB)
+		startDate := time.Now().In(loc)
+		if req.StartDate != nil && *req.StartDate != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", *req.StartDate, loc)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format. Use YYYY-MM-DD"})
+				return
+			}
+			startDate = parsed
+		}
+		startDate = time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+		endDate := startDate.AddDate(0, 0, req.Days-1)
+
+		tx, err := db.Begin(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+			return
+		}
+		defer tx.Rollback(c.Request.Context())
+
+		adjustments, err := schedule.ReconcileAbsences(c.Request.Context(), tx, startDate, endDate, loc, &userID, req.DryRun)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reconcile schedule", "details": err.Error()})
+			return
+		}
+
+		if !req.DryRun {
+			if err = tx.Commit(c.Request.Context()); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, models.ScheduleReconcileResponse{
+			StartDate:   startDate.Format("2006-01-02"),
+			EndDate:     endDate.Format("2006-01-02"),
+			DryRun:      req.DryRun,
+			Adjustments: adjustments,
+		})
+	}
+}
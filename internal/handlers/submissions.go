@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Submission outcomes
+const (
+	outcomePending     = "pending"
+	outcomeVerified    = "verified"
+	outcomeNeedsRework = "needs_rework"
+)
+
+// submissionReview is a parent's judgement of the latest round
+type submissionReview struct {
+	Outcome              string
+	ReviewedBy           *uuid.UUID // nil when auto-approved
+	Notes                *string
+	RejectionReason      *string
+	RedoDueDate          *time.Time
+	QualityRating        *int
+	PartialCreditPercent *int
+	PointsAwarded        *int
+}
+
+// recordSubmission starts a new completion round and returns its number
+func recordSubmission(ctx context.Context, q database.Querier, assignmentID, submittedBy uuid.UUID, notes *string) (int, error) {
+	var round int
+	err := q.QueryRow(ctx, `
+		INSERT INTO assignment_submissions (
+			id, assignment_id, round, submitted_by, submitted_at, completion_notes, outcome
+		)
+		SELECT $1, $2, COALESCE(MAX(round), 0) + 1, $3, NOW(), $4, 'pending'
+		FROM assignment_submissions
+		WHERE assignment_id = $2
+		RETURNING round
+	`, uuid.New(), assignmentID, submittedBy, notes).Scan(&round)
+	return round, err
+}
+
+// reviewSubmission records the outcome of the latest round. Assignments
+// completed before rounds were kept get a round created for them.
+func reviewSubmission(ctx context.Context, q database.Querier, assignmentID uuid.UUID, r submissionReview) error {
+	result, err := q.Exec(ctx, `
+		UPDATE assignment_submissions
+		SET outcome = $1,
+			reviewed_by = $2,
+			reviewed_at = NOW(),
+			review_notes = $3,
+			rejection_reason = $4,
+			redo_due_date = $5,
+			quality_rating = $6,
+			partial_credit_percent = $7,
+			points_awarded = $8
+		WHERE assignment_id = $9
+			AND round = (SELECT MAX(round) FROM assignment_submissions WHERE assignment_id = $9)
+			AND outcome = 'pending'
+	`, r.Outcome, r.ReviewedBy, r.Notes, r.RejectionReason, r.RedoDueDate,
+		r.QualityRating, r.PartialCreditPercent, r.PointsAwarded, assignmentID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() > 0 {
+		return nil
+	}
+
+	_, err = q.Exec(ctx, `
+		INSERT INTO assignment_submissions (
+			id, assignment_id, round, submitted_by, submitted_at, completion_notes, outcome,
+			reviewed_by, reviewed_at, review_notes, rejection_reason, redo_due_date,
+			quality_rating, partial_credit_percent, points_awarded
+		)
+		SELECT $1, a.id,
+			COALESCE((SELECT MAX(round) FROM assignment_submissions WHERE assignment_id = a.id), 0) + 1,
+			a.assigned_to, COALESCE(a.completed_at, NOW()), a.completion_notes, $2,
+			$3, NOW(), $4, $5, $6, $7, $8, $9
+		FROM assignments a
+		WHERE a.id = $10
+	`, uuid.New(), r.Outcome, r.ReviewedBy, r.Notes, r.RejectionReason, r.RedoDueDate,
+		r.QualityRating, r.PartialCreditPercent, r.PointsAwarded, assignmentID)
+	return err
+}
+
+// GetAssignmentSubmissions returns every completion round of an assignment
+// with its review, oldest first
+func GetAssignmentSubmissions(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	assignmentIDParam := c.Param("id")
+	assignmentID, err := uuid.Parse(assignmentIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID format"})
+		return
+	}
+
+	var (
+		status      string
+		reworkCount int
+	)
+	err = db.QueryRow(c.Request.Context(),
+		"SELECT status, rework_count FROM assignments WHERE id = $1", assignmentID,
+	).Scan(&status, &reworkCount)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+		}
+		return
+	}
+
+	rows, err := db.Query(c.Request.Context(), `
+		SELECT
+			s.id, s.round, s.submitted_by, su.display_name, s.submitted_at, s.completion_notes,
+			s.outcome, s.reviewed_by, ru.display_name, s.reviewed_at, s.review_notes,
+			s.rejection_reason, s.redo_due_date, s.quality_rating, s.partial_credit_percent,
+			s.points_awarded
+		FROM assignment_submissions s
+		LEFT JOIN users su ON s.submitted_by = su.id
+		LEFT JOIN users ru ON s.reviewed_by = ru.id
+		WHERE s.assignment_id = $1
+		ORDER BY s.round ASC
+	`, assignmentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query submissions", "details": err.Error()})
+		return
+	}
+	defer rows.Close()
+
+	submissions := []models.AssignmentSubmission{}
+	for rows.Next() {
+		var s models.AssignmentSubmission
+		err := rows.Scan(
+			&s.ID, &s.Round, &s.SubmittedBy, &s.SubmittedByName, &s.SubmittedAt, &s.CompletionNotes,
+			&s.Outcome, &s.ReviewedBy, &s.ReviewedByName, &s.ReviewedAt, &s.ReviewNotes,
+			&s.RejectionReason, &s.RedoDueDate, &s.QualityRating, &s.PartialCreditPercent,
+			&s.PointsAwarded,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse submission", "details": err.Error()})
+			return
+		}
+		submissions = append(submissions, s)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"assignment_id": assignmentID,
+		"status":        status,
+		"rework_count":  reworkCount,
+		"submissions":   submissions,
+		"count":         len(submissions),
+	})
+}
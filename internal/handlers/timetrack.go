@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
+	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/timetrack"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Timer actions
+const (
+	timerStart  = "start"
+	timerPause  = "pause"
+	timerResume = "resume"
+	timerStop   = "stop"
+)
+
+// StartAssignment moves an assignment to in_progress and starts the caller's
+// timer
+func StartAssignment(c *gin.Context) {
+	timerAction(c, timerStart)
+}
+
+// PauseAssignment stops the caller's timer; the assignment stays in progress
+func PauseAssignment(c *gin.Context) {
+	timerAction(c, timerPause)
+}
+
+// ResumeAssignment starts the caller's timer again on an assignment in progress
+func ResumeAssignment(c *gin.Context) {
+	timerAction(c, timerResume)
+}
+
+// StopAssignment stops the timer without finishing the chore. A parent stops
+// every running timer. A solo assignment goes back to pending.
+func StopAssignment(c *gin.Context) {
+	timerAction(c, timerStop)
+}
+
+func timerAction(c *gin.Context, action string) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	isParent, _ := middleware.GetAuthIsParent(c)
+
+	assignmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID format"})
+		return
+	}
+
+	tx, err := db.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var (
+		assignedTo *uuid.UUID
+		status     string
+		isTeam     bool
+		version    int
+	)
+	err = tx.QueryRow(c.Request.Context(),
+		"SELECT assigned_to, status, is_team, version FROM assignments WHERE id = $1 FOR UPDATE",
+		assignmentID,
+	).Scan(&assignedTo, &status, &isTeam, &version)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+		}
+		return
+	}
+
+	// Time is recorded against whoever does the work, so only the assigned
+	// child (or a team member) can run a timer. Parents can stop them.
+	assignee, err := isAssignee(c.Request.Context(), tx, assignmentID, assignedTo, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check assignee", "details": err.Error()})
+		return
+	}
+	if !assignee && !(isParent && (action == timerPause || action == timerStop)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the assigned child can time this chore"})
+		return
+	}
+
+	if !checkIfMatch(c, version) {
+		return
+	}
+
+	// A parent who is not on the chore pauses or stops everyone's timer
+	var timerUser *uuid.UUID
+	if assignee {
+		timerUser = &userID
+	}
+
+	change := lifecycle.Change{
+		AssignmentID: assignmentID,
+		From:         status,
+		ActorID:      &userID,
+	}
+
+	switch action {
+	case timerStart, timerResume:
+		if action == timerResume && status != lifecycle.StatusInProgress {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot resume assignment with status: %s", status)})
+			return
+		}
+		if status != lifecycle.StatusInProgress && !lifecycle.CanTransition(status, lifecycle.StatusInProgress) {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot start assignment with status: %s", status)})
+			return
+		}
+		if respondIfBlocked(c, tx, assignmentID) {
+			return
+		}
+
+		if err = timetrack.Start(c.Request.Context(), tx, assignmentID, userID); err != nil {
+			if errors.Is(err, timetrack.ErrRunning) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Timer is already running"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start timer", "details": err.Error()})
+			}
+			return
+		}
+
+		change.Event = lifecycle.EventResumed
+		if status != lifecycle.StatusInProgress {
+			change.Event = lifecycle.EventStarted
+			change.To = lifecycle.StatusInProgress
+		}
+
+	case timerPause, timerStop:
+		stopped, err := timetrack.Stop(c.Request.Context(), tx, assignmentID, timerUser)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stop timer", "details": err.Error()})
+			return
+		}
+		if stopped == 0 && action == timerPause {
+			c.JSON(http.StatusConflict, gin.H{"error": "Timer is not running"})
+			return
+		}
+
+		change.Event = lifecycle.EventPaused
+		if action == timerStop {
+			change.Event = lifecycle.EventStopped
+			if !isTeam && status == lifecycle.StatusInProgress {
+				change.To = lifecycle.StatusPending
+			}
+		}
+	}
+
+	if change.To != "" {
+		err = lifecycle.Apply(c.Request.Context(), tx, change)
+	} else {
+		err = lifecycle.Record(c.Request.Context(), tx, change)
+	}
+	if err != nil {
+		respondTransitionError(c, err)
+		return
+	}
+
+	summary, err := timetrack.Load(c.Request.Context(), tx, assignmentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query time segments", "details": err.Error()})
+		return
+	}
+
+	if version, err = assignmentVersion(c.Request.Context(), tx, assignmentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+		return
+	}
+
+	if err = tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	newStatus := status
+	if change.To != "" {
+		newStatus = change.To
+	}
+
+	setETag(c, version)
+	c.JSON(http.StatusOK, gin.H{
+		"message":       fmt.Sprintf("Assignment %s", change.Event),
+		"assignment_id": assignmentID,
+		"status":        newStatus,
+		"time":          summary,
+		"version":       version,
+	})
+}
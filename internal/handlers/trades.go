@@ -0,0 +1,688 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/JunoAX/housepoints-go/internal/allowance"
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
+	"github.com/JunoAX/housepoints-go/internal/middleware"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/notify"
+	"github.com/JunoAX/housepoints-go/internal/trades"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OfferAssignmentTrade puts one of the caller's pending assignments up for
+// trade, optionally with a points sweetener for whoever takes it
+func OfferAssignmentTrade(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	assignmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID format"})
+		return
+	}
+
+	var req models.TradeOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	tx, err := db.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var (
+		assignedTo *uuid.UUID
+		status     string
+		isTeam     bool
+		choreName  string
+	)
+	err = tx.QueryRow(c.Request.Context(), `
+		SELECT a.assigned_to, a.status, a.is_team, c.name
+		FROM assignments a
+		JOIN chores c ON a.chore_id = c.id
+		WHERE a.id = $1
+		FOR UPDATE OF a
+	`, assignmentID).Scan(&assignedTo, &status, &isTeam, &choreName)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+		}
+		return
+	}
+
+	if assignedTo == nil || *assignedTo != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the assigned child can trade this chore"})
+		return
+	}
+	if isTeam {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Team chores cannot be traded"})
+		return
+	}
+	if status != lifecycle.StatusPending {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot trade assignment with status: %s", status)})
+		return
+	}
+
+	maxSweetener := database.SettingInt(c.Request.Context(), tx, "trade_max_sweetener", 50)
+	if req.SweetenerPoints > maxSweetener {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Sweetener cannot be more than %d points", maxSweetener)})
+		return
+	}
+
+	// The sweetener is only paid when the trade completes, but a child cannot
+	// offer points they do not have or that pending conversions hold
+	if req.SweetenerPoints > 0 {
+		var available int
+		err = tx.QueryRow(c.Request.Context(), "SELECT available_points FROM users WHERE id = $1", userID).Scan(&available)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query points", "details": err.Error()})
+			return
+		}
+		held, err := allowance.HeldPoints(c.Request.Context(), tx, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query conversions", "details": err.Error()})
+			return
+		}
+		available -= held
+		if available < req.SweetenerPoints {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":            "Not enough points for the sweetener",
+				"available_points": available,
+			})
+			return
+		}
+	}
+
+	var live bool
+	err = tx.QueryRow(c.Request.Context(),
+		"SELECT EXISTS(SELECT 1 FROM assignment_trades WHERE assignment_id = $1 AND status = ANY($2))",
+		assignmentID, []string{trades.StatusOpen, trades.StatusPendingApproval},
+	).Scan(&live)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query trades", "details": err.Error()})
+		return
+	}
+	if live {
+		c.JSON(http.StatusConflict, gin.H{"error": "This assignment is already up for trade"})
+		return
+	}
+
+	tradeID := uuid.New()
+	_, err = tx.Exec(c.Request.Context(), `
+		INSERT INTO assignment_trades (id, assignment_id, offered_by, sweetener_points, note, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+	`, tradeID, assignmentID, userID, req.SweetenerPoints, req.Note, trades.StatusOpen)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create trade", "details": err.Error()})
+		return
+	}
+
+	if err = trades.Record(c.Request.Context(), tx, tradeID, trades.EventOffered, &userID, req.Note); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record trade", "details": err.Error()})
+		return
+	}
+
+	err = lifecycle.Record(c.Request.Context(), tx, lifecycle.Change{
+		AssignmentID: assignmentID,
+		Event:        lifecycle.EventTradeOffered,
+		From:         status,
+		ActorID:      &userID,
+		Notes:        req.Note,
+		Metadata: map[string]interface{}{
+			"trade_id":         tradeID,
+			"sweetener_points": req.SweetenerPoints,
+		},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record event", "details": err.Error()})
+		return
+	}
+
+	title := fmt.Sprintf("%s is up for trade", choreName)
+	if req.SweetenerPoints > 0 {
+		title = fmt.Sprintf("%s is up for trade with a %d point sweetener", choreName, req.SweetenerPoints)
+	}
+	if err = notifySiblings(c.Request.Context(), tx, userID, notify.Notification{
+		Type:         notify.TypeTrade,
+		Title:        title,
+		AssignmentID: &assignmentID,
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send notifications", "details": err.Error()})
+		return
+	}
+
+	trade, err := trades.Load(c.Request.Context(), tx, tradeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query trade", "details": err.Error()})
+		return
+	}
+
+	if err = tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, trade)
+}
+
+// ListTrades returns open and pending trades, or ?status=completed|rejected|
+// cancelled|all. ?mine=true limits them to trades the caller offered or took.
+func ListTrades(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	statuses := []string{trades.StatusOpen, trades.StatusPendingApproval}
+	switch status := c.Query("status"); status {
+	case "":
+	case "all":
+		statuses = []string{
+			trades.StatusOpen, trades.StatusPendingApproval,
+			trades.StatusCompleted, trades.StatusRejected, trades.StatusCancelled,
+		}
+	case trades.StatusOpen, trades.StatusPendingApproval,
+		trades.StatusCompleted, trades.StatusRejected, trades.StatusCancelled:
+		statuses = []string{status}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status filter"})
+		return
+	}
+
+	var mine *uuid.UUID
+	if c.Query("mine") == "true" {
+		mine = &userID
+	}
+
+	list, err := trades.List(c.Request.Context(), db, statuses, mine)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query trades", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trades": list,
+		"count":  len(list),
+	})
+}
+
+// GetTrade returns a trade with its negotiation history
+func GetTrade(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	tradeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trade ID format"})
+		return
+	}
+
+	trade, err := trades.Load(c.Request.Context(), db, tradeID)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Trade not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query trade", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, trade)
+}
+
+// AcceptTrade takes an open trade, optionally handing back one of the
+// caller's own pending assignments. Unless the family requires a parent to
+// approve trades, the assignments change hands straight away.
+func AcceptTrade(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only children can take traded chores"})
+		return
+	}
+
+	var req models.TradeAcceptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Notes are optional
+		req = models.TradeAcceptRequest{}
+	}
+
+	tx, trade, ok := lockTrade(c, db)
+	if !ok {
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if trade.Status != trades.StatusOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot accept trade with status: %s", trade.Status)})
+		return
+	}
+	if trade.OfferedBy == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot accept your own trade"})
+		return
+	}
+
+	if req.ExchangeAssignmentID != nil {
+		if *req.ExchangeAssignmentID == trade.AssignmentID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot swap an assignment for itself"})
+			return
+		}
+
+		var (
+			assignedTo *uuid.UUID
+			status     string
+			isTeam     bool
+		)
+		err := tx.QueryRow(c.Request.Context(),
+			"SELECT assigned_to, status, is_team FROM assignments WHERE id = $1",
+			*req.ExchangeAssignmentID,
+		).Scan(&assignedTo, &status, &isTeam)
+		if err != nil {
+			if err.Error() == "no rows in result set" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Exchange assignment not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query assignment", "details": err.Error()})
+			}
+			return
+		}
+		if assignedTo == nil || *assignedTo != userID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You can only swap one of your own assignments"})
+			return
+		}
+		if isTeam {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Team chores cannot be traded"})
+			return
+		}
+		if status != lifecycle.StatusPending {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot swap assignment with status: %s", status)})
+			return
+		}
+	}
+
+	requiresApproval := database.SettingBool(c.Request.Context(), tx, "trade_requires_approval", false)
+	status := trades.StatusOpen
+	if requiresApproval {
+		status = trades.StatusPendingApproval
+	}
+
+	_, err := tx.Exec(c.Request.Context(), `
+		UPDATE assignment_trades
+		SET accepted_by = $1,
+			accepted_at = NOW(),
+			exchange_assignment_id = $2,
+			status = $3,
+			updated_at = NOW()
+		WHERE id = $4
+	`, userID, req.ExchangeAssignmentID, status, trade.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept trade", "details": err.Error()})
+		return
+	}
+	if err = trades.Record(c.Request.Context(), tx, trade.ID, trades.EventAccepted, &userID, req.Note); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record trade", "details": err.Error()})
+		return
+	}
+
+	trade.AcceptedBy = &userID
+	trade.ExchangeAssignmentID = req.ExchangeAssignmentID
+
+	if requiresApproval {
+		err = notify.Parents(c.Request.Context(), tx, notify.Notification{
+			Type:         notify.TypeTrade,
+			Title:        fmt.Sprintf("A trade of %s is waiting for approval", trade.ChoreName),
+			AssignmentID: &trade.AssignmentID,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send notifications", "details": err.Error()})
+			return
+		}
+	} else if !completeTrade(c, tx, trade, &userID) {
+		return
+	}
+
+	finishTrade(c, tx, trade.ID)
+}
+
+// ApproveTrade lets a parent sign off an accepted trade, which then completes
+func ApproveTrade(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can approve trades"})
+		return
+	}
+
+	var req models.TradeDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Notes are optional
+		req = models.TradeDecisionRequest{}
+	}
+
+	tx, trade, ok := lockTrade(c, db)
+	if !ok {
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if trade.Status != trades.StatusPendingApproval {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot approve trade with status: %s", trade.Status)})
+		return
+	}
+
+	if !decideTrade(c, tx, trade.ID, trade.Status, userID, req.Notes, trades.EventApproved) {
+		return
+	}
+	if !completeTrade(c, tx, trade, &userID) {
+		return
+	}
+
+	finishTrade(c, tx, trade.ID)
+}
+
+// RejectTrade lets a parent turn down a trade before it completes. The
+// assignments stay where they were and no sweetener is paid.
+func RejectTrade(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	isParent, _ := middleware.GetAuthIsParent(c)
+	if !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can reject trades"})
+		return
+	}
+
+	var req models.TradeDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Notes are optional
+		req = models.TradeDecisionRequest{}
+	}
+
+	tx, trade, ok := lockTrade(c, db)
+	if !ok {
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if !trades.Live(trade.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot reject trade with status: %s", trade.Status)})
+		return
+	}
+
+	if !decideTrade(c, tx, trade.ID, trades.StatusRejected, userID, req.Notes, trades.EventRejected) {
+		return
+	}
+
+	recipients := []uuid.UUID{trade.OfferedBy}
+	if trade.AcceptedBy != nil {
+		recipients = append(recipients, *trade.AcceptedBy)
+	}
+	var body string
+	if req.Notes != nil {
+		body = *req.Notes
+	}
+	for _, id := range recipients {
+		err := notify.Send(c.Request.Context(), tx, notify.Notification{
+			UserID:       id,
+			Type:         notify.TypeTrade,
+			Title:        fmt.Sprintf("The trade of %s was not approved", trade.ChoreName),
+			Body:         body,
+			AssignmentID: &trade.AssignmentID,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send notifications", "details": err.Error()})
+			return
+		}
+	}
+
+	finishTrade(c, tx, trade.ID)
+}
+
+// CancelTrade withdraws a trade before it completes (the child who offered
+// it, or a parent)
+func CancelTrade(c *gin.Context) {
+	db, ok := middleware.GetFamilyDB(c)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database connection not found"})
+		return
+	}
+
+	userID, ok := middleware.GetAuthUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	isParent, _ := middleware.GetAuthIsParent(c)
+
+	var req models.TradeDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		// Notes are optional
+		req = models.TradeDecisionRequest{}
+	}
+
+	tx, trade, ok := lockTrade(c, db)
+	if !ok {
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if trade.OfferedBy != userID && !isParent {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the child who offered the trade can cancel it"})
+		return
+	}
+	if !trades.Live(trade.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Cannot cancel trade with status: %s", trade.Status)})
+		return
+	}
+
+	_, err := tx.Exec(c.Request.Context(), `
+		UPDATE assignment_trades
+		SET status = $1,
+			updated_at = NOW()
+		WHERE id = $2
+	`, trades.StatusCancelled, trade.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel trade", "details": err.Error()})
+		return
+	}
+	if err = trades.Record(c.Request.Context(), tx, trade.ID, trades.EventCancelled, &userID, req.Notes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record trade", "details": err.Error()})
+		return
+	}
+
+	finishTrade(c, tx, trade.ID)
+}
+
+// lockTrade starts a transaction and loads the trade in :id with its row
+// locked. On failure it has already responded and rolled back.
+func lockTrade(c *gin.Context, db *pgxpool.Pool) (pgx.Tx, models.AssignmentTrade, bool) {
+	var trade models.AssignmentTrade
+
+	tradeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid trade ID format"})
+		return nil, trade, false
+	}
+
+	tx, err := db.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return nil, trade, false
+	}
+
+	_, err = tx.Exec(c.Request.Context(), "SELECT 1 FROM assignment_trades WHERE id = $1 FOR UPDATE", tradeID)
+	if err == nil {
+		trade, err = trades.Load(c.Request.Context(), tx, tradeID)
+	}
+	if err != nil {
+		tx.Rollback(c.Request.Context())
+		if err.Error() == "no rows in result set" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Trade not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query trade", "details": err.Error()})
+		}
+		return nil, trade, false
+	}
+	return tx, trade, true
+}
+
+// decideTrade records a parent's decision and moves the trade to status
+func decideTrade(c *gin.Context, tx pgx.Tx, tradeID uuid.UUID, status string, parentID uuid.UUID, notes *string, event string) bool {
+	_, err := tx.Exec(c.Request.Context(), `
+		UPDATE assignment_trades
+		SET status = $1,
+			decided_by = $2,
+			decided_at = NOW(),
+			decision_notes = $3,
+			updated_at = NOW()
+		WHERE id = $4
+	`, status, parentID, notes, tradeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update trade", "details": err.Error()})
+		return false
+	}
+	if err = trades.Record(c.Request.Context(), tx, tradeID, event, &parentID, notes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record trade", "details": err.Error()})
+		return false
+	}
+	return true
+}
+
+// completeTrade hands the assignments over and tells the child who offered
+// the trade. On failure it has already responded.
+func completeTrade(c *gin.Context, tx pgx.Tx, trade models.AssignmentTrade, actorID *uuid.UUID) bool {
+	if err := trades.Complete(c.Request.Context(), tx, trade, actorID); err != nil {
+		switch {
+		case errors.Is(err, trades.ErrStale):
+			c.JSON(http.StatusConflict, gin.H{"error": "One of the assignments is no longer available to trade"})
+		case errors.Is(err, trades.ErrInsufficientPoints):
+			c.JSON(http.StatusConflict, gin.H{"error": "The child who offered the trade no longer has enough points for the sweetener"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete trade", "details": err.Error()})
+		}
+		return false
+	}
+
+	err := notify.Send(c.Request.Context(), tx, notify.Notification{
+		UserID:       trade.OfferedBy,
+		Type:         notify.TypeTrade,
+		Title:        fmt.Sprintf("Your trade of %s went through", trade.ChoreName),
+		AssignmentID: &trade.AssignmentID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send notifications", "details": err.Error()})
+		return false
+	}
+	return true
+}
+
+// finishTrade reloads the trade, commits and responds with it
+func finishTrade(c *gin.Context, tx pgx.Tx, tradeID uuid.UUID) {
+	trade, err := trades.Load(c.Request.Context(), tx, tradeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query trade", "details": err.Error()})
+		return
+	}
+
+	if err = tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
+		return
+	}
+
+	c.JSON(http.StatusOK, trade)
+}
+
+// notifySiblings sends a copy of the notification to every active child
+// other than userID
+func notifySiblings(ctx context.Context, q database.Querier, userID uuid.UUID, n notify.Notification) error {
+	rows, err := q.Query(ctx,
+		"SELECT id FROM users WHERE is_parent = false AND is_active = true AND id <> $1",
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query children: %w", err)
+	}
+	children := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		children = append(children, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range children {
+		n.UserID = id
+		if err := notify.Send(ctx, q, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
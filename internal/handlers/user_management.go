@@ -200,6 +200,17 @@ func UpdateUser(c *gin.Context) {
 		argIndex++
 	}
 
+	// Granting auto-approval again clears any earlier automatic revocation
+	if req.AutoApproveWork != nil && *req.AutoApproveWork {
+		updates = append(updates, "trust_revoked_at = NULL", "trust_revoked_reason = NULL")
+	}
+
+	if req.TrustLevel != nil {
+		updates = append(updates, fmt.Sprintf("trust_level = $%d", argIndex))
+		args = append(args, *req.TrustLevel)
+		argIndex++
+	}
+
 	if req.PhoneNumber != nil {
 		updates = append(updates, fmt.Sprintf("phone_number = $%d", argIndex))
 		args = append(args, req.PhoneNumber)
@@ -136,6 +136,11 @@ func UpdateCurrentUserProfile(c *gin.Context) {
 		updates["usually_eats_dinner"] = *req.UsuallyEatsDinner
 	}
 	if req.AutoApproveWork != nil {
+		// Auto-approval is a trust grant, so children cannot give it to themselves
+		if isParent, _ := middleware.GetAuthIsParent(c); !isParent {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only parents can change auto-approval"})
+			return
+		}
 		updates["auto_approve_work"] = *req.AutoApproveWork
 	}
 	if req.AvailabilityNotifications != nil {
@@ -0,0 +1,108 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/allowance"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/notify"
+	"github.com/JunoAX/housepoints-go/internal/reports"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AllowanceJob pays scheduled weekly allowances due on day. A schedule with a
+// minimum completion rate is checked against the child's last full family
+// week before day, using the same figures as the weekly summary report. Work
+// waiting for a parent to verify counts as done, so a slow review never costs
+// a child their allowance.
+// Each schedule is recorded once per day in allowance_deposits, so reruns and
+// backfills never pay twice.
+type AllowanceJob struct{}
+
+// Name implements Job
+func (AllowanceJob) Name() string { return "allowance_deposits" }
+
+// Run implements Job
+func (AllowanceJob) Run(ctx context.Context, db *pgxpool.Pool, settings *models.FamilySettings, day time.Time) error {
+	due, err := allowance.DueSchedules(ctx, db, day, settings.Currency)
+	if err != nil {
+		return err
+	}
+	if len(due) == 0 {
+		return nil
+	}
+
+	weekStart := settings.WeekStart(day.AddDate(0, 0, -7))
+	children, err := reports.ChildWeekly(ctx, db, weekStart, weekStart.AddDate(0, 0, 7))
+	if err != nil {
+		return err
+	}
+	rates := make(map[string]float64, len(children))
+	for _, child := range children {
+		if child.TotalAssignments > 0 {
+			rates[child.ID] = reports.CompletionRate(child.Completed+child.AwaitingReview, child.TotalAssignments)
+		}
+	}
+
+	paid, skipped := 0, 0
+	for _, s := range due {
+		var rate *float64
+		if r, ok := rates[s.UserID.String()]; ok {
+			rate = &r
+		}
+
+		status, err := deposit(ctx, db, s, day, rate, settings.Currency)
+		if err != nil {
+			return err
+		}
+		switch status {
+		case allowance.DepositPaid:
+			paid++
+		case allowance.DepositSkipped:
+			skipped++
+		}
+	}
+
+	if paid > 0 || skipped > 0 {
+		log.Printf("💵 Allowance: %d paid, %d skipped for %s", paid, skipped, day.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// deposit pays one schedule and tells the child, in its own transaction so
+// one failure does not hold back the rest of the family
+func deposit(ctx context.Context, db *pgxpool.Pool, s models.AllowanceSchedule, day time.Time, rate *float64, currency string) (string, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := allowance.Deposit(ctx, tx, s, day, rate, currency)
+	if err != nil {
+		return "", fmt.Errorf("schedule %s: %w", s.ID, err)
+	}
+
+	n := notify.Notification{
+		UserID: s.UserID,
+		Type:   notify.TypeAllowance,
+		Title:  fmt.Sprintf("Your allowance of %s has arrived", s.AmountDisplay),
+	}
+	if status == allowance.DepositSkipped {
+		n.Title = "No allowance this week"
+		n.Body = fmt.Sprintf("Last week you completed %.1f%% of your chores; %.1f%% was needed.", *rate, *s.MinCompletionRate)
+	}
+	if status != "" {
+		if err := notify.Send(ctx, tx, n); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return status, nil
+}
@@ -0,0 +1,21 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IdempotencyCleanupJob deletes expired idempotency keys
+type IdempotencyCleanupJob struct{}
+
+// Name implements Job
+func (IdempotencyCleanupJob) Name() string { return "idempotency_cleanup" }
+
+// Run implements Job
+func (IdempotencyCleanupJob) Run(ctx context.Context, db *pgxpool.Pool, settings *models.FamilySettings, day time.Time) error {
+	_, err := db.Exec(ctx, "DELETE FROM idempotency_keys WHERE expires_at < NOW()")
+	return err
+}
@@ -0,0 +1,216 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/ledger"
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/teams"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OverdueJob marks past-due assignments as overdue, closes them as missed
+// after a grace period, and applies chore penalties to missed assignments.
+//
+// Configured through system_settings:
+//   - missed_grace_days (int, default 1): days an overdue assignment stays completable
+//   - penalties_enabled (bool, default true): apply chores.penalty_points on missed
+//
+// A missed team assignment's penalty is split across its participants the way
+// its points would have been, evenly for a manual split.
+type OverdueJob struct{}
+
+// missedAssignment is an assignment closed as missed in this run
+type missedAssignment struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	ChoreName     string
+	PenaltyPoints int
+	DueDate       time.Time
+	IsTeam        bool
+	SplitMode     *string
+}
+
+// Name implements Job
+func (OverdueJob) Name() string { return "overdue_penalties" }
+
+// Run implements Job. Everything due before day is considered past due, so
+// backfilled runs see the same state they would have seen on that day.
+func (OverdueJob) Run(ctx context.Context, db *pgxpool.Pool, settings *models.FamilySettings, day time.Time) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	graceDays := database.SettingInt(ctx, tx, "missed_grace_days", 1)
+	penaltiesEnabled := database.SettingBool(ctx, tx, "penalties_enabled", true)
+	missedBefore := day.AddDate(0, 0, -graceDays)
+
+	// Open work past its due date becomes overdue
+	overdue, err := tx.Exec(ctx, `
+		WITH due AS (
+			SELECT id, status FROM assignments
+			WHERE status IN ('pending', 'in_progress', 'needs_rework')
+				AND assigned_to IS NOT NULL
+				AND due_date < $1
+			FOR UPDATE
+		),
+		marked AS (
+			UPDATE assignments a
+			SET status = 'overdue',
+				updated_at = NOW()
+			FROM due
+			WHERE a.id = due.id
+			RETURNING a.id, due.status AS from_status
+		)
+		INSERT INTO assignment_events (id, assignment_id, event_type, from_status, to_status, created_at)
+		SELECT gen_random_uuid(), id, $2, from_status, $3, NOW()
+		FROM marked
+	`, day, lifecycle.EventOverdue, lifecycle.StatusOverdue)
+	if err != nil {
+		return fmt.Errorf("failed to mark overdue: %w", err)
+	}
+
+	// Overdue work past the grace period is closed as missed
+	rows, err := tx.Query(ctx, `
+		UPDATE assignments a
+		SET status = 'missed',
+			updated_at = NOW()
+		FROM chores c
+		WHERE a.chore_id = c.id
+			AND a.status = 'overdue'
+			AND a.due_date < $1
+		RETURNING a.id, a.assigned_to, c.name, COALESCE(c.penalty_points, 0), a.due_date,
+			a.is_team, a.split_mode
+	`, missedBefore)
+	if err != nil {
+		return fmt.Errorf("failed to mark missed: %w", err)
+	}
+
+	missed := []missedAssignment{}
+	for rows.Next() {
+		var m missedAssignment
+		if err := rows.Scan(&m.ID, &m.UserID, &m.ChoreName, &m.PenaltyPoints, &m.DueDate, &m.IsTeam, &m.SplitMode); err != nil {
+			rows.Close()
+			return err
+		}
+		missed = append(missed, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	penalties := 0
+	for _, m := range missed {
+		delta := 0
+		if penaltiesEnabled && m.PenaltyPoints > 0 {
+			applied, err := applyPenalty(ctx, tx, m, settings, day)
+			if err != nil {
+				return err
+			}
+			if applied {
+				delta = -m.PenaltyPoints
+				penalties++
+			}
+		}
+
+		err := lifecycle.Record(ctx, tx, lifecycle.Change{
+			AssignmentID: m.ID,
+			Event:        lifecycle.EventMissed,
+			From:         lifecycle.StatusOverdue,
+			To:           lifecycle.StatusMissed,
+			PointsDelta:  delta,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if overdue.RowsAffected() > 0 || len(missed) > 0 {
+		log.Printf("⏰ Overdue: %d overdue, %d missed, %d penalties for %s",
+			overdue.RowsAffected(), len(missed), penalties, day.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// applyPenalty records the chore's penalty for a missed assignment, split
+// across the team for a team assignment. It reports false if the assignment
+// was already penalized.
+func applyPenalty(ctx context.Context, q database.Querier, m missedAssignment, settings *models.FamilySettings, day time.Time) (bool, error) {
+	// One penalty per assignment, no matter how often the job runs
+	var penalized bool
+	err := q.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM point_transactions
+			WHERE related_assignment_id = $1 AND transaction_type = $2
+		)
+	`, m.ID, ledger.TypePenalty).Scan(&penalized)
+	if err != nil {
+		return false, fmt.Errorf("failed to check penalty: %w", err)
+	}
+	if penalized {
+		return false, nil
+	}
+
+	shares := map[uuid.UUID]int{m.UserID: m.PenaltyPoints}
+	participants := []uuid.UUID{m.UserID}
+	if m.IsTeam {
+		team, err := teams.Load(ctx, q, m.ID)
+		if err != nil {
+			return false, err
+		}
+		if len(team) > 0 {
+			mode := teams.SplitEven
+			if m.SplitMode != nil && *m.SplitMode == teams.SplitWeighted {
+				mode = teams.SplitWeighted
+			}
+			weights, err := teams.Weights(mode, team, nil)
+			if err != nil {
+				return false, err
+			}
+			participants = participants[:0]
+			for i, points := range teams.Split(m.PenaltyPoints, weights) {
+				participants = append(participants, team[i].UserID)
+				shares[team[i].UserID] = points
+			}
+		}
+	}
+
+	for _, userID := range participants {
+		if shares[userID] <= 0 {
+			continue
+		}
+		extra := map[string]interface{}{
+			"reason":   "missed",
+			"due_date": m.DueDate.In(settings.Location()).Format("2006-01-02"),
+			"run_date": day.Format("2006-01-02"),
+		}
+		if m.IsTeam {
+			extra["team_penalty"] = m.PenaltyPoints
+		}
+		_, err = ledger.Post(ctx, q, ledger.Entry{
+			UserID:       userID,
+			Points:       -shares[userID],
+			Type:         ledger.TypePenalty,
+			Description:  fmt.Sprintf("Missed chore: %s", m.ChoreName),
+			AssignmentID: &m.ID,
+			ExtraData:    extra,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to apply penalty: %w", err)
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/schedule"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// presenceLookaheadDays is how far ahead absences are reconciled each day
+const presenceLookaheadDays = 7
+
+// PresenceJob reassigns or defers upcoming assignments for children who are
+// away according to the family schedule
+type PresenceJob struct{}
+
+// Name implements Job
+func (PresenceJob) Name() string { return "presence_reconcile" }
+
+// RepeatsToday implements Repeating, so assignments created after the day's
+// first run, including ones due today, are still reconciled
+func (PresenceJob) RepeatsToday() bool { return true }
+
+// Run implements Job
+func (PresenceJob) Run(ctx context.Context, db *pgxpool.Pool, settings *models.FamilySettings, day time.Time) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	adjustments, err := schedule.ReconcileAbsences(ctx, tx, day, day.AddDate(0, 0, presenceLookaheadDays), settings.Location(), nil, false)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if len(adjustments) > 0 {
+		log.Printf("📅 Presence: adjusted %d assignments from %s", len(adjustments), day.Format("2006-01-02"))
+	}
+	return nil
+}
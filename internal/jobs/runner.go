@@ -0,0 +1,227 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxBackfillDays caps how many missed days a single pass will catch up on
+const MaxBackfillDays = 31
+
+// Job is a unit of per-family work that runs once per family-local day.
+// day is midnight of that day in the family's timezone.
+type Job interface {
+	Name() string
+	Run(ctx context.Context, db *pgxpool.Pool, settings *models.FamilySettings, day time.Time) error
+}
+
+// Repeating is implemented by jobs that run again for today on every pass,
+// because the work they look for can appear after the day's first run. They
+// must be idempotent.
+type Repeating interface {
+	RepeatsToday() bool
+}
+
+// Runner periodically runs daily jobs for every active family
+type Runner struct {
+	platformDB *database.PlatformDB
+	familyDBs  *database.FamilyDBManager
+	jobs       []Job
+	interval   time.Duration
+}
+
+// NewRunner creates a runner for the given jobs
+func NewRunner(platformDB *database.PlatformDB, familyDBs *database.FamilyDBManager, jobs ...Job) *Runner {
+	return &Runner{
+		platformDB: platformDB,
+		familyDBs:  familyDBs,
+		jobs:       jobs,
+		interval:   15 * time.Minute,
+	}
+}
+
+// Start runs a pass immediately and then on every interval until ctx is done
+func (r *Runner) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Lookup returns the registered job with the given name
+func (r *Runner) Lookup(name string) (Job, bool) {
+	for _, job := range r.jobs {
+		if job.Name() == name {
+			return job, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of all registered jobs
+func (r *Runner) Names() []string {
+	names := make([]string, 0, len(r.jobs))
+	for _, job := range r.jobs {
+		names = append(names, job.Name())
+	}
+	return names
+}
+
+// Backfill runs a job for every day from start to end inclusive, in order,
+// interpreting the dates in the family's timezone
+func (r *Runner) Backfill(ctx context.Context, db *pgxpool.Pool, familyID uuid.UUID, job Job, start, end time.Time, force bool) ([]string, error) {
+	settings, err := r.platformDB.GetFamilySettings(ctx, familyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load family settings: %w", err)
+	}
+
+	loc := settings.Location()
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, loc)
+
+	days := []string{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if err := RunForDay(ctx, db, settings, job, day, force); err != nil {
+			return days, fmt.Errorf("%s: %w", day.Format("2006-01-02"), err)
+		}
+		days = append(days, day.Format("2006-01-02"))
+	}
+	return days, nil
+}
+
+// RunOnce runs every job for every active family, catching up on missed days
+func (r *Runner) RunOnce(ctx context.Context) {
+	families, err := r.platformDB.ListActiveFamilies(ctx)
+	if err != nil {
+		log.Printf("❌ Jobs: failed to list families: %v", err)
+		return
+	}
+
+	for _, family := range families {
+		if ctx.Err() != nil {
+			return
+		}
+
+		db, err := r.familyDBs.GetFamilyDB(ctx, family)
+		if err != nil {
+			log.Printf("❌ Jobs: failed to connect to %s: %v", family.Slug, err)
+			continue
+		}
+
+		settings, err := r.platformDB.GetFamilySettings(ctx, family.ID)
+		if err != nil {
+			log.Printf("❌ Jobs: failed to load settings for %s: %v", family.Slug, err)
+			continue
+		}
+
+		today := LocalDay(time.Now(), settings.Location())
+		for _, job := range r.jobs {
+			if err := CatchUp(ctx, db, settings, job, today); err != nil {
+				log.Printf("❌ Jobs: %s failed for %s: %v", job.Name(), family.Slug, err)
+				continue
+			}
+			if rep, ok := job.(Repeating); ok && rep.RepeatsToday() {
+				if err := RunForDay(ctx, db, settings, job, today, true); err != nil {
+					log.Printf("❌ Jobs: %s failed for %s: %v", job.Name(), family.Slug, err)
+				}
+			}
+		}
+	}
+}
+
+// CatchUp runs a job for every day since its last completed run, up to and
+// including today. A job that has never run only runs for today.
+func CatchUp(ctx context.Context, db *pgxpool.Pool, settings *models.FamilySettings, job Job, today time.Time) error {
+	var lastRun *time.Time
+	err := db.QueryRow(ctx, `
+		SELECT MAX(run_date) FROM family_job_runs
+		WHERE job_name = $1 AND status = 'completed'
+	`, job.Name()).Scan(&lastRun)
+	if err != nil {
+		return fmt.Errorf("failed to query last run: %w", err)
+	}
+
+	start := today
+	if lastRun != nil {
+		last := time.Date(lastRun.Year(), lastRun.Month(), lastRun.Day(), 0, 0, 0, 0, today.Location())
+		start = last.AddDate(0, 0, 1)
+		if earliest := today.AddDate(0, 0, -MaxBackfillDays); start.Before(earliest) {
+			start = earliest
+		}
+	}
+
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if err := RunForDay(ctx, db, settings, job, day, false); err != nil {
+			return fmt.Errorf("%s: %w", day.Format("2006-01-02"), err)
+		}
+	}
+	return nil
+}
+
+// RunForDay runs a job for a single day unless it already completed. With
+// force, a completed day is run again; jobs must be idempotent for this.
+func RunForDay(ctx context.Context, db *pgxpool.Pool, settings *models.FamilySettings, job Job, day time.Time, force bool) error {
+	runDate := day.Format("2006-01-02")
+
+	// Claim the day. Failed or stale runs can be retried; completed runs only
+	// when forced.
+	var claimed string
+	err := db.QueryRow(ctx, `
+		INSERT INTO family_job_runs (job_name, run_date, status, started_at)
+		VALUES ($1, $2, 'running', NOW())
+		ON CONFLICT (job_name, run_date) DO UPDATE
+		SET status = 'running', started_at = NOW(), completed_at = NULL, error = NULL
+		WHERE family_job_runs.status = 'failed'
+			OR (family_job_runs.status = 'running' AND family_job_runs.started_at < NOW() - INTERVAL '1 hour')
+			OR (family_job_runs.status = 'completed' AND $3::boolean)
+		RETURNING job_name
+	`, job.Name(), runDate, force).Scan(&claimed)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			// Already done or being run elsewhere
+			return nil
+		}
+		return fmt.Errorf("failed to claim run: %w", err)
+	}
+
+	runErr := job.Run(ctx, db, settings, day)
+
+	status, errText := "completed", (*string)(nil)
+	if runErr != nil {
+		status = "failed"
+		msg := runErr.Error()
+		errText = &msg
+	}
+
+	_, err = db.Exec(ctx, `
+		UPDATE family_job_runs
+		SET status = $1, completed_at = NOW(), error = $2
+		WHERE job_name = $3 AND run_date = $4
+	`, status, errText, job.Name(), runDate)
+	if err != nil {
+		return fmt.Errorf("failed to record run: %w", err)
+	}
+
+	return runErr
+}
+
+// LocalDay returns midnight of t's calendar day in loc
+func LocalDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
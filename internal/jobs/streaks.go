@@ -0,0 +1,131 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// streakLookbackDays bounds how far back completion history is scanned
+const streakLookbackDays = 365
+
+// StreakJob recomputes users.streak_days from completion history
+type StreakJob struct{}
+
+// Name implements Job
+func (StreakJob) Name() string { return "streaks" }
+
+// Run implements Job. A streak is the number of consecutive family-local days
+// ending yesterday with at least one completed chore. Days on which a child
+// had nothing due (for example when away) neither count nor break the
+// streak. Completions on day itself count when a run is forced later that day.
+func (StreakJob) Run(ctx context.Context, db *pgxpool.Pool, settings *models.FamilySettings, day time.Time) error {
+	tz := settings.Location().String()
+	since := day.AddDate(0, 0, -streakLookbackDays)
+
+	completedDays, err := userDays(ctx, db, `
+		SELECT assigned_to, (completed_at AT TIME ZONE $1)::date
+		FROM assignments
+		WHERE assigned_to IS NOT NULL
+			AND completed_at IS NOT NULL
+			AND status IN ('completed', 'pending_verification', 'verified')
+			AND completed_at >= $2 AND completed_at < $3
+		GROUP BY 1, 2
+	`, tz, since, day.AddDate(0, 0, 1))
+	if err != nil {
+		return fmt.Errorf("failed to query completions: %w", err)
+	}
+
+	dueDays, err := userDays(ctx, db, `
+		SELECT assigned_to, (due_date AT TIME ZONE $1)::date
+		FROM assignments
+		WHERE assigned_to IS NOT NULL
+			AND due_date >= $2 AND due_date < $3
+			AND status NOT IN ('cancelled', 'skipped')
+		GROUP BY 1, 2
+	`, tz, since, day)
+	if err != nil {
+		return fmt.Errorf("failed to query due dates: %w", err)
+	}
+
+	rows, err := db.Query(ctx, "SELECT id FROM users WHERE is_parent = false AND is_active = true")
+	if err != nil {
+		return err
+	}
+	children := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		children = append(children, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, userID := range children {
+		streak := streakFor(completedDays[userID], dueDays[userID], day)
+		_, err := db.Exec(ctx, `
+			UPDATE users
+			SET streak_days = $1,
+				updated_at = NOW()
+			WHERE id = $2 AND streak_days <> $1
+		`, streak, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update streak: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// streakFor walks back from the day before today counting completion days
+func streakFor(completed, due map[string]bool, today time.Time) int {
+	streak := 0
+	if completed[today.Format("2006-01-02")] {
+		streak++
+	}
+
+	for i := 1; i <= streakLookbackDays; i++ {
+		key := today.AddDate(0, 0, -i).Format("2006-01-02")
+		switch {
+		case completed[key]:
+			streak++
+		case due[key]:
+			return streak
+		}
+	}
+	return streak
+}
+
+// userDays runs a query returning (user_id, date) pairs and groups them by user
+func userDays(ctx context.Context, db *pgxpool.Pool, query string, args ...interface{}) (map[uuid.UUID]map[string]bool, error) {
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := map[uuid.UUID]map[string]bool{}
+	for rows.Next() {
+		var (
+			userID uuid.UUID
+			date   time.Time
+		)
+		if err := rows.Scan(&userID, &date); err != nil {
+			return nil, err
+		}
+		if days[userID] == nil {
+			days[userID] = map[string]bool{}
+		}
+		days[userID][date.Format("2006-01-02")] = true
+	}
+	return days, rows.Err()
+}
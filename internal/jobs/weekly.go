@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/ledger"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WeeklyTransactionTypes are the point transaction types that count towards
+// users.weekly_points
+var WeeklyTransactionTypes = ledger.WeeklyTypes
+
+// WeeklyResetJob snapshots the previous week's standings into
+// weekly_standings and resets users.weekly_points for the current week.
+//
+// Standings are computed from point_transactions rather than the running
+// counter, so a late or backfilled rollover still records the right totals
+// and carries over points already earned in the new week.
+type WeeklyResetJob struct{}
+
+// Name implements Job
+func (WeeklyResetJob) Name() string { return "weekly_reset" }
+
+// Run implements Job
+func (WeeklyResetJob) Run(ctx context.Context, db *pgxpool.Pool, settings *models.FamilySettings, day time.Time) error {
+	weekStart := settings.WeekStart(day)
+	prevStart := weekStart.AddDate(0, 0, -7)
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialize rollovers so two runners cannot snapshot the same week
+	if _, err := tx.Exec(ctx, "LOCK TABLE weekly_standings IN SHARE ROW EXCLUSIVE MODE"); err != nil {
+		return err
+	}
+
+	var snapshotted bool
+	err = tx.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM weekly_standings WHERE week_start = $1)",
+		prevStart.Format("2006-01-02"),
+	).Scan(&snapshotted)
+	if err != nil {
+		return fmt.Errorf("failed to check standings: %w", err)
+	}
+
+	standings := int64(0)
+	if !snapshotted {
+		result, err := tx.Exec(ctx, `
+			WITH totals AS (
+				SELECT
+					u.id AS user_id,
+					COALESCE((
+						SELECT SUM(pt.points) FROM point_transactions pt
+						WHERE pt.user_id = u.id
+							AND pt.transaction_type = ANY($3)
+							AND pt.created_at >= $1 AND pt.created_at < $2
+					), 0) AS points,
+					(
+						SELECT COUNT(*) FROM assignments a
+						WHERE a.assigned_to = u.id
+							AND a.status IN ('completed', 'verified')
+							AND a.completed_at >= $1 AND a.completed_at < $2
+					) AS chores_completed
+				FROM users u
+				WHERE u.is_parent = false AND u.is_active = true
+			),
+			ranked AS (
+				SELECT *, RANK() OVER (ORDER BY points DESC) AS rank
+				FROM totals
+			)
+			INSERT INTO weekly_standings (
+				week_start, week_end, user_id, rank, points, chores_completed, is_winner
+			)
+			SELECT $4, $5, user_id, rank, points, chores_completed, rank = 1 AND points > 0
+			FROM ranked
+			ON CONFLICT (week_start, user_id) DO NOTHING
+		`, prevStart, weekStart, WeeklyTransactionTypes,
+			prevStart.Format("2006-01-02"), weekStart.AddDate(0, 0, -1).Format("2006-01-02"))
+		if err != nil {
+			return fmt.Errorf("failed to snapshot standings: %w", err)
+		}
+		standings = result.RowsAffected()
+	}
+
+	// Recompute rather than zero so points earned since the week began survive.
+	// Always for the current week, whatever day this run is for, so a backfill
+	// of an old week cannot overwrite this week's points. Parents too, so every
+	// cached balance matches the ledger.
+	currentStart := settings.WeekStart(LocalDay(time.Now(), settings.Location()))
+	_, err = tx.Exec(ctx, `
+		UPDATE users u
+		SET weekly_points = COALESCE((
+				SELECT SUM(pt.points) FROM point_transactions pt
+				WHERE pt.user_id = u.id
+					AND pt.transaction_type = ANY($2)
+					AND pt.created_at >= $1
+			), 0),
+			updated_at = NOW()
+	`, currentStart, WeeklyTransactionTypes)
+	if err != nil {
+		return fmt.Errorf("failed to reset weekly points: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if standings > 0 {
+		log.Printf("🏆 Weekly: recorded %d standings for week of %s", standings, prevStart.Format("2006-01-02"))
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+// Package ledger makes point_transactions the source of truth for point
+// balances. Every change to a balance is posted as a transaction, and the
+// balance columns on users are a cache of sums over the ledger that can be
+// checked and rebuilt at any time.
+package ledger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// Transaction types written by the server
+const (
+	TypeChoreCompletion  = "chore_completion"
+	TypeQualityBonus     = "quality_bonus"
+	TypeEarlyBonus       = "early_bonus"
+	TypeEffortBonus      = "effort_bonus"
+	TypePenalty          = "penalty"
+	TypeRewardRedemption = "reward_redemption"
+	TypeTradeSweetener   = "trade_sweetener"
+	TypeConversion       = "conversion"
+	TypeAdjustment       = "adjustment"
+	TypeAllowance        = "allowance"
+)
+
+// ErrInsufficientPoints is returned when a NoOverdraft entry would take
+// available points below what pending conversions hold
+var ErrInsufficientPoints = errors.New("not enough available points")
+
+// effect is which cached balances a transaction type moves. Every type moves
+// available_points.
+type effect struct {
+	total     bool // total_points: points kept, not spent
+	weekly    bool // weekly_points: counts towards the weekly leaderboard
+	lifetime  bool // lifetime_points_earned: earned from chores
+	converted bool // total_points_converted: turned into money
+}
+
+// effects maps each known type to its balances. Types not listed here, such
+// as those written by older versions, count towards total and available.
+var effects = map[string]effect{
+	TypeChoreCompletion:  {total: true, weekly: true, lifetime: true},
+	TypeQualityBonus:     {total: true, weekly: true, lifetime: true},
+	TypeEarlyBonus:       {total: true, weekly: true, lifetime: true},
+	TypeEffortBonus:      {total: true, weekly: true, lifetime: true},
+	TypePenalty:          {total: true, weekly: true},
+	TypeRewardRedemption: {},
+	TypeTradeSweetener:   {},
+	TypeConversion:       {converted: true},
+	TypeAdjustment:       {total: true},
+	TypeAllowance:        {total: true},
+}
+
+func effectOf(transactionType string) effect {
+	if e, ok := effects[transactionType]; ok {
+		return e
+	}
+	return effect{total: true}
+}
+
+// typesWhere returns the known types whose effect matches, sorted
+func typesWhere(match func(effect) bool) []string {
+	types := []string{}
+	for t, e := range effects {
+		if match(e) {
+			types = append(types, t)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// WeeklyTypes are the transaction types that count towards weekly_points
+var WeeklyTypes = typesWhere(func(e effect) bool { return e.weekly })
+
+// Entry is one transaction to post
+type Entry struct {
+	UserID        uuid.UUID
+	Points        int // Positive for earned, negative for spent
+	Type          string
+	Description   string
+	AssignmentID  *uuid.UUID
+	RelatedUserID *uuid.UUID
+	ExtraData     map[string]interface{}
+	// Reverses is the transaction this one reverses
+	Reverses *uuid.UUID
+	// NoOverdraft fails a negative entry with ErrInsufficientPoints instead
+	// of spending points held by pending conversions or going below zero
+	NoOverdraft bool
+	// Completes is the pending conversion this entry carries out. Its own
+	// hold is not counted against it.
+	Completes *uuid.UUID
+}
+
+// Post writes a transaction and moves the user's cached balances by the same
+// amount. Run it in the caller's transaction so the two cannot drift apart,
+// and roll back on error. The transaction is written first so a posting waits
+// behind a running repair rather than deadlocking with it.
+func Post(ctx context.Context, q database.Querier, e Entry) (uuid.UUID, error) {
+	eff := effectOf(e.Type)
+
+	var extraData []byte
+	if e.ExtraData != nil {
+		data, err := json.Marshal(e.ExtraData)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("failed to encode extra data: %w", err)
+		}
+		extraData = data
+	}
+
+	delta := func(applies bool) int {
+		if applies {
+			return e.Points
+		}
+		return 0
+	}
+	converted := 0
+	if eff.converted {
+		converted = -e.Points
+	}
+
+	id := uuid.New()
+	_, err := q.Exec(ctx, `
+		INSERT INTO point_transactions (
+			id, user_id, points, transaction_type, description,
+			related_assignment_id, related_user_id, extra_data, reverses_transaction_id, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+	`, id, e.UserID, e.Points, e.Type, e.Description, e.AssignmentID, e.RelatedUserID, extraData, e.Reverses)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create %s transaction: %w", e.Type, err)
+	}
+
+	result, err := q.Exec(ctx, `
+		UPDATE users
+		SET total_points = total_points + $1,
+			available_points = available_points + $2,
+			weekly_points = weekly_points + $3,
+			lifetime_points_earned = lifetime_points_earned + $4,
+			total_points_converted = total_points_converted + $5,
+			updated_at = NOW()
+		WHERE id = $6
+			AND (NOT $7::boolean OR $2 >= 0 OR available_points + $2 >= (
+				SELECT COALESCE(SUM(points), 0)
+				FROM allowance_conversions
+				WHERE user_id = $6 AND status = 'pending' AND id IS DISTINCT FROM $8
+			))
+	`, delta(eff.total), e.Points, delta(eff.weekly), delta(eff.lifetime), converted, e.UserID, e.NoOverdraft, e.Completes)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to update balances: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		if e.NoOverdraft {
+			var exists bool
+			err := q.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", e.UserID).Scan(&exists)
+			if err == nil && exists {
+				return uuid.Nil, ErrInsufficientPoints
+			}
+		}
+		return uuid.Nil, fmt.Errorf("user %s not found", e.UserID)
+	}
+	return id, nil
+}
+
+// Balances are a user's balances as the ledger sums them
+type Balances struct {
+	TotalPoints          int
+	AvailablePoints      int
+	WeeklyPoints         int
+	LifetimePointsEarned int
+	TotalPointsConverted int
+}
+
+// Reconcile compares every user's cached balances, or only userID's, with the
+// ledger and reports each column that differs. weekStart is the start of the
+// family's current week. With repair the cached columns are overwritten with
+// the ledger's values and read back; a row that still differs is an error.
+// The ledger is locked against new postings meanwhile, so run it in a
+// transaction.
+func Reconcile(ctx context.Context, q database.Querier, weekStart time.Time, userID *uuid.UUID, repair bool) (models.BalanceReconcileResponse, error) {
+	report := models.BalanceReconcileResponse{Discrepancies: []models.BalanceDiscrepancy{}}
+
+	if repair {
+		if _, err := q.Exec(ctx, "LOCK TABLE point_transactions IN SHARE MODE"); err != nil {
+			return report, fmt.Errorf("failed to lock ledger: %w", err)
+		}
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT
+			u.id, u.username,
+			u.total_points, u.available_points, u.weekly_points,
+			u.lifetime_points_earned, u.total_points_converted,
+			COALESCE(SUM(pt.points) FILTER (WHERE pt.transaction_type <> ALL($1)), 0),
+			COALESCE(SUM(pt.points), 0),
+			COALESCE(SUM(pt.points) FILTER (WHERE pt.transaction_type = ANY($2) AND pt.created_at >= $3), 0),
+			COALESCE(SUM(pt.points) FILTER (WHERE pt.transaction_type = ANY($4)), 0),
+			COALESCE(-SUM(pt.points) FILTER (WHERE pt.transaction_type = ANY($5)), 0)
+		FROM users u
+		LEFT JOIN point_transactions pt ON pt.user_id = u.id
+		WHERE $6::uuid IS NULL OR u.id = $6
+		GROUP BY u.id
+		ORDER BY u.username
+	`,
+		typesWhere(func(e effect) bool { return !e.total }),
+		WeeklyTypes,
+		weekStart,
+		typesWhere(func(e effect) bool { return e.lifetime }),
+		typesWhere(func(e effect) bool { return e.converted }),
+		userID,
+	)
+	if err != nil {
+		return report, fmt.Errorf("failed to sum ledger: %w", err)
+	}
+
+	type drift struct {
+		id      uuid.UUID
+		derived Balances
+	}
+	drifted := []drift{}
+	for rows.Next() {
+		var (
+			id              uuid.UUID
+			username        string
+			cached, derived Balances
+		)
+		err := rows.Scan(&id, &username,
+			&cached.TotalPoints, &cached.AvailablePoints, &cached.WeeklyPoints,
+			&cached.LifetimePointsEarned, &cached.TotalPointsConverted,
+			&derived.TotalPoints, &derived.AvailablePoints, &derived.WeeklyPoints,
+			&derived.LifetimePointsEarned, &derived.TotalPointsConverted,
+		)
+		if err != nil {
+			rows.Close()
+			return report, fmt.Errorf("failed to parse balances: %w", err)
+		}
+		report.UsersChecked++
+
+		found := compare(id, username, cached, derived)
+		if len(found) > 0 {
+			report.Discrepancies = append(report.Discrepancies, found...)
+			drifted = append(drifted, drift{id, derived})
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return report, fmt.Errorf("failed to sum ledger: %w", err)
+	}
+
+	if repair {
+		for _, d := range drifted {
+			_, err := q.Exec(ctx, `
+				UPDATE users
+				SET total_points = $1,
+					available_points = $2,
+					weekly_points = $3,
+					lifetime_points_earned = $4,
+					total_points_converted = $5,
+					updated_at = NOW()
+				WHERE id = $6
+			`, d.derived.TotalPoints, d.derived.AvailablePoints, d.derived.WeeklyPoints,
+				d.derived.LifetimePointsEarned, d.derived.TotalPointsConverted, d.id)
+			if err != nil {
+				return report, fmt.Errorf("failed to repair balances: %w", err)
+			}
+
+			// Read the row back: a trigger on users could have rewritten it
+			var stored Balances
+			err = q.QueryRow(ctx, `
+				SELECT total_points, available_points, weekly_points,
+					lifetime_points_earned, total_points_converted
+				FROM users
+				WHERE id = $1
+			`, d.id).Scan(&stored.TotalPoints, &stored.AvailablePoints, &stored.WeeklyPoints,
+				&stored.LifetimePointsEarned, &stored.TotalPointsConverted)
+			if err != nil {
+				return report, fmt.Errorf("failed to verify repaired balances: %w", err)
+			}
+			if left := compare(d.id, "", stored, d.derived); len(left) > 0 {
+				return report, fmt.Errorf("balances for user %s still differ from the ledger after repair (%s: %d, ledger %d)",
+					d.id, left[0].Field, left[0].Cached, left[0].Ledger)
+			}
+		}
+	}
+
+	report.UsersDrifted = len(drifted)
+	report.Repaired = repair && len(drifted) > 0
+	return report, nil
+}
+
+// compare reports each balance column where cached differs from derived
+func compare(id uuid.UUID, username string, cached, derived Balances) []models.BalanceDiscrepancy {
+	fields := []struct {
+		name            string
+		cached, derived int
+	}{
+		{"total_points", cached.TotalPoints, derived.TotalPoints},
+		{"available_points", cached.AvailablePoints, derived.AvailablePoints},
+		{"weekly_points", cached.WeeklyPoints, derived.WeeklyPoints},
+		{"lifetime_points_earned", cached.LifetimePointsEarned, derived.LifetimePointsEarned},
+		{"total_points_converted", cached.TotalPointsConverted, derived.TotalPointsConverted},
+	}
+
+	found := []models.BalanceDiscrepancy{}
+	for _, f := range fields {
+		if f.cached != f.derived {
+			found = append(found, models.BalanceDiscrepancy{
+				UserID:     id,
+				Username:   username,
+				Field:      f.name,
+				Cached:     f.cached,
+				Ledger:     f.derived,
+				Difference: f.cached - f.derived,
+			})
+		}
+	}
+	return found
+}
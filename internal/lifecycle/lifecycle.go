@@ -0,0 +1,218 @@
+// Package lifecycle defines the assignment state machine and records every
+// status change in assignment_events.
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/google/uuid"
+)
+
+// Assignment statuses
+const (
+	StatusOpen                = "open"
+	StatusPending             = "pending"
+	StatusInProgress          = "in_progress"
+	StatusPendingVerification = "pending_verification"
+	StatusCompleted           = "completed" // legacy, treated like pending_verification
+	StatusVerified            = "verified"
+	StatusNeedsRework         = "needs_rework"
+	StatusOverdue             = "overdue"
+	StatusMissed              = "missed"
+	StatusSkipped             = "skipped"
+	StatusCancelled           = "cancelled"
+)
+
+// Event types recorded in assignment_events
+const (
+	EventCreated    = "created"
+	EventClaimed    = "claimed"
+	EventStarted    = "started"
+	EventSubmitted  = "submitted"
+	EventVerified   = "verified"
+	EventRejected   = "rejected"
+	EventOverdue    = "overdue"
+	EventMissed     = "missed"
+	EventSkipped    = "skipped"
+	EventCancelled  = "cancelled"
+	EventReassigned = "reassigned"
+	EventDeferred   = "deferred"
+	// EventParticipantDone marks one member of a team finishing their part
+	EventParticipantDone = "participant_completed"
+	// Timer events leave the status alone
+	EventPaused  = "paused"
+	EventResumed = "resumed"
+	EventStopped = "stopped"
+	// EventTradeOffered marks an assignment put up for trade by its assignee
+	EventTradeOffered = "trade_offered"
+	// EventVerificationReversed marks a verification undone and its points
+	// taken back
+	EventVerificationReversed = "verification_reversed"
+)
+
+// transitions lists the statuses reachable from each status. Terminal
+// statuses have no entry.
+var transitions = map[string][]string{
+	StatusOpen:                {StatusPending, StatusSkipped, StatusCancelled},
+	StatusPending:             {StatusInProgress, StatusPendingVerification, StatusVerified, StatusOverdue, StatusSkipped, StatusCancelled},
+	StatusInProgress:          {StatusPending, StatusPendingVerification, StatusVerified, StatusOverdue, StatusSkipped, StatusCancelled},
+	StatusNeedsRework:         {StatusInProgress, StatusPendingVerification, StatusVerified, StatusOverdue, StatusSkipped, StatusCancelled},
+	StatusOverdue:             {StatusInProgress, StatusPendingVerification, StatusVerified, StatusMissed, StatusSkipped, StatusCancelled},
+	StatusPendingVerification: {StatusVerified, StatusNeedsRework, StatusCancelled},
+	StatusCompleted:           {StatusVerified, StatusNeedsRework, StatusCancelled},
+}
+
+// ErrStatusChanged is returned when the assignment no longer has the status
+// the caller read, usually because of a concurrent update
+var ErrStatusChanged = errors.New("assignment status changed")
+
+// ErrAssigneeChanged is returned when the assignment is no longer assigned to
+// the user the caller read
+var ErrAssigneeChanged = errors.New("assignment assignee changed")
+
+// TransitionError is returned for a transition the state machine does not allow
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("cannot move assignment from %s to %s", e.From, e.To)
+}
+
+// CanTransition reports whether an assignment may move from one status to another
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether no further transitions are possible
+func IsTerminal(status string) bool {
+	return len(transitions[status]) == 0
+}
+
+// Change describes a status transition or, with To empty, an event that
+// leaves the status alone (reassignment, deferral)
+type Change struct {
+	AssignmentID uuid.UUID
+	Event        string
+	From         string // Status the caller read
+	To           string
+	ActorID      *uuid.UUID // nil for system jobs
+	Notes        *string
+	PointsDelta  int
+	Metadata     map[string]interface{}
+}
+
+// Apply validates a transition, moves the assignment to the new status if it
+// is still in the expected one, and records the event. Other columns are the
+// caller's to update in the same transaction.
+func Apply(ctx context.Context, q database.Querier, change Change) error {
+	if !CanTransition(change.From, change.To) {
+		return &TransitionError{From: change.From, To: change.To}
+	}
+
+	result, err := q.Exec(ctx, `
+		UPDATE assignments
+		SET status = $1,
+			updated_at = NOW()
+		WHERE id = $2 AND status = $3
+	`, change.To, change.AssignmentID, change.From)
+	if err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrStatusChanged
+	}
+
+	return Record(ctx, q, change)
+}
+
+// ReverseVerification moves a verified assignment back to
+// pending_verification, if it is still verified, and records the event.
+// Verified is otherwise terminal, so this is deliberately not a transition
+// that Apply allows. The change's Event, From and To are set here.
+func ReverseVerification(ctx context.Context, q database.Querier, change Change) error {
+	result, err := q.Exec(ctx, `
+		UPDATE assignments
+		SET status = $1,
+			updated_at = NOW()
+		WHERE id = $2 AND status = $3
+	`, StatusPendingVerification, change.AssignmentID, StatusVerified)
+	if err != nil {
+		return fmt.Errorf("failed to update status: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrStatusChanged
+	}
+
+	change.Event = EventVerificationReversed
+	change.From = StatusVerified
+	change.To = StatusPendingVerification
+	return Record(ctx, q, change)
+}
+
+// Reassign hands an assignment to another user if it is still assigned to
+// from, and records a reassigned event. The change's Event is set here;
+// its Metadata gains the from and to users.
+func Reassign(ctx context.Context, q database.Querier, change Change, from *uuid.UUID, to uuid.UUID) error {
+	result, err := q.Exec(ctx, `
+		UPDATE assignments
+		SET assigned_to = $1,
+			updated_at = NOW()
+		WHERE id = $2 AND assigned_to IS NOT DISTINCT FROM $3
+	`, to, change.AssignmentID, from)
+	if err != nil {
+		return fmt.Errorf("failed to reassign assignment: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrAssigneeChanged
+	}
+
+	metadata := map[string]interface{}{"from_user_id": from, "to_user_id": to}
+	for k, v := range change.Metadata {
+		metadata[k] = v
+	}
+	change.Event = EventReassigned
+	change.To = ""
+	change.Metadata = metadata
+	return Record(ctx, q, change)
+}
+
+// Record writes an event without changing the assignment
+func Record(ctx context.Context, q database.Querier, change Change) error {
+	var from, to *string
+	if change.From != "" {
+		from = &change.From
+	}
+	if change.To != "" {
+		to = &change.To
+	} else {
+		to = from
+	}
+
+	var metadata []byte
+	if len(change.Metadata) > 0 {
+		metadata, _ = json.Marshal(change.Metadata)
+	}
+
+	_, err := q.Exec(ctx, `
+		INSERT INTO assignment_events (
+			id, assignment_id, event_type, from_status, to_status,
+			actor_id, notes, points_delta, metadata, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+	`, uuid.New(), change.AssignmentID, change.Event, from, to,
+		change.ActorID, change.Notes, change.PointsDelta, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to record assignment event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the client's key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotencyTTL is how long a key and its response are kept
+	IdempotencyTTL = 24 * time.Hour
+
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodyBytes caps the request body read for hashing; it
+	// leaves room for a completion carrying before and after photos
+	maxIdempotentBodyBytes = 21 << 20
+)
+
+// replayedHeaders are the response headers stored and replayed with the body
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// responseRecorder copies everything written to the client
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes mutating requests that carry an Idempotency-Key header
+// safe to retry. The first request with a key runs normally and its response
+// is stored; retries with the same key and payload get that response back
+// with Idempotent-Replayed: true. Reusing a key for a different payload is
+// rejected with 422. Keys are scoped to the authenticated user, so this must
+// run after RequireAuth. Server errors are not cached so they can be retried.
+func Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || !isMutating(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			return
+		}
+
+		db, ok := GetFamilyDB(c)
+		if !ok {
+			c.Next()
+			return
+		}
+		userID, ok := GetAuthUserID(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body is too large"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "?" + c.Request.URL.RawQuery + "\n"))
+		hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		ctx := c.Request.Context()
+
+		// Drop an expired key, or one abandoned mid-request, so it can be reused
+		_, err = db.Exec(ctx, `
+			DELETE FROM idempotency_keys
+			WHERE user_id = $1 AND idempotency_key = $2
+				AND (expires_at < NOW()
+					OR (status = 'in_progress' AND created_at < NOW() - INTERVAL '5 minutes'))
+		`, userID, key)
+		if err != nil {
+			log.Printf("❌ Idempotency: failed to expire key: %v", err)
+		}
+
+		// Claim the key; only one request can hold it at a time
+		result, err := db.Exec(ctx, `
+			INSERT INTO idempotency_keys (
+				user_id, idempotency_key, method, path, request_hash, status, created_at, expires_at
+			) VALUES ($1, $2, $3, $4, $5, 'in_progress', NOW(), $6)
+			ON CONFLICT (user_id, idempotency_key) DO NOTHING
+		`, userID, key, c.Request.Method, c.Request.URL.Path, requestHash, time.Now().Add(IdempotencyTTL))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to record idempotency key", "details": err.Error()})
+			return
+		}
+
+		if result.RowsAffected() == 0 {
+			replayIdempotent(c, userID, key, requestHash)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			// Let the client retry with the same key
+			if _, err := db.Exec(ctx,
+				"DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2",
+				userID, key,
+			); err != nil {
+				log.Printf("❌ Idempotency: failed to release key: %v", err)
+			}
+			return
+		}
+
+		headers := map[string]string{}
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				headers[name] = value
+			}
+		}
+		headersJSON, _ := json.Marshal(headers)
+
+		_, err = db.Exec(ctx, `
+			UPDATE idempotency_keys
+			SET status = 'completed',
+				response_status = $1,
+				response_headers = $2,
+				response_body = $3
+			WHERE user_id = $4 AND idempotency_key = $5
+		`, status, headersJSON, recorder.body.Bytes(), userID, key)
+		if err != nil {
+			log.Printf("❌ Idempotency: failed to store response: %v", err)
+		}
+	}
+}
+
+// replayIdempotent answers a request whose key was already used
+func replayIdempotent(c *gin.Context, userID uuid.UUID, key, requestHash string) {
+	db, _ := GetFamilyDB(c)
+
+	var (
+		storedHash     string
+		status         string
+		responseStatus *int
+		headersJSON    []byte
+		body           []byte
+	)
+	err := db.QueryRow(c.Request.Context(), `
+		SELECT request_hash, status, response_status, response_headers, response_body
+		FROM idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2
+	`, userID, key).Scan(&storedHash, &status, &responseStatus, &headersJSON, &body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "Idempotency key is in use, please retry"})
+		return
+	}
+
+	if storedHash != requestHash {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "Idempotency-Key was already used for a different request",
+		})
+		return
+	}
+
+	if status != "completed" || responseStatus == nil {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still in progress"})
+		return
+	}
+
+	headers := map[string]string{}
+	json.Unmarshal(headersJSON, &headers)
+	for name, value := range headers {
+		c.Header(name, value)
+	}
+	c.Header("Idempotent-Replayed", "true")
+
+	contentType := headers["Content-Type"]
+	if contentType == "" {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Data(*responseStatus, contentType, body)
+	c.Abort()
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
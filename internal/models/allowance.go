@@ -0,0 +1,187 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Money amounts are integers in minor units of the family currency (cents
+// for USD). Formatted fields are for display only.
+
+// MoneyTransaction is one entry in a child's allowance money ledger
+type MoneyTransaction struct {
+	ID              uuid.UUID  `json:"id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	AmountMinor     int64      `json:"amount_minor"` // Positive credited, negative paid out
+	Amount          string     `json:"amount"`
+	Currency        string     `json:"currency"`
+	TransactionType string     `json:"transaction_type"` // conversion, payout, allowance
+	Description     string     `json:"description"`
+	ConversionID    *uuid.UUID `json:"conversion_id,omitempty"`
+	PayoutID        *uuid.UUID `json:"payout_id,omitempty"`
+	CreatedBy       *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// MoneyBalance is a child's allowance money balance in one currency
+type MoneyBalance struct {
+	UserID       uuid.UUID `json:"user_id"`
+	DisplayName  string    `json:"display_name"`
+	BalanceMinor int64     `json:"balance_minor"`
+	Balance      string    `json:"balance"`
+	Currency     string    `json:"currency"`
+}
+
+// PointConversion is a child's request to turn points into money, priced at
+// the exchange rate when it was requested
+type PointConversion struct {
+	ID                 uuid.UUID  `json:"id"`
+	UserID             uuid.UUID  `json:"user_id"`
+	UserName           string     `json:"user_name"`
+	Points             int        `json:"points"`
+	AmountMinor        int64      `json:"amount_minor"`
+	Amount             string     `json:"amount"`
+	Currency           string     `json:"currency"`
+	RatePoints         int        `json:"rate_points"`
+	RateMinorUnits     int        `json:"rate_minor_units"`
+	Note               *string    `json:"note,omitempty"`
+	Status             string     `json:"status"` // pending, completed, rejected, cancelled
+	DecidedBy          *uuid.UUID `json:"decided_by,omitempty"`
+	DecidedAt          *time.Time `json:"decided_at,omitempty"`
+	DecisionNotes      *string    `json:"decision_notes,omitempty"`
+	PointTransactionID *uuid.UUID `json:"point_transaction_id,omitempty"`
+	MoneyTransactionID *uuid.UUID `json:"money_transaction_id,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// AllowancePayout is money a parent handed over
+type AllowancePayout struct {
+	ID                 uuid.UUID  `json:"id"`
+	UserID             uuid.UUID  `json:"user_id"`
+	AmountMinor        int64      `json:"amount_minor"`
+	Amount             string     `json:"amount"`
+	Currency           string     `json:"currency"`
+	Method             string     `json:"method"` // cash, transfer, gift_card
+	Note               *string    `json:"note,omitempty"`
+	PaidBy             *uuid.UUID `json:"paid_by,omitempty"`
+	MoneyTransactionID *uuid.UUID `json:"money_transaction_id,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// ExchangeRate is how many points are worth how many minor units
+type ExchangeRate struct {
+	Currency   string `json:"currency"`
+	Points     int    `json:"points"`
+	MinorUnits int    `json:"minor_units"`
+	Amount     string `json:"amount"` // MinorUnits formatted
+	Enabled    bool   `json:"enabled"`
+}
+
+// ExchangeRateRequest is the request body for PUT /api/allowance/rate. Zero
+// points turns conversion off.
+type ExchangeRateRequest struct {
+	Points     int `json:"points" binding:"min=0"`
+	MinorUnits int `json:"minor_units" binding:"min=0"`
+}
+
+// ConversionRequest is the request body for POST /api/allowance/conversions
+type ConversionRequest struct {
+	Points int     `json:"points" binding:"required,min=1"`
+	Note   *string `json:"note,omitempty" binding:"omitempty,max=500"`
+}
+
+// ConversionDecisionRequest is the request body for the conversion approve,
+// reject and cancel endpoints
+type ConversionDecisionRequest struct {
+	Notes *string `json:"notes,omitempty"`
+}
+
+// PayoutRequest is the request body for POST /api/allowance/payouts
+type PayoutRequest struct {
+	UserID      uuid.UUID `json:"user_id" binding:"required"`
+	AmountMinor int64     `json:"amount_minor" binding:"required,min=1"`
+	Method      string    `json:"method" binding:"required,oneof=cash transfer gift_card"`
+	Note        *string   `json:"note,omitempty" binding:"omitempty,max=500"`
+}
+
+// MoneyStatement lists a child's money ledger for a period with running
+// balances
+type MoneyStatement struct {
+	UserID       uuid.UUID       `json:"user_id"`
+	DisplayName  string          `json:"display_name"`
+	Currency     string          `json:"currency"`
+	From         string          `json:"from"`
+	To           string          `json:"to"`
+	OpeningMinor int64           `json:"opening_balance_minor"`
+	Opening      string          `json:"opening_balance"`
+	CreditsMinor int64           `json:"credits_minor"`
+	Credits      string          `json:"credits"`
+	DebitsMinor  int64           `json:"debits_minor"`
+	Debits       string          `json:"debits"`
+	ClosingMinor int64           `json:"closing_balance_minor"`
+	Closing      string          `json:"closing_balance"`
+	Lines        []StatementLine `json:"lines"`
+}
+
+// StatementLine is one money transaction with the balance after it
+type StatementLine struct {
+	MoneyTransaction
+	BalanceMinor int64  `json:"balance_minor"`
+	Balance      string `json:"balance"`
+}
+
+// AllowanceSchedule pays a child a fixed amount every week on Weekday
+type AllowanceSchedule struct {
+	ID                uuid.UUID  `json:"id"`
+	UserID            uuid.UUID  `json:"user_id"`
+	UserName          string     `json:"user_name"`
+	Kind              string     `json:"kind"`   // points, money
+	Amount            int64      `json:"amount"` // Points, or minor units for money
+	AmountDisplay     string     `json:"amount_display"`
+	Weekday           int        `json:"weekday"` // 0=Sunday
+	MinCompletionRate *float64   `json:"min_completion_rate,omitempty"`
+	Description       *string    `json:"description,omitempty"`
+	Active            bool       `json:"active"`
+	CreatedBy         *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// AllowanceScheduleRequest is the request body for POST
+// /api/allowance/schedules
+type AllowanceScheduleRequest struct {
+	UserID            uuid.UUID `json:"user_id" binding:"required"`
+	Kind              string    `json:"kind" binding:"required,oneof=points money"`
+	Amount            int64     `json:"amount" binding:"required,min=1"`
+	Weekday           int       `json:"weekday" binding:"min=0,max=6"`
+	MinCompletionRate *float64  `json:"min_completion_rate,omitempty" binding:"omitempty,min=0,max=100"`
+	Description       *string   `json:"description,omitempty" binding:"omitempty,max=200"`
+}
+
+// AllowanceScheduleUpdateRequest is the request body for PUT
+// /api/allowance/schedules/:id. Only the fields given change.
+type AllowanceScheduleUpdateRequest struct {
+	Amount            *int64   `json:"amount,omitempty" binding:"omitempty,min=1"`
+	Weekday           *int     `json:"weekday,omitempty" binding:"omitempty,min=0,max=6"`
+	MinCompletionRate *float64 `json:"min_completion_rate,omitempty" binding:"omitempty,min=0,max=100"`
+	ClearMinRate      bool     `json:"clear_min_completion_rate,omitempty"`
+	Description       *string  `json:"description,omitempty" binding:"omitempty,max=200"`
+	Active            *bool    `json:"active,omitempty"`
+}
+
+// AllowanceDeposit is one scheduled day of an allowance schedule
+type AllowanceDeposit struct {
+	ID                 uuid.UUID  `json:"id"`
+	ScheduleID         uuid.UUID  `json:"schedule_id"`
+	UserID             uuid.UUID  `json:"user_id"`
+	DepositDate        string     `json:"deposit_date"`
+	Kind               string     `json:"kind"`
+	Amount             int64      `json:"amount"`
+	Status             string     `json:"status"` // paid, skipped
+	CompletionRate     *float64   `json:"completion_rate,omitempty"`
+	PointTransactionID *uuid.UUID `json:"point_transaction_id,omitempty"`
+	MoneyTransactionID *uuid.UUID `json:"money_transaction_id,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
@@ -63,8 +63,14 @@ type AssignmentListResponse struct {
 	CompletionNotes   *string             `json:"completion_notes,omitempty"`
 	VerificationNotes *string             `json:"verification_notes,omitempty"`
 	IsBonus           bool                `json:"is_bonus"` // true if unassigned or open status
+	IsTeam            bool                `json:"is_team"`
+	SplitMode         *string             `json:"split_mode,omitempty"`
+	Version           int                 `json:"version"`
 	Chore             AssignmentChoreInfo `json:"chore"`
 	AssignedUser      *AssignmentUserInfo `json:"assigned_user,omitempty"`
+	Participants      []AssignmentParticipant `json:"participants,omitempty"`
+	Sequence          string              `json:"sequence,omitempty"` // blocked or ready, for assignments with prerequisites
+	Prerequisites     []AssignmentPrerequisite `json:"prerequisites,omitempty"`
 }
 
 // AssignmentDetailResponse includes full details for a single assignment
@@ -84,9 +90,18 @@ type AssignmentDetailResponse struct {
 	CompletionNotes   *string              `json:"completion_notes,omitempty"`
 	VerificationNotes *string              `json:"verification_notes,omitempty"`
 	IsBonus           bool                 `json:"is_bonus"`
+	IsTeam            bool                 `json:"is_team"`
+	SplitMode         *string              `json:"split_mode,omitempty"`
+	Version           int                  `json:"version"`
 	Chore             AssignmentChoreInfo  `json:"chore"`
 	AssignedUser      *AssignmentUserInfo  `json:"assigned_user,omitempty"`
 	AssignedByUser    *AssignmentUserInfo  `json:"assigned_by_user,omitempty"`
+	Participants      []AssignmentParticipant `json:"participants,omitempty"`
+	Checklist         []AssignmentChecklistItem `json:"checklist"`
+	Time              AssignmentTime       `json:"time"`
+	Sequence          string               `json:"sequence,omitempty"`
+	Prerequisites     []AssignmentPrerequisite `json:"prerequisites,omitempty"`
+	Photos            []AssignmentPhoto    `json:"photos"`
 }
 
 // AssignmentCreateRequest is the request body for POST /api/assignments
@@ -95,4 +110,118 @@ type AssignmentCreateRequest struct {
 	AssignedTo    *uuid.UUID `json:"assigned_to,omitempty"`
 	PointsOffered int        `json:"points_offered"`
 	DueDate       *string    `json:"due_date,omitempty"` // ISO date or date-time string
+	// Participants makes this a team assignment shared by several children
+	Participants []AssignmentParticipantRequest `json:"participants,omitempty" binding:"omitempty,dive"`
+	SplitMode    string                         `json:"split_mode,omitempty" binding:"omitempty,oneof=even weighted manual"`
+}
+
+// AssignmentParticipantRequest adds a child to a team assignment
+type AssignmentParticipantRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Weight *float64  `json:"weight,omitempty" binding:"omitempty,gt=0"`
+}
+
+// AssignmentParticipant is one child's part in a team assignment
+type AssignmentParticipant struct {
+	UserID          uuid.UUID  `json:"user_id"`
+	Username        string     `json:"username"`
+	DisplayName     string     `json:"display_name"`
+	ColorTheme      string     `json:"color_theme"`
+	Weight          float64    `json:"weight"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	CompletionNotes *string    `json:"completion_notes,omitempty"`
+	PointsEarned    *int       `json:"points_earned,omitempty"`
+}
+
+// AssignmentEvent is a single entry in an assignment's history
+type AssignmentEvent struct {
+	ID          uuid.UUID              `json:"id"`
+	EventType   string                 `json:"event_type"`
+	FromStatus  *string                `json:"from_status,omitempty"`
+	ToStatus    *string                `json:"to_status,omitempty"`
+	ActorID     *uuid.UUID             `json:"actor_id,omitempty"`
+	ActorName   *string                `json:"actor_name,omitempty"`
+	Notes       *string                `json:"notes,omitempty"`
+	PointsDelta int                    `json:"points_delta"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
+
+// AssignmentPhoto is an uploaded photo with short-lived signed URLs
+type AssignmentPhoto struct {
+	ID           uuid.UUID  `json:"id"`
+	Kind         string     `json:"kind"` // "before" or "after"
+	URL          string     `json:"url"`
+	ThumbnailURL string     `json:"thumbnail_url"`
+	Width        int        `json:"width"`
+	Height       int        `json:"height"`
+	SizeBytes    int        `json:"size_bytes"`
+	UploadedBy   *uuid.UUID `json:"uploaded_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// AssignmentSubmission is one completion round of an assignment and its review
+type AssignmentSubmission struct {
+	ID                   uuid.UUID  `json:"id"`
+	Round                int        `json:"round"`
+	SubmittedBy          *uuid.UUID `json:"submitted_by,omitempty"`
+	SubmittedByName      *string    `json:"submitted_by_name,omitempty"`
+	SubmittedAt          time.Time  `json:"submitted_at"`
+	CompletionNotes      *string    `json:"completion_notes,omitempty"`
+	Outcome              string     `json:"outcome"` // pending, verified, needs_rework
+	ReviewedBy           *uuid.UUID `json:"reviewed_by,omitempty"`
+	ReviewedByName       *string    `json:"reviewed_by_name,omitempty"`
+	ReviewedAt           *time.Time `json:"reviewed_at,omitempty"`
+	ReviewNotes          *string    `json:"review_notes,omitempty"`
+	RejectionReason      *string    `json:"rejection_reason,omitempty"`
+	RedoDueDate          *time.Time `json:"redo_due_date,omitempty"`
+	QualityRating        *int       `json:"quality_rating,omitempty"`
+	PartialCreditPercent *int       `json:"partial_credit_percent,omitempty"`
+	PointsAwarded        *int       `json:"points_awarded,omitempty"`
+}
+
+// AssignmentChecklistItem is a checklist step on an assignment and whether it
+// has been done
+type AssignmentChecklistItem struct {
+	ID            uuid.UUID        `json:"id"`
+	Position      int              `json:"position"`
+	Title         string           `json:"title"`
+	Description   *string          `json:"description,omitempty"`
+	Required      bool             `json:"required"`
+	RequiresPhoto bool             `json:"requires_photo"`
+	Checked       bool             `json:"checked"`
+	CheckedAt     *time.Time       `json:"checked_at,omitempty"`
+	CheckedBy     *uuid.UUID       `json:"checked_by,omitempty"`
+	CheckedByName *string          `json:"checked_by_name,omitempty"`
+	Photo         *AssignmentPhoto `json:"photo,omitempty"`
+}
+
+// AssignmentPrerequisite is an assignment that a dependent assignment waits
+// for. Satisfied is set once it is verified or closed without the work.
+type AssignmentPrerequisite struct {
+	AssignmentID     uuid.UUID  `json:"assignment_id"`
+	ChoreID          uuid.UUID  `json:"chore_id"`
+	ChoreName        string     `json:"chore_name"`
+	Status           string     `json:"status"`
+	AssignedTo       *uuid.UUID `json:"assigned_to,omitempty"`
+	AssignedUserName *string    `json:"assigned_user_name,omitempty"`
+	Satisfied        bool       `json:"satisfied"`
+}
+
+// TimeSegment is one stretch of a child working on an assignment; EndedAt is
+// nil while the timer is running
+type TimeSegment struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	DisplayName string     `json:"display_name"`
+	StartedAt   time.Time  `json:"started_at"`
+	EndedAt     *time.Time `json:"ended_at,omitempty"`
+	Minutes     float64    `json:"minutes"`
+}
+
+// AssignmentTime is the time worked on an assignment so far
+type AssignmentTime struct {
+	Running       bool          `json:"running"`
+	ActualMinutes float64       `json:"actual_minutes"`
+	Segments      []TimeSegment `json:"segments"`
 }
@@ -0,0 +1,90 @@
+package models
+
+import "github.com/google/uuid"
+
+// Bulk modes. In all_or_nothing mode nothing is saved unless every item
+// succeeds; in best_effort mode the items that succeed are saved.
+const (
+	BulkAllOrNothing = "all_or_nothing"
+	BulkBestEffort   = "best_effort"
+)
+
+// BulkCreateRequest is the request body for POST /api/assignments/bulk/create
+type BulkCreateRequest struct {
+	Mode   string                    `json:"mode" binding:"omitempty,oneof=all_or_nothing best_effort"`
+	DryRun bool                      `json:"dry_run"`
+	Items  []AssignmentCreateRequest `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// BulkReassignItem hands one assignment to another child
+type BulkReassignItem struct {
+	AssignmentID uuid.UUID `json:"assignment_id" binding:"required"`
+	AssignedTo   uuid.UUID `json:"assigned_to" binding:"required"`
+	Version      *int      `json:"version,omitempty"` // Skip the item if the assignment has changed
+	Notes        *string   `json:"notes,omitempty"`
+}
+
+// BulkReassignRequest is the request body for POST /api/assignments/bulk/reassign
+type BulkReassignRequest struct {
+	Mode   string             `json:"mode" binding:"omitempty,oneof=all_or_nothing best_effort"`
+	DryRun bool               `json:"dry_run"`
+	Items  []BulkReassignItem `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// BulkCancelItem withdraws one assignment
+type BulkCancelItem struct {
+	AssignmentID uuid.UUID `json:"assignment_id" binding:"required"`
+	Version      *int      `json:"version,omitempty"`
+	Notes        *string   `json:"notes,omitempty"`
+}
+
+// BulkCancelRequest is the request body for POST /api/assignments/bulk/cancel
+type BulkCancelRequest struct {
+	Mode   string           `json:"mode" binding:"omitempty,oneof=all_or_nothing best_effort"`
+	DryRun bool             `json:"dry_run"`
+	Items  []BulkCancelItem `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// BulkVerifyItem approves one completed assignment. Work that needs redoing
+// is sent back one at a time through the verify endpoint.
+type BulkVerifyItem struct {
+	AssignmentID         uuid.UUID `json:"assignment_id" binding:"required"`
+	Version              *int      `json:"version,omitempty"`
+	PointsAwarded        *int      `json:"points_awarded,omitempty"`
+	QualityRating        *int      `json:"quality_rating,omitempty" binding:"omitempty,min=1,max=5"`
+	PartialCreditPercent *int      `json:"partial_credit_percent,omitempty" binding:"omitempty,min=1,max=100"`
+	EffortBonusPercent   *int      `json:"effort_bonus_percent,omitempty" binding:"omitempty,min=0"`
+	VerificationNotes    *string   `json:"verification_notes,omitempty"`
+}
+
+// BulkVerifyRequest is the request body for POST /api/assignments/bulk/verify
+type BulkVerifyRequest struct {
+	Mode   string           `json:"mode" binding:"omitempty,oneof=all_or_nothing best_effort"`
+	DryRun bool             `json:"dry_run"`
+	Items  []BulkVerifyItem `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// BulkItemResult is the outcome of one item, in request order
+type BulkItemResult struct {
+	Index         int        `json:"index"`
+	AssignmentID  *uuid.UUID `json:"assignment_id,omitempty"`
+	Success       bool       `json:"success"`
+	Status        string     `json:"status,omitempty"`
+	AssignedTo    *uuid.UUID `json:"assigned_to,omitempty"`
+	PointsAwarded *int       `json:"points_awarded,omitempty"`
+	Version       int        `json:"version,omitempty"`
+	Warnings      []string   `json:"warnings,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// BulkResponse reports every item of a bulk request. Committed is false for
+// a dry run, and in all_or_nothing mode when any item failed.
+type BulkResponse struct {
+	Mode      string           `json:"mode"`
+	DryRun    bool             `json:"dry_run"`
+	Committed bool             `json:"committed"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+	Error     string           `json:"error,omitempty"`
+}
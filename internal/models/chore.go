@@ -29,6 +29,9 @@ type Chore struct {
 	MinAge               int        `json:"min_age" db:"min_age"`
 	AssignmentType       string     `json:"assignment_type" db:"assignment_type"`
 	RotationEligible     bool       `json:"rotation_eligible" db:"rotation_eligible"`
+	WindowStart          *string    `json:"window_start,omitempty"` // HH:MM in the family timezone
+	WindowEnd            *string    `json:"window_end,omitempty"`
+	LateCutoffMinutes    *int       `json:"late_cutoff_minutes,omitempty" db:"late_cutoff_minutes"`
 }
 
 // ChoreCreateRequest is the request body for POST /api/chores
@@ -44,9 +47,14 @@ type ChoreCreateRequest struct {
 	Difficulty           string   `json:"difficulty"`
 	Frequency            *string  `json:"frequency,omitempty"`
 	Tags                 []string `json:"tags,omitempty"`
+	Icon                 *string  `json:"icon,omitempty"`
+	MinAge               *int     `json:"min_age,omitempty" binding:"omitempty,min=0"`
 	RotationEligible     bool     `json:"rotation_eligible"`
 	RequiresPhoto        bool     `json:"requires_photo"`
 	RequiresVerification bool     `json:"requires_verification"`
+	WindowStart          string   `json:"window_start,omitempty"` // HH:MM, with window_end
+	WindowEnd            string   `json:"window_end,omitempty"`
+	LateCutoffMinutes    *int     `json:"late_cutoff_minutes,omitempty" binding:"omitempty,min=0"`
 }
 
 // ChoreUpdateRequest is the request body for PUT/PATCH /api/chores/:id
@@ -66,6 +74,44 @@ type ChoreUpdateRequest struct {
 	RotationEligible     *bool    `json:"rotation_eligible,omitempty"`
 	RequiresPhoto        *bool    `json:"requires_photo,omitempty"`
 	RequiresVerification *bool    `json:"requires_verification,omitempty"`
+	// WindowStart and WindowEnd are set together; empty strings clear the window
+	WindowStart          *string  `json:"window_start,omitempty"`
+	WindowEnd            *string  `json:"window_end,omitempty"`
+	LateCutoffMinutes    *int     `json:"late_cutoff_minutes,omitempty" binding:"omitempty,min=0"`
+}
+
+// ChoreChecklistItem is one step of a chore's checklist
+type ChoreChecklistItem struct {
+	ID            uuid.UUID `json:"id"`
+	Position      int       `json:"position"`
+	Title         string    `json:"title"`
+	Description   *string   `json:"description,omitempty"`
+	Required      bool      `json:"required"`
+	RequiresPhoto bool      `json:"requires_photo"`
+}
+
+// ChecklistItemRequest defines a checklist step; Required defaults to true
+type ChecklistItemRequest struct {
+	Title         string  `json:"title" binding:"required,max=200"`
+	Description   *string `json:"description,omitempty"`
+	Required      *bool   `json:"required,omitempty"`
+	RequiresPhoto bool    `json:"requires_photo"`
+}
+
+// ChoreChecklistRequest is the request body for PUT /api/chores/:id/checklist
+type ChoreChecklistRequest struct {
+	Items []ChecklistItemRequest `json:"items" binding:"dive"`
+}
+
+// ChorePrerequisite is a chore that has to be done before another one
+type ChorePrerequisite struct {
+	ChoreID uuid.UUID `json:"chore_id"`
+	Name    string    `json:"name"`
+}
+
+// ChorePrerequisitesRequest is the request body for PUT /api/chores/:id/prerequisites
+type ChorePrerequisitesRequest struct {
+	ChoreIDs []uuid.UUID `json:"chore_ids"`
 }
 
 // ChoreListResponse is a simplified version for list endpoints
@@ -77,6 +123,9 @@ type ChoreListResponse struct {
 	EstimatedMinutes *int      `json:"estimated_minutes,omitempty"`
 	Difficulty       string    `json:"difficulty"`
 	Icon             string    `json:"icon"`
+	Tags             []string  `json:"tags,omitempty"`
+	MinAge           int       `json:"min_age"`
 	Active           bool      `json:"active"`
 	AssignmentType   string    `json:"assignment_type"`
+	AssignmentCount  int       `json:"assignment_count"` // Popularity: assignments ever made
 }
@@ -31,15 +31,59 @@ type Family struct {
 
 // FamilySettings represents family-specific configuration
 type FamilySettings struct {
-	ID                uuid.UUID `json:"id" db:"id"`
-	FamilyID          uuid.UUID `json:"family_id" db:"family_id"`
-	Timezone          string    `json:"timezone" db:"timezone"`                     // Family timezone (e.g., "America/New_York")
-	Currency          string    `json:"currency" db:"currency"`                     // Currency code (e.g., "USD")
-	WeekStartDay      int       `json:"week_start_day" db:"week_start_day"`         // 0=Sunday, 1=Monday
-	ThemeColor        string    `json:"theme_color" db:"theme_color"`               // Primary color for family branding
-	CustomDomain      *string   `json:"custom_domain,omitempty" db:"custom_domain"` // Optional custom domain
-	CreatedAt         time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+	ID                 uuid.UUID `json:"id" db:"id"`
+	FamilyID           uuid.UUID `json:"family_id" db:"family_id"`
+	Timezone           string    `json:"timezone" db:"timezone"`                         // Family timezone (e.g., "America/New_York")
+	Currency           string    `json:"currency" db:"currency"`                         // Currency code (e.g., "USD")
+	ExchangePoints     int       `json:"exchange_points" db:"exchange_points"`           // Points worth ExchangeMinorUnits; 0 disables conversion
+	ExchangeMinorUnits int       `json:"exchange_minor_units" db:"exchange_minor_units"` // Minor units of Currency (e.g., cents)
+	WeekStartDay       int       `json:"week_start_day" db:"week_start_day"`             // 0=Sunday, 1=Monday
+	ThemeColor         string    `json:"theme_color" db:"theme_color"`                   // Primary color for family branding
+	CustomDomain       *string   `json:"custom_domain,omitempty" db:"custom_domain"`     // Optional custom domain
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultFamilySettings returns the settings used when a family has none stored
+func DefaultFamilySettings(familyID uuid.UUID) *FamilySettings {
+	return &FamilySettings{
+		FamilyID:     familyID,
+		Timezone:     "UTC",
+		Currency:     "USD",
+		WeekStartDay: 1,
+		ThemeColor:   "#3498db",
+	}
+}
+
+// Location returns the family's timezone, falling back to UTC if it is invalid
+func (s *FamilySettings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// WeekStart returns midnight of the first day of the week containing day,
+// honoring WeekStartDay
+func (s *FamilySettings) WeekStart(day time.Time) time.Time {
+	start := ((s.WeekStartDay % 7) + 7) % 7
+	offset := (int(day.Weekday()) - start + 7) % 7
+	return time.Date(day.Year(), day.Month(), day.Day()-offset, 0, 0, 0, 0, day.Location())
+}
+
+// ConversionEnabled reports whether points can be converted to money
+func (s *FamilySettings) ConversionEnabled() bool {
+	return s.ExchangePoints > 0 && s.ExchangeMinorUnits > 0
+}
+
+// PointsToMinor converts points to minor units of Currency at the exchange
+// rate, rounding down
+func (s *FamilySettings) PointsToMinor(points int) int64 {
+	if !s.ConversionEnabled() {
+		return 0
+	}
+	return int64(points) * int64(s.ExchangeMinorUnits) / int64(s.ExchangePoints)
 }
 
 // FamilyMember represents a user's membership in a family
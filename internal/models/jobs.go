@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// JobRun is a daily background job run for a family-local date
+type JobRun struct {
+	JobName     string     `json:"job_name"`
+	RunDate     string     `json:"run_date"`
+	Status      string     `json:"status"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+}
+
+// JobRunRequest is the request body for POST /api/jobs/:name/run
+type JobRunRequest struct {
+	StartDate string `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate   string `json:"end_date,omitempty"`            // YYYY-MM-DD, defaults to start_date
+	Force     bool   `json:"force"`                         // Re-run days that already completed
+}
@@ -25,3 +25,32 @@ type LeaderboardResponse struct {
 	Leaderboard []LeaderboardEntry `json:"leaderboard"`
 	TotalUsers  int                `json:"total_users"`
 }
+
+// WeeklyStanding is a user's final position in a completed week
+type WeeklyStanding struct {
+	Rank            int       `json:"rank"`
+	UserID          uuid.UUID `json:"user_id"`
+	Username        string    `json:"username"`
+	DisplayName     string    `json:"display_name"`
+	AvatarURL       *string   `json:"avatar_url,omitempty"`
+	ColorTheme      string    `json:"color_theme"`
+	Points          int       `json:"points"`
+	ChoresCompleted int       `json:"chores_completed"`
+	IsWinner        bool      `json:"is_winner"`
+}
+
+// WeeklyHistoryEntry summarizes a completed week and its winners
+type WeeklyHistoryEntry struct {
+	WeekStart   string           `json:"week_start"`
+	WeekEnd     string           `json:"week_end"`
+	TotalPoints int              `json:"total_points"`
+	Winners     []WeeklyStanding `json:"winners"`
+}
+
+// WeeklyStandingsResponse is the API response for a past week's leaderboard
+type WeeklyStandingsResponse struct {
+	WeekStart   string           `json:"week_start"`
+	WeekEnd     string           `json:"week_end"`
+	Leaderboard []WeeklyStanding `json:"leaderboard"`
+	TotalUsers  int              `json:"total_users"`
+}
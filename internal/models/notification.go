@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification is an in-app message for a family member
+type Notification struct {
+	ID           uuid.UUID  `json:"id"`
+	Type         string     `json:"type"`
+	Title        string     `json:"title"`
+	Body         *string    `json:"body,omitempty"`
+	AssignmentID *uuid.UUID `json:"assignment_id,omitempty"`
+	Read         bool       `json:"read"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "github.com/google/uuid"
+
+// ChorePack is a portable set of chores, imported and exported as JSON or
+// YAML
+type ChorePack struct {
+	Format      int         `json:"format" yaml:"format"` // Currently 1
+	ID          string      `json:"id,omitempty" yaml:"id,omitempty"`
+	Name        string      `json:"name" yaml:"name" binding:"required"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	MinAge      int         `json:"min_age,omitempty" yaml:"min_age,omitempty"`
+	MaxAge      int         `json:"max_age,omitempty" yaml:"max_age,omitempty"`
+	Chores      []PackChore `json:"chores" yaml:"chores" binding:"required,min=1,max=200,dive"`
+}
+
+// PackChore is one chore in a pack. Points are a suggestion the family can
+// change after importing.
+type PackChore struct {
+	Name                 string   `json:"name" yaml:"name" binding:"required,max=100"`
+	Description          *string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Instructions         *string  `json:"instructions,omitempty" yaml:"instructions,omitempty"`
+	Category             string   `json:"category,omitempty" yaml:"category,omitempty"`
+	Icon                 string   `json:"icon,omitempty" yaml:"icon,omitempty"`
+	Tags                 []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	SuggestedPoints      int      `json:"suggested_points,omitempty" yaml:"suggested_points,omitempty" binding:"min=0"`
+	EstimatedMinutes     *int     `json:"estimated_minutes,omitempty" yaml:"estimated_minutes,omitempty" binding:"omitempty,min=1"`
+	Difficulty           string   `json:"difficulty,omitempty" yaml:"difficulty,omitempty" binding:"omitempty,oneof=easy medium hard"`
+	MinAge               int      `json:"min_age,omitempty" yaml:"min_age,omitempty" binding:"min=0"`
+	Frequency            *string  `json:"frequency,omitempty" yaml:"frequency,omitempty"`
+	RotationEligible     bool     `json:"rotation_eligible,omitempty" yaml:"rotation_eligible,omitempty"`
+	RequiresPhoto        bool     `json:"requires_photo,omitempty" yaml:"requires_photo,omitempty"`
+	RequiresVerification bool     `json:"requires_verification,omitempty" yaml:"requires_verification,omitempty"`
+}
+
+// ChorePackSummary describes a built-in pack without its chores
+type ChorePackSummary struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	MinAge      int    `json:"min_age"`
+	MaxAge      int    `json:"max_age,omitempty"`
+	ChoreCount  int    `json:"chore_count"`
+}
+
+// PackImportResult is what happened to one chore of an imported pack
+type PackImportResult struct {
+	Name    string     `json:"name"`
+	Action  string     `json:"action"` // created, renamed, replaced, skipped
+	ChoreID *uuid.UUID `json:"chore_id,omitempty"`
+	// CreatedAs is the new name when a duplicate was renamed
+	CreatedAs *string `json:"created_as,omitempty"`
+}
+
+// PackImportResponse is the response for the chore pack import endpoints
+type PackImportResponse struct {
+	Pack     string             `json:"pack"`
+	Conflict string             `json:"conflict"` // skip, rename, replace
+	DryRun   bool               `json:"dry_run"`
+	Created  int                `json:"created"`
+	Replaced int                `json:"replaced"`
+	Skipped  int                `json:"skipped"`
+	Results  []PackImportResult `json:"results"`
+}
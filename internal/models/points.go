@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,40 +24,132 @@ type PointsBalance struct {
 
 // PointTransaction represents a point transaction record
 type PointTransaction struct {
-	ID                  uuid.UUID  `json:"id" db:"id"`
-	UserID              uuid.UUID  `json:"user_id" db:"user_id"`
-	Points              int        `json:"points" db:"points"` // Positive for earned, negative for spent
-	TransactionType     string     `json:"transaction_type" db:"transaction_type"`
-	Description         string     `json:"description" db:"description"`
-	RelatedAssignmentID *uuid.UUID `json:"related_assignment_id,omitempty" db:"related_assignment_id"`
-	RelatedUserID       *uuid.UUID `json:"related_user_id,omitempty" db:"related_user_id"`
-	ExtraData           *string    `json:"extra_data,omitempty" db:"extra_data"` // JSONB field
-	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
-	Reference           *uuid.UUID `json:"reference,omitempty" db:"reference"`
+	ID                    uuid.UUID  `json:"id" db:"id"`
+	UserID                uuid.UUID  `json:"user_id" db:"user_id"`
+	Points                int        `json:"points" db:"points"` // Positive for earned, negative for spent
+	TransactionType       string     `json:"transaction_type" db:"transaction_type"`
+	Description           string     `json:"description" db:"description"`
+	RelatedAssignmentID   *uuid.UUID `json:"related_assignment_id,omitempty" db:"related_assignment_id"`
+	RelatedUserID         *uuid.UUID `json:"related_user_id,omitempty" db:"related_user_id"`
+	ExtraData             *string    `json:"extra_data,omitempty" db:"extra_data"` // JSONB field
+	CreatedAt             time.Time  `json:"created_at" db:"created_at"`
+	Reference             *uuid.UUID `json:"reference,omitempty" db:"reference"`
+	ReversesTransactionID *uuid.UUID `json:"reverses_transaction_id,omitempty" db:"reverses_transaction_id"` // The transaction this one reverses
 }
 
 // PointTransactionResponse is the API response format
 type PointTransactionResponse struct {
-	ID                  uuid.UUID  `json:"id"`
-	UserID              uuid.UUID  `json:"user_id"`
-	Points              int        `json:"points"`
-	TransactionType     string     `json:"transaction_type"`
-	Description         string     `json:"description"`
-	RelatedAssignmentID *uuid.UUID `json:"related_assignment_id,omitempty"`
-	RelatedUserID       *uuid.UUID `json:"related_user_id,omitempty"`
-	CreatedAt           string     `json:"created_at"`
+	ID                    uuid.UUID       `json:"id"`
+	UserID                uuid.UUID       `json:"user_id"`
+	Points                int             `json:"points"`
+	TransactionType       string          `json:"transaction_type"`
+	Description           string          `json:"description"`
+	RelatedAssignmentID   *uuid.UUID      `json:"related_assignment_id,omitempty"`
+	RelatedUserID         *uuid.UUID      `json:"related_user_id,omitempty"`
+	ExtraData             json.RawMessage `json:"extra_data,omitempty"`              // Who, why and what it links to
+	ReversesTransactionID *uuid.UUID      `json:"reverses_transaction_id,omitempty"` // The transaction this one reverses
+	CreatedAt             string          `json:"created_at"`
 }
 
 // ToResponse converts PointTransaction to PointTransactionResponse
 func (pt *PointTransaction) ToResponse() PointTransactionResponse {
+	var extraData json.RawMessage
+	if pt.ExtraData != nil {
+		extraData = json.RawMessage(*pt.ExtraData)
+	}
 	return PointTransactionResponse{
-		ID:                  pt.ID,
-		UserID:              pt.UserID,
-		Points:              pt.Points,
-		TransactionType:     pt.TransactionType,
-		Description:         pt.Description,
-		RelatedAssignmentID: pt.RelatedAssignmentID,
-		RelatedUserID:       pt.RelatedUserID,
-		CreatedAt:           pt.CreatedAt.Format(time.RFC3339),
+		ID:                    pt.ID,
+		UserID:                pt.UserID,
+		Points:                pt.Points,
+		TransactionType:       pt.TransactionType,
+		Description:           pt.Description,
+		RelatedAssignmentID:   pt.RelatedAssignmentID,
+		RelatedUserID:         pt.RelatedUserID,
+		ExtraData:             extraData,
+		ReversesTransactionID: pt.ReversesTransactionID,
+		CreatedAt:             pt.CreatedAt.Format(time.RFC3339),
 	}
 }
+
+// BalanceDiscrepancy is one cached balance column that disagrees with the
+// point ledger
+type BalanceDiscrepancy struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Username   string    `json:"username"`
+	Field      string    `json:"field"`
+	Cached     int       `json:"cached"`
+	Ledger     int       `json:"ledger"`
+	Difference int       `json:"difference"` // Cached minus ledger
+}
+
+// BalanceReconcileRequest is the request body for POST /api/points/reconcile
+type BalanceReconcileRequest struct {
+	UserID *uuid.UUID `json:"user_id,omitempty"` // Only this user; all users if omitted
+	DryRun bool       `json:"dry_run"`
+}
+
+// BalanceReconcileResponse reports every discrepancy found. Repaired is true
+// when the cached balances were rewritten from the ledger.
+type BalanceReconcileResponse struct {
+	DryRun        bool                 `json:"dry_run"`
+	Repaired      bool                 `json:"repaired"`
+	UsersChecked  int                  `json:"users_checked"`
+	UsersDrifted  int                  `json:"users_drifted"`
+	Discrepancies []BalanceDiscrepancy `json:"discrepancies"`
+}
+
+// PointAdjustment is a manual change to a user's points made by a parent
+type PointAdjustment struct {
+	ID               uuid.UUID  `json:"id"`
+	UserID           uuid.UUID  `json:"user_id"`
+	UserName         string     `json:"user_name"`
+	Points           int        `json:"points"`
+	ReasonCode       string     `json:"reason_code"`
+	Note             *string    `json:"note,omitempty"`
+	Status           string     `json:"status"` // pending_approval, posted, rejected
+	RequestedBy      *uuid.UUID `json:"requested_by,omitempty"`
+	RequestedByName  *string    `json:"requested_by_name,omitempty"`
+	DecidedBy        *uuid.UUID `json:"decided_by,omitempty"`
+	DecidedByName    *string    `json:"decided_by_name,omitempty"`
+	DecidedAt        *time.Time `json:"decided_at,omitempty"`
+	DecisionNotes    *string    `json:"decision_notes,omitempty"`
+	TransactionID    *uuid.UUID `json:"transaction_id,omitempty"`
+	ApprovalBypassed bool       `json:"approval_bypassed"` // Posted without approval for lack of a co-parent
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// PointAdjustmentRequest is the request body for POST
+// /api/users/:id/points/adjust. A note is required with reason code other.
+type PointAdjustmentRequest struct {
+	Points     int     `json:"points" binding:"required"` // Positive to add, negative to remove
+	ReasonCode string  `json:"reason_code" binding:"required,oneof=correction missed_credit bonus behavior refund other"`
+	Note       *string `json:"note,omitempty" binding:"omitempty,max=500"`
+}
+
+// PointAdjustmentDecisionRequest is the request body for the adjustment
+// approve and reject endpoints
+type PointAdjustmentDecisionRequest struct {
+	Notes *string `json:"notes,omitempty"`
+}
+
+// TransactionReversalRequest is the request body for POST
+// /api/transactions/:id/reverse
+type TransactionReversalRequest struct {
+	Reason *string `json:"reason,omitempty" binding:"omitempty,max=500"`
+	// Force reverses even if a balance would go below zero (parent only)
+	Force bool `json:"force"`
+}
+
+// TransactionReversal reports what a reversal undid. Reversals are the
+// compensating transactions, one per original in the group: a verification
+// is reversed as a whole, as is a sweetener with both its sides.
+type TransactionReversal struct {
+	TransactionID    uuid.UUID                  `json:"transaction_id"`
+	Reversals        []PointTransactionResponse `json:"reversals"`
+	Forced           bool                       `json:"forced"`
+	AssignmentID     *uuid.UUID                 `json:"assignment_id,omitempty"`
+	AssignmentStatus *string                    `json:"assignment_status,omitempty"`
+	RedemptionID     *uuid.UUID                 `json:"redemption_id,omitempty"`
+	StockRestored    bool                       `json:"stock_restored"`
+}
@@ -30,6 +30,7 @@ type ChildWeeklyPerformance struct {
 	TotalAssignments int     `json:"total_assignments"`
 	Completed        int     `json:"completed"`
 	Verified         int     `json:"verified"`
+	AwaitingReview   int     `json:"awaiting_review"` // Done, waiting for a parent to verify
 	PointsEarned     int     `json:"points_earned"`
 	CompletionRate   float64 `json:"completion_rate"`
 }
@@ -104,6 +105,36 @@ type ChildTrendPerformance struct {
 	Trend           string `json:"trend"` // "up", "down", "neutral"
 }
 
+// QualityTrendsResponse represents per-child quality ratings over time
+type QualityTrendsResponse struct {
+	Period   DateRange           `json:"period"`
+	Children []ChildQualityTrend `json:"children"`
+}
+
+// ChildQualityTrend represents one child's quality ratings by week
+type ChildQualityTrend struct {
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name"`
+	AverageRating        float64             `json:"average_rating"`
+	Rated                int                 `json:"rated"`
+	AveragePartialCredit float64             `json:"average_partial_credit"`
+	Verified             int                 `json:"verified"`
+	Reworks              int                 `json:"reworks"`
+	ReworkRate           float64             `json:"rework_rate"` // percent of reviews sent back
+	Trend                string              `json:"trend"`       // "up", "down", "neutral"
+	Weeks                []QualityTrendPoint `json:"weeks"`
+}
+
+// QualityTrendPoint represents a child's ratings for a single week
+type QualityTrendPoint struct {
+	WeekStart            string  `json:"week_start"`
+	AverageRating        float64 `json:"average_rating"`
+	Rated                int     `json:"rated"`
+	AveragePartialCredit float64 `json:"average_partial_credit"`
+	Verified             int     `json:"verified"`
+	Reworks              int     `json:"reworks"`
+}
+
 // PendingRedemptionsResponse represents pending redemptions
 type PendingRedemptionsResponse struct {
 	Redemptions []PendingRedemption `json:"redemptions"`
@@ -126,3 +157,35 @@ type PendingRedemption struct {
 type RedemptionActionRequest struct {
 	Notes *string `json:"notes,omitempty"`
 }
+
+// TimeTrackingResponse compares actual and estimated minutes per chore and per
+// child
+type TimeTrackingResponse struct {
+	Period   DateRange        `json:"period"`
+	Chores   []ChoreTimeStats `json:"chores"`
+	Children []ChildTimeStats `json:"children"`
+}
+
+// ChoreTimeStats is how long a chore actually takes against its estimate.
+// SuggestedMinutes is set once there are enough timed assignments.
+type ChoreTimeStats struct {
+	ChoreID          string   `json:"chore_id"`
+	Name             string   `json:"name"`
+	EstimatedMinutes *int     `json:"estimated_minutes,omitempty"`
+	Timed            int      `json:"timed"`
+	AverageMinutes   float64  `json:"average_minutes"`
+	MedianMinutes    float64  `json:"median_minutes"`
+	VariancePercent  *float64 `json:"variance_percent,omitempty"` // median vs estimate, positive when slower
+	SuggestedMinutes *int     `json:"suggested_minutes,omitempty"`
+}
+
+// ChildTimeStats is how long a child's timed chores took against their
+// estimates
+type ChildTimeStats struct {
+	ID               string  `json:"id"`
+	Name             string  `json:"name"`
+	Timed            int     `json:"timed"`
+	ActualMinutes    float64 `json:"actual_minutes"`
+	EstimatedMinutes int     `json:"estimated_minutes"` // for the timed chores that have an estimate
+	Ratio            float64 `json:"ratio"`             // actual / estimated for those chores; 0 without estimates
+}
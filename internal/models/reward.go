@@ -24,6 +24,7 @@ type Reward struct {
 	UpdatedAt              time.Time  `json:"updated_at" db:"updated_at"`
 	CreatedBy              *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
 	UpdatedBy              *uuid.UUID `json:"updated_by,omitempty" db:"updated_by"`
+	Version                int        `json:"version" db:"version"`
 }
 
 // RewardListResponse is the simplified response for reward lists
@@ -39,6 +40,7 @@ type RewardListResponse struct {
 	Active                 bool      `json:"active"`
 	Availability           string    `json:"availability"`
 	StockRemaining         *int      `json:"stock_remaining,omitempty"`
+	Version                int       `json:"version"`
 	UserRedemptionCount    int       `json:"user_redemption_count"` // How many times current user has redeemed
 }
 
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RotationLoad is a child's accumulated work over the rolling window
+type RotationLoad struct {
+	Minutes     int `json:"minutes"`
+	Points      int `json:"points"`
+	Assignments int `json:"assignments"`
+}
+
+// RotationCandidate explains how a single child was evaluated for a chore
+type RotationCandidate struct {
+	UserID      uuid.UUID    `json:"user_id"`
+	DisplayName string       `json:"display_name"`
+	Eligible    bool         `json:"eligible"`
+	Reason      string       `json:"reason"`
+	Load        RotationLoad `json:"load"`
+	Score       float64      `json:"score"`
+}
+
+// RotationDecision is the outcome for one chore, including every candidate considered
+type RotationDecision struct {
+	ChoreID    uuid.UUID           `json:"chore_id"`
+	ChoreName  string              `json:"chore_name"`
+	AssignedTo *uuid.UUID          `json:"assigned_to,omitempty"`
+	Reason     string              `json:"reason"`
+	Candidates []RotationCandidate `json:"candidates"`
+}
+
+// RotationRunRequest is the request body for POST /api/rotation/run
+type RotationRunRequest struct {
+	StartDate  *string     `json:"start_date,omitempty"` // YYYY-MM-DD, defaults to today
+	Days       int         `json:"days"`                 // Number of days to generate, defaults to 1
+	WindowDays int         `json:"window_days"`          // Rolling balance window, defaults to 14
+	ChoreIDs   []uuid.UUID `json:"chore_ids,omitempty"`  // Limit the run to specific chores
+	DryRun     bool        `json:"dry_run"`              // Compute decisions without creating assignments
+}
+
+// RotationDay is the set of decisions made for a single date
+type RotationDay struct {
+	Date          string              `json:"date"`
+	KidsPresent   []string            `json:"kids_present,omitempty"`
+	ScheduleKnown bool                `json:"schedule_known"`
+	Skipped       []string            `json:"skipped,omitempty"` // Chores already assigned that day
+	Decisions     []RotationDayResult `json:"decisions"`
+}
+
+// RotationDayResult pairs an engine decision with the assignment it created
+type RotationDayResult struct {
+	RotationDecision
+	AssignmentID *uuid.UUID `json:"assignment_id,omitempty"`
+}
+
+// RotationRunResponse is the response for POST /api/rotation/run
+type RotationRunResponse struct {
+	RunID      uuid.UUID     `json:"run_id"`
+	DryRun     bool          `json:"dry_run"`
+	WindowDays int           `json:"window_days"`
+	Days       []RotationDay `json:"days"`
+	Created    int           `json:"created"`
+}
+
+// RotationDecisionRecord is a stored explanation returned by GET /api/rotation/decisions
+type RotationDecisionRecord struct {
+	ID             uuid.UUID           `json:"id"`
+	RunID          uuid.UUID           `json:"run_id"`
+	AssignmentID   *uuid.UUID          `json:"assignment_id,omitempty"`
+	ChoreID        uuid.UUID           `json:"chore_id"`
+	ChoreName      string              `json:"chore_name"`
+	AssignedTo     *uuid.UUID          `json:"assigned_to,omitempty"`
+	AssignedToName *string             `json:"assigned_to_name,omitempty"`
+	AssignmentDate string              `json:"assignment_date"`
+	WindowDays     int                 `json:"window_days"`
+	Reason         string              `json:"reason"`
+	Candidates     []RotationCandidate `json:"candidates"`
+	CreatedAt      time.Time           `json:"created_at"`
+}
@@ -31,3 +31,31 @@ type FamilyScheduleResponse struct {
 	Schedule  []FamilyScheduleEntry  `json:"schedule"`
 	TotalDays int                    `json:"total_days"`
 }
+
+// PresenceAdjustment records how an assignment for an absent child was handled
+type PresenceAdjustment struct {
+	AssignmentID uuid.UUID  `json:"assignment_id"`
+	ChoreID      uuid.UUID  `json:"chore_id"`
+	ChoreName    string     `json:"chore_name"`
+	Action       string     `json:"action"` // reassigned, deferred, unresolved
+	FromUserID   uuid.UUID  `json:"from_user_id"`
+	ToUserID     *uuid.UUID `json:"to_user_id,omitempty"`
+	FromDueDate  string     `json:"from_due_date"`
+	ToDueDate    *string    `json:"to_due_date,omitempty"`
+	Reason       string     `json:"reason"`
+}
+
+// ScheduleReconcileRequest is the request body for POST /api/schedule/reconcile
+type ScheduleReconcileRequest struct {
+	StartDate *string `json:"start_date,omitempty"` // YYYY-MM-DD, defaults to today
+	Days      int     `json:"days"`                 // Defaults to 7
+	DryRun    bool    `json:"dry_run"`
+}
+
+// ScheduleReconcileResponse is the response for POST /api/schedule/reconcile
+type ScheduleReconcileResponse struct {
+	StartDate   string               `json:"start_date"`
+	EndDate     string               `json:"end_date"`
+	DryRun      bool                 `json:"dry_run"`
+	Adjustments []PresenceAdjustment `json:"adjustments"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AssignmentTrade is an assignment one child has offered to their siblings
+type AssignmentTrade struct {
+	ID                   uuid.UUID    `json:"id"`
+	AssignmentID         uuid.UUID    `json:"assignment_id"`
+	ChoreName            string       `json:"chore_name"`
+	DueDate              *time.Time   `json:"due_date,omitempty"`
+	PointsOffered        int          `json:"points_offered"`
+	OfferedBy            uuid.UUID    `json:"offered_by"`
+	OfferedByName        string       `json:"offered_by_name"`
+	SweetenerPoints      int          `json:"sweetener_points"`
+	Note                 *string      `json:"note,omitempty"`
+	Status               string       `json:"status"` // open, pending_approval, completed, rejected, cancelled
+	AcceptedBy           *uuid.UUID   `json:"accepted_by,omitempty"`
+	AcceptedByName       *string      `json:"accepted_by_name,omitempty"`
+	AcceptedAt           *time.Time   `json:"accepted_at,omitempty"`
+	ExchangeAssignmentID *uuid.UUID   `json:"exchange_assignment_id,omitempty"`
+	ExchangeChoreName    *string      `json:"exchange_chore_name,omitempty"`
+	DecidedBy            *uuid.UUID   `json:"decided_by,omitempty"`
+	DecidedAt            *time.Time   `json:"decided_at,omitempty"`
+	DecisionNotes        *string      `json:"decision_notes,omitempty"`
+	CreatedAt            time.Time    `json:"created_at"`
+	UpdatedAt            time.Time    `json:"updated_at"`
+	Events               []TradeEvent `json:"events,omitempty"`
+}
+
+// TradeEvent is one step of a trade negotiation
+type TradeEvent struct {
+	Event     string     `json:"event"`
+	ActorID   *uuid.UUID `json:"actor_id,omitempty"`
+	ActorName *string    `json:"actor_name,omitempty"`
+	Notes     *string    `json:"notes,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TradeOfferRequest is the request body for POST /api/assignments/:id/trade
+type TradeOfferRequest struct {
+	SweetenerPoints int     `json:"sweetener_points" binding:"min=0"`
+	Note            *string `json:"note,omitempty"`
+}
+
+// TradeAcceptRequest is the request body for POST /api/trades/:id/accept.
+// ExchangeAssignmentID turns the trade into a swap: that assignment goes to
+// the child who made the offer.
+type TradeAcceptRequest struct {
+	ExchangeAssignmentID *uuid.UUID `json:"exchange_assignment_id,omitempty"`
+	Note                 *string    `json:"note,omitempty"`
+}
+
+// TradeDecisionRequest is the request body for the trade approve, reject and
+// cancel endpoints
+type TradeDecisionRequest struct {
+	Notes *string `json:"notes,omitempty"`
+}
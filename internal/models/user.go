@@ -63,6 +63,7 @@ type UserUpdateRequest struct {
 	LoginEnabled              *bool   `json:"login_enabled,omitempty"`
 	AvailabilityNotifications *bool   `json:"availability_notifications,omitempty"`
 	AutoApproveWork           *bool   `json:"auto_approve_work,omitempty"`
+	TrustLevel                *string `json:"trust_level,omitempty" binding:"omitempty,oneof=standard high"`
 	PhoneNumber               *string `json:"phone_number,omitempty"`
 	School                    *string `json:"school,omitempty"`
 	Notes                     *string `json:"notes,omitempty"`
@@ -0,0 +1,82 @@
+// Package notify stores in-app notifications for family members
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/google/uuid"
+)
+
+// Notification types
+const (
+	TypeRework     = "assignment_rework"
+	TypeReady      = "assignment_ready"
+	TypeTrade      = "assignment_trade"
+	TypeAdjustment = "point_adjustment"
+	TypeAllowance  = "allowance"
+)
+
+// Notification is a message for a single user
+type Notification struct {
+	UserID       uuid.UUID
+	Type         string
+	Title        string
+	Body         string
+	AssignmentID *uuid.UUID
+}
+
+// Send stores a notification in the family's notifications table. The body
+// goes in message and the assignment, if any, in metadata. It runs in the
+// caller's transaction so the notification only appears if the change it
+// describes is committed.
+func Send(ctx context.Context, q database.Querier, n Notification) error {
+	metadata := map[string]interface{}{}
+	if n.AssignmentID != nil {
+		metadata["assignment_id"] = *n.AssignmentID
+	}
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification metadata: %w", err)
+	}
+
+	_, err = q.Exec(ctx, `
+		INSERT INTO notifications (id, user_id, type, title, message, read, metadata, created_at)
+		VALUES ($1, $2, $3, $4, $5, false, $6, NOW())
+	`, uuid.New(), n.UserID, n.Type, n.Title, n.Body, metadataJSON)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	return nil
+}
+
+// Parents sends a copy of the notification to every active parent
+func Parents(ctx context.Context, q database.Querier, n Notification) error {
+	rows, err := q.Query(ctx, "SELECT id FROM users WHERE is_parent = true AND is_active = true")
+	if err != nil {
+		return fmt.Errorf("failed to query parents: %w", err)
+	}
+	parents := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		parents = append(parents, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range parents {
+		n.UserID = id
+		if err := Send(ctx, q, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Package packs holds chore packs: portable sets of chores a family can
+// import to get started, or export to share. A set of age-appropriate packs
+// is built into the binary.
+package packs
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/JunoAX/housepoints-go/internal/models"
+)
+
+// Format is the pack format version this server reads and writes
+const Format = 1
+
+// Ways to handle a pack chore whose name matches an existing chore
+const (
+	ConflictSkip    = "skip"
+	ConflictRename  = "rename"
+	ConflictReplace = "replace"
+)
+
+//go:embed builtin/*.json
+var builtinFS embed.FS
+
+var (
+	loadOnce sync.Once
+	builtin  []models.ChorePack
+	loadErr  error
+)
+
+// Builtin returns the packs built into the binary, youngest first
+func Builtin() ([]models.ChorePack, error) {
+	loadOnce.Do(func() {
+		entries, err := builtinFS.ReadDir("builtin")
+		if err != nil {
+			loadErr = err
+			return
+		}
+		for _, entry := range entries {
+			data, err := builtinFS.ReadFile(path.Join("builtin", entry.Name()))
+			if err != nil {
+				loadErr = err
+				return
+			}
+			var pack models.ChorePack
+			if err := json.Unmarshal(data, &pack); err != nil {
+				loadErr = fmt.Errorf("built-in pack %s: %w", entry.Name(), err)
+				return
+			}
+			if err := Validate(pack); err != nil {
+				loadErr = fmt.Errorf("built-in pack %s: %w", entry.Name(), err)
+				return
+			}
+			builtin = append(builtin, pack)
+		}
+		sort.SliceStable(builtin, func(i, j int) bool {
+			return builtin[i].MinAge < builtin[j].MinAge
+		})
+	})
+	return builtin, loadErr
+}
+
+// Get returns a built-in pack by ID
+func Get(id string) (models.ChorePack, bool, error) {
+	all, err := Builtin()
+	if err != nil {
+		return models.ChorePack{}, false, err
+	}
+	for _, pack := range all {
+		if pack.ID == id {
+			return pack, true, nil
+		}
+	}
+	return models.ChorePack{}, false, nil
+}
+
+// Summarize describes a pack without its chores
+func Summarize(pack models.ChorePack) models.ChorePackSummary {
+	return models.ChorePackSummary{
+		ID:          pack.ID,
+		Name:        pack.Name,
+		Description: pack.Description,
+		MinAge:      pack.MinAge,
+		MaxAge:      pack.MaxAge,
+		ChoreCount:  len(pack.Chores),
+	}
+}
+
+// Validate checks what request binding cannot: the format version and
+// duplicate chore names within the pack
+func Validate(pack models.ChorePack) error {
+	if pack.Format != Format {
+		return fmt.Errorf("unsupported pack format %d, expected %d", pack.Format, Format)
+	}
+	if strings.TrimSpace(pack.Name) == "" {
+		return fmt.Errorf("pack name is required")
+	}
+	if len(pack.Chores) == 0 {
+		return fmt.Errorf("pack has no chores")
+	}
+	seen := map[string]bool{}
+	for _, chore := range pack.Chores {
+		key := NameKey(chore.Name)
+		if key == "" {
+			return fmt.Errorf("every chore needs a name")
+		}
+		if seen[key] {
+			return fmt.Errorf("chore %q appears more than once", chore.Name)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// NameKey is the form used to compare chore names: case and surrounding
+// spaces are ignored
+func NameKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// UniqueName returns name, or name with the lowest free "(n)" suffix if it is
+// already taken
+func UniqueName(name string, taken map[string]bool) string {
+	if !taken[NameKey(name)] {
+		return name
+	}
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", name, n)
+		if !taken[NameKey(candidate)] {
+			return candidate
+		}
+	}
+}
+
+// CreateRequest turns a pack chore into a chore create request
+func CreateRequest(chore models.PackChore) models.ChoreCreateRequest {
+	req := models.ChoreCreateRequest{
+		Name:                 strings.TrimSpace(chore.Name),
+		Description:          chore.Description,
+		Instructions:         chore.Instructions,
+		Category:             chore.Category,
+		BasePoints:           chore.SuggestedPoints,
+		EstimatedMinutes:     chore.EstimatedMinutes,
+		Difficulty:           chore.Difficulty,
+		Frequency:            chore.Frequency,
+		Tags:                 chore.Tags,
+		RotationEligible:     chore.RotationEligible,
+		RequiresPhoto:        chore.RequiresPhoto,
+		RequiresVerification: chore.RequiresVerification,
+	}
+	if chore.Icon != "" {
+		req.Icon = &chore.Icon
+	}
+	if chore.MinAge > 0 {
+		req.MinAge = &chore.MinAge
+	}
+	return req
+}
+
+// FromChore turns a family's chore into a pack chore for export
+func FromChore(chore models.Chore) models.PackChore {
+	return models.PackChore{
+		Name:                 chore.Name,
+		Description:          chore.Description,
+		Instructions:         chore.Instructions,
+		Category:             chore.Category,
+		Icon:                 chore.Icon,
+		Tags:                 chore.Tags,
+		SuggestedPoints:      chore.BasePoints,
+		EstimatedMinutes:     chore.EstimatedMinutes,
+		Difficulty:           chore.Difficulty,
+		MinAge:               chore.MinAge,
+		Frequency:            chore.Frequency,
+		RotationEligible:     chore.RotationEligible,
+		RequiresPhoto:        chore.RequiresPhoto,
+		RequiresVerification: chore.RequiresVerification,
+	}
+}
@@ -0,0 +1,74 @@
+package photos
+
+import "encoding/binary"
+
+// exifOrientation returns the orientation tag from a JPEG's EXIF segment, or
+// 1 (normal) if there is none
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	// Walk the JPEG segments looking for APP1 "Exif"
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			// Start of scan or end of image; metadata comes before these
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		start, end := pos+4, pos+2+length
+		if length < 2 || end > len(data) {
+			return 1
+		}
+
+		if marker == 0xE1 && end-start > 6 && string(data[start:start+6]) == "Exif\x00\x00" {
+			return tiffOrientation(data[start+6 : end])
+		}
+		pos = end
+	}
+	return 1
+}
+
+// tiffOrientation reads tag 0x0112 from the first IFD of a TIFF header
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			value := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if value >= 1 && value <= 8 {
+				return value
+			}
+			return 1
+		}
+	}
+	return 1
+}
@@ -0,0 +1,210 @@
+// Package photos normalizes uploaded chore photos. Images are decoded and
+// re-encoded as JPEG, which drops EXIF and any other embedded metadata
+// (including GPS location), after applying the EXIF orientation so the
+// result still displays upright.
+package photos
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+
+	// Register decoders for accepted upload formats
+	_ "image/gif"
+	_ "image/png"
+)
+
+const (
+	// MaxUploadBytes is the largest accepted upload
+	MaxUploadBytes = 10 << 20
+	// MaxPixels bounds the decoded size of an upload, which a small
+	// compressed file can make very large
+	MaxPixels = 40_000_000
+	// MaxDimension bounds the longest side of stored photos
+	MaxDimension = 2048
+	// ThumbnailDimension bounds the longest side of thumbnails
+	ThumbnailDimension = 320
+
+	jpegQuality = 85
+)
+
+var (
+	// ErrTooLarge is returned for uploads over MaxUploadBytes
+	ErrTooLarge = errors.New("photo exceeds maximum upload size")
+	// ErrTooManyPixels is returned for images over MaxPixels
+	ErrTooManyPixels = errors.New("photo exceeds maximum dimensions")
+	// ErrInvalidImage is returned when an upload cannot be decoded
+	ErrInvalidImage = errors.New("unsupported or corrupt image")
+)
+
+// Processed is a sanitized photo and its thumbnail, both JPEG encoded
+type Processed struct {
+	Photo     []byte
+	Thumbnail []byte
+	Width     int
+	Height    int
+}
+
+// Process decodes an uploaded image, corrects its orientation, strips all
+// metadata and produces a bounded-size photo and a thumbnail
+func Process(r io.Reader) (*Processed, error) {
+	raw, err := io.ReadAll(io.LimitReader(r, MaxUploadBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > MaxUploadBytes {
+		return nil, ErrTooLarge
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	if format == "jpeg" {
+		img = orient(img, exifOrientation(raw))
+	}
+
+	photo := resize(img, MaxDimension)
+	thumb := resize(photo, ThumbnailDimension)
+
+	photoBytes, err := encode(photo)
+	if err != nil {
+		return nil, err
+	}
+	thumbBytes, err := encode(thumb)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := photo.Bounds()
+	return &Processed{
+		Photo:     photoBytes,
+		Thumbnail: thumbBytes,
+		Width:     bounds.Dx(),
+		Height:    bounds.Dy(),
+	}, nil
+}
+
+// encode writes img as JPEG, flattening any transparency onto white
+func encode(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, bounds.Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resize scales img down so its longest side is at most max, averaging the
+// source pixels covered by each destination pixel
+func resize(img image.Image, max int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= max && h <= max {
+		return img
+	}
+
+	dw, dh := max, h*max/w
+	if h > w {
+		dw, dh = w*max/h, max
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		sy0 := bounds.Min.Y + y*h/dh
+		sy1 := bounds.Min.Y + (y+1)*h/dh
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < dw; x++ {
+			sx0 := bounds.Min.X + x*w/dw
+			sx1 := bounds.Min.X + (x+1)*w/dw
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
+
+// orient applies an EXIF orientation (1-8) so the image displays upright
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirror horizontal
+				dx, dy = w-1-x, y
+			case 3: // rotate 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirror vertical
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // rotate 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotate 90 counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,72 @@
+// Package reports holds report queries shared by handlers and background jobs
+package reports
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/models"
+)
+
+// CompletionRate returns completed as a percentage of total, rounded down to
+// one decimal, or 0 when there is nothing to complete
+func CompletionRate(completed, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	rate := float64(completed) / float64(total) * 100
+	return float64(int(rate*10)) / 10
+}
+
+// ChildWeekly returns each active child's assignments due from from up to,
+// but not including, to, most points first. Pass family-local midnights to
+// cover whole days.
+func ChildWeekly(ctx context.Context, q database.Querier, from, to time.Time) ([]models.ChildWeeklyPerformance, error) {
+	rows, err := q.Query(ctx, `
+		SELECT
+			u.id,
+			u.username,
+			u.display_name,
+			u.color_theme,
+			COUNT(a.id) as total_assignments,
+			COUNT(CASE WHEN a.status = 'completed' OR a.status = 'verified' THEN 1 END) as completed,
+			COUNT(CASE WHEN a.status = 'verified' THEN 1 END) as verified,
+			COUNT(CASE WHEN a.status = 'pending_verification' THEN 1 END) as awaiting_review,
+			COALESCE(SUM(CASE WHEN a.status = 'verified' THEN c.base_points ELSE 0 END), 0) as points_earned
+		FROM users u
+		LEFT JOIN assignments a ON u.id = a.assigned_to
+			AND a.due_date >= $1 AND a.due_date < $2
+		LEFT JOIN chores c ON a.chore_id = c.id
+		WHERE u.is_parent = false AND u.is_active = true
+		GROUP BY u.id, u.username, u.display_name, u.color_theme
+		ORDER BY points_earned DESC
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query children: %w", err)
+	}
+	defer rows.Close()
+
+	children := []models.ChildWeeklyPerformance{}
+	for rows.Next() {
+		var child models.ChildWeeklyPerformance
+		err := rows.Scan(
+			&child.ID,
+			&child.Username,
+			&child.DisplayName,
+			&child.ColorTheme,
+			&child.TotalAssignments,
+			&child.Completed,
+			&child.Verified,
+			&child.AwaitingReview,
+			&child.PointsEarned,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse child: %w", err)
+		}
+		child.CompletionRate = CompletionRate(child.Completed, child.TotalAssignments)
+		children = append(children, child)
+	}
+	return children, rows.Err()
+}
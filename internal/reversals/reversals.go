@@ -0,0 +1,364 @@
+// Package reversals undoes point transactions. A reversal posts a
+// compensating transaction of the same type with the opposite points that
+// names the original in reverses_transaction_id, so every balance moves back,
+// and restores what
+// the original changed: a verified assignment goes back to waiting for
+// verification, and a redemption is rejected with its reward's stock
+// returned.
+package reversals
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/ledger"
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// Redemption statuses this package reads and writes
+const (
+	redemptionPending  = "pending"
+	redemptionRejected = "rejected"
+)
+
+var (
+	// ErrIsReversal is returned for a transaction that reverses another
+	ErrIsReversal = errors.New("transaction is itself a reversal")
+	// ErrAlreadyReversed is returned for a transaction reversed before
+	ErrAlreadyReversed = errors.New("transaction has already been reversed")
+	// ErrRedemptionProcessed is returned with PendingOnly when a parent has
+	// already acted on the redemption
+	ErrRedemptionProcessed = errors.New("redemption has already been processed")
+	// ErrNotReversible is returned for a transaction that moved money as well
+	// as points: a conversion, or a scheduled allowance deposit
+	ErrNotReversible = errors.New("transaction cannot be reversed")
+)
+
+// verificationTypes are the lines written when an assignment is verified.
+// They are reversed together, along with the verification itself.
+var verificationTypes = []string{
+	ledger.TypeChoreCompletion,
+	ledger.TypeQualityBonus,
+	ledger.TypeEarlyBonus,
+	ledger.TypeEffortBonus,
+}
+
+func isVerification(transactionType string) bool {
+	for _, t := range verificationTypes {
+		if t == transactionType {
+			return true
+		}
+	}
+	return false
+}
+
+// Request describes one reversal
+type Request struct {
+	TransactionID uuid.UUID
+	ActorID       uuid.UUID
+	Reason        *string
+	// Force reverses even if a balance would go below zero
+	Force bool
+	// PendingOnly refuses to reverse a redemption that is no longer pending
+	PendingOnly bool
+}
+
+const selectTransactions = `
+	SELECT
+		id, user_id, points, transaction_type, description,
+		related_assignment_id, related_user_id, extra_data::text, created_at, reverses_transaction_id
+	FROM point_transactions pt
+`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTransaction(row scanner) (models.PointTransaction, error) {
+	var pt models.PointTransaction
+	err := row.Scan(
+		&pt.ID, &pt.UserID, &pt.Points, &pt.TransactionType, &pt.Description,
+		&pt.RelatedAssignmentID, &pt.RelatedUserID, &pt.ExtraData, &pt.CreatedAt, &pt.ReversesTransactionID,
+	)
+	return pt, err
+}
+
+// checkReversible refuses transactions whose other half is in the money
+// ledger. Undoing only the points would leave the money in place.
+func checkReversible(ctx context.Context, q database.Querier, pt models.PointTransaction) error {
+	switch pt.TransactionType {
+	case ledger.TypeConversion:
+		return ErrNotReversible
+	case ledger.TypeAllowance:
+		var deposit bool
+		err := q.QueryRow(ctx,
+			"SELECT EXISTS(SELECT 1 FROM allowance_deposits WHERE point_transaction_id = $1)",
+			pt.ID,
+		).Scan(&deposit)
+		if err != nil {
+			return fmt.Errorf("failed to check allowance deposits: %w", err)
+		}
+		if deposit {
+			return ErrNotReversible
+		}
+	}
+	return nil
+}
+
+// Load returns a transaction. A missing transaction returns the driver's no
+// rows error.
+func Load(ctx context.Context, q database.Querier, id uuid.UUID) (models.PointTransaction, error) {
+	return scanTransaction(q.QueryRow(ctx, selectTransactions+" WHERE pt.id = $1", id))
+}
+
+// Reverse undoes a transaction and the rest of its group in the caller's
+// transaction. Negative compensating entries fail with
+// ledger.ErrInsufficientPoints unless forced.
+func Reverse(ctx context.Context, q database.Querier, req Request) (models.TransactionReversal, error) {
+	result := models.TransactionReversal{
+		TransactionID: req.TransactionID,
+		Reversals:     []models.PointTransactionResponse{},
+		Forced:        req.Force,
+	}
+
+	original, err := scanTransaction(q.QueryRow(ctx, selectTransactions+" WHERE pt.id = $1 FOR UPDATE", req.TransactionID))
+	if err != nil {
+		return result, err
+	}
+	if original.ReversesTransactionID != nil {
+		return result, ErrIsReversal
+	}
+	if err := checkReversible(ctx, q, original); err != nil {
+		return result, err
+	}
+
+	var reversed bool
+	err = q.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM point_transactions WHERE reverses_transaction_id = $1)",
+		original.ID,
+	).Scan(&reversed)
+	if err != nil {
+		return result, fmt.Errorf("failed to check reversals: %w", err)
+	}
+	if reversed {
+		return result, ErrAlreadyReversed
+	}
+
+	group, err := loadGroup(ctx, q, original)
+	if err != nil {
+		return result, err
+	}
+
+	// Side effects first, so a stale assignment or redemption fails the
+	// reversal before any points move
+	verification := isVerification(original.TransactionType) && original.RelatedAssignmentID != nil
+	if verification {
+		total := 0
+		for _, pt := range group {
+			total += pt.Points
+		}
+		if err := reopenAssignment(ctx, q, *original.RelatedAssignmentID, req, -total); err != nil {
+			return result, err
+		}
+		status := lifecycle.StatusPendingVerification
+		result.AssignmentID = original.RelatedAssignmentID
+		result.AssignmentStatus = &status
+	}
+	if original.TransactionType == ledger.TypeRewardRedemption {
+		redemptionID, restored, err := cancelRedemption(ctx, q, original, req)
+		if err != nil {
+			return result, err
+		}
+		result.RedemptionID = redemptionID
+		result.StockRestored = restored
+	}
+
+	for _, pt := range group {
+		extra := map[string]interface{}{
+			"reversal_of": pt.ID,
+			"reversed_by": req.ActorID,
+			"forced":      req.Force,
+		}
+		if req.Reason != nil {
+			extra["reason"] = *req.Reason
+		}
+
+		id, err := ledger.Post(ctx, q, ledger.Entry{
+			UserID:        pt.UserID,
+			Points:        -pt.Points,
+			Type:          pt.TransactionType,
+			Description:   fmt.Sprintf("Reversed: %s", pt.Description),
+			AssignmentID:  pt.RelatedAssignmentID,
+			RelatedUserID: pt.RelatedUserID,
+			ExtraData:     extra,
+			Reverses:      &pt.ID,
+			NoOverdraft:   !req.Force,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		posted, err := Load(ctx, q, id)
+		if err != nil {
+			return result, fmt.Errorf("failed to query reversal: %w", err)
+		}
+		result.Reversals = append(result.Reversals, posted.ToResponse())
+	}
+
+	return result, nil
+}
+
+// loadGroup locks and returns the transactions reversed together with
+// original: every live line of a verification, or both sides of a
+// sweetener, which were written in one database transaction
+func loadGroup(ctx context.Context, q database.Querier, original models.PointTransaction) ([]models.PointTransaction, error) {
+	const live = `
+		AND pt.reverses_transaction_id IS NULL
+		AND NOT EXISTS (SELECT 1 FROM point_transactions r WHERE r.reverses_transaction_id = pt.id)
+	`
+
+	var (
+		query  string
+		params []interface{}
+	)
+	switch {
+	case isVerification(original.TransactionType) && original.RelatedAssignmentID != nil:
+		query = selectTransactions + `
+			WHERE pt.related_assignment_id = $1
+				AND pt.transaction_type = ANY($2)
+		` + live + " ORDER BY pt.created_at, pt.id FOR UPDATE"
+		params = []interface{}{*original.RelatedAssignmentID, verificationTypes}
+	case original.TransactionType == ledger.TypeTradeSweetener && original.RelatedAssignmentID != nil:
+		query = selectTransactions + `
+			WHERE pt.related_assignment_id = $1
+				AND pt.transaction_type = $2
+				AND pt.created_at = $3
+		` + live + " ORDER BY pt.points, pt.id FOR UPDATE"
+		params = []interface{}{*original.RelatedAssignmentID, original.TransactionType, original.CreatedAt}
+	default:
+		return []models.PointTransaction{original}, nil
+	}
+
+	rows, err := q.Query(ctx, query, params...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	group := []models.PointTransaction{}
+	for rows.Next() {
+		pt, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse transaction: %w", err)
+		}
+		group = append(group, pt)
+	}
+	return group, rows.Err()
+}
+
+// reopenAssignment sends a verified assignment back to waiting for
+// verification and clears its review, so it can be verified again
+func reopenAssignment(ctx context.Context, q database.Querier, assignmentID uuid.UUID, req Request, pointsDelta int) error {
+	err := lifecycle.ReverseVerification(ctx, q, lifecycle.Change{
+		AssignmentID: assignmentID,
+		ActorID:      &req.ActorID,
+		Notes:        req.Reason,
+		PointsDelta:  pointsDelta,
+		Metadata:     map[string]interface{}{"transaction_id": req.TransactionID},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, `
+		UPDATE assignments
+		SET verified_at = NULL,
+			quality_rating = NULL,
+			partial_credit_percent = NULL,
+			updated_at = NOW()
+		WHERE id = $1
+	`, assignmentID)
+	if err != nil {
+		return fmt.Errorf("failed to reopen assignment: %w", err)
+	}
+
+	_, err = q.Exec(ctx,
+		"UPDATE assignment_participants SET points_earned = NULL WHERE assignment_id = $1",
+		assignmentID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to clear participant points: %w", err)
+	}
+	return nil
+}
+
+// cancelRedemption rejects the redemption a transaction paid for and returns
+// the reward to stock. Redemptions from before transactions recorded their
+// redemption_id are matched by user, points and time. It reports the
+// redemption, if one was found, and whether stock was restored.
+func cancelRedemption(ctx context.Context, q database.Querier, pt models.PointTransaction, req Request) (*uuid.UUID, bool, error) {
+	var (
+		id       uuid.UUID
+		rewardID uuid.UUID
+		status   string
+	)
+	err := q.QueryRow(ctx, `
+		SELECT rr.id, rr.reward_id, rr.status
+		FROM reward_redemptions rr
+		WHERE rr.id = ($1::jsonb ->> 'redemption_id')::uuid
+			OR ($1::jsonb ->> 'redemption_id' IS NULL
+				AND rr.user_id = $2
+				AND rr.points_spent = $3
+				AND rr.created_at BETWEEN $4::timestamptz - INTERVAL '5 seconds' AND $4::timestamptz + INTERVAL '5 seconds')
+		ORDER BY ABS(EXTRACT(EPOCH FROM rr.created_at - $4::timestamptz))
+		LIMIT 1
+		FOR UPDATE
+	`, pt.ExtraData, pt.UserID, -pt.Points, pt.CreatedAt).Scan(&id, &rewardID, &status)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			if req.PendingOnly {
+				return nil, false, ErrRedemptionProcessed
+			}
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to query redemption: %w", err)
+	}
+
+	if req.PendingOnly && status != redemptionPending {
+		return nil, false, ErrRedemptionProcessed
+	}
+	if status == redemptionRejected {
+		return &id, false, nil
+	}
+
+	notes := "Reversed"
+	if req.Reason != nil && *req.Reason != "" {
+		notes = fmt.Sprintf("Reversed: %s", *req.Reason)
+	}
+	_, err = q.Exec(ctx, `
+		UPDATE reward_redemptions
+		SET status = $1,
+			parent_notes = $2,
+			processed_at = NOW(),
+			processed_by = $3
+		WHERE id = $4
+	`, redemptionRejected, notes, req.ActorID, id)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to cancel redemption: %w", err)
+	}
+
+	result, err := q.Exec(ctx, `
+		UPDATE rewards
+		SET stock_remaining = stock_remaining + 1,
+			updated_at = NOW()
+		WHERE id = $1 AND stock_remaining IS NOT NULL
+	`, rewardID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to restore stock: %w", err)
+	}
+	return &id, result.RowsAffected() > 0, nil
+}
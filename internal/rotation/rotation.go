@@ -0,0 +1,171 @@
+package rotation
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// Child is a rotation candidate as seen on a single day
+type Child struct {
+	ID          uuid.UUID
+	Username    string
+	DisplayName string
+	Age         *int
+	Birthdate   *time.Time
+	Present     bool
+}
+
+// Chore is a rotation-eligible chore to be distributed
+type Chore struct {
+	ID               uuid.UUID
+	Name             string
+	MinAge           int
+	EstimatedMinutes int
+	Points           int
+	// LastAssigned holds the most recent date each child had this chore
+	LastAssigned map[uuid.UUID]time.Time
+}
+
+// Assign distributes chores among children, balancing estimated minutes and
+// points over the rolling window. Loads are updated in place so later chores
+// see the work handed out earlier in the same run.
+func Assign(children []Child, chores []Chore, loads map[uuid.UUID]*models.RotationLoad) []models.RotationDecision {
+	for _, child := range children {
+		if loads[child.ID] == nil {
+			loads[child.ID] = &models.RotationLoad{}
+		}
+	}
+
+	// Hand out the heaviest chores first so they land on the lightest loads
+	ordered := make([]Chore, len(chores))
+	copy(ordered, chores)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].EstimatedMinutes != ordered[j].EstimatedMinutes {
+			return ordered[i].EstimatedMinutes > ordered[j].EstimatedMinutes
+		}
+		if ordered[i].Points != ordered[j].Points {
+			return ordered[i].Points > ordered[j].Points
+		}
+		return ordered[i].Name < ordered[j].Name
+	})
+
+	decisions := make([]models.RotationDecision, 0, len(ordered))
+	for _, chore := range ordered {
+		decisions = append(decisions, assignOne(children, chore, loads))
+	}
+	return decisions
+}
+
+func assignOne(children []Child, chore Chore, loads map[uuid.UUID]*models.RotationLoad) models.RotationDecision {
+	totalMinutes, totalPoints := 0, 0
+	for _, child := range children {
+		totalMinutes += loads[child.ID].Minutes
+		totalPoints += loads[child.ID].Points
+	}
+
+	candidates := make([]models.RotationCandidate, 0, len(children))
+	var best *models.RotationCandidate
+	var bestLast time.Time
+
+	for _, child := range children {
+		load := *loads[child.ID]
+		candidate := models.RotationCandidate{
+			UserID:      child.ID,
+			DisplayName: child.DisplayName,
+			Load:        load,
+		}
+
+		switch {
+		case !child.Present:
+			candidate.Reason = "not present according to family schedule"
+		case chore.MinAge > 0 && child.Age == nil:
+			candidate.Reason = fmt.Sprintf("age unknown, chore requires age %d+", chore.MinAge)
+		case child.Age != nil && *child.Age < chore.MinAge:
+			candidate.Reason = fmt.Sprintf("age %d is below minimum age %d", *child.Age, chore.MinAge)
+		default:
+			candidate.Eligible = true
+			candidate.Score = score(load, totalMinutes, totalPoints)
+		}
+
+		candidates = append(candidates, candidate)
+		if !candidate.Eligible {
+			continue
+		}
+
+		last := chore.LastAssigned[child.ID]
+		if best == nil || better(candidate, last, *best, bestLast) {
+			c := candidate
+			best = &c
+			bestLast = last
+		}
+	}
+
+	decision := models.RotationDecision{
+		ChoreID:    chore.ID,
+		ChoreName:  chore.Name,
+		Candidates: candidates,
+	}
+
+	if best == nil {
+		decision.Reason = "no eligible child (all absent or under minimum age)"
+		return decision
+	}
+
+	id := best.UserID
+	decision.AssignedTo = &id
+	decision.Reason = fmt.Sprintf(
+		"lowest load in window (%d min, %d pts across %d assignments)",
+		best.Load.Minutes, best.Load.Points, best.Load.Assignments,
+	)
+	if !bestLast.IsZero() {
+		decision.Reason += fmt.Sprintf("; last had this chore %s", bestLast.Format("2006-01-02"))
+	}
+
+	load := loads[id]
+	load.Minutes += chore.EstimatedMinutes
+	load.Points += chore.Points
+	load.Assignments++
+
+	return decision
+}
+
+// score weighs a child's share of minutes and points equally
+func score(load models.RotationLoad, totalMinutes, totalPoints int) float64 {
+	s := 0.0
+	if totalMinutes > 0 {
+		s += float64(load.Minutes) / float64(totalMinutes)
+	}
+	if totalPoints > 0 {
+		s += float64(load.Points) / float64(totalPoints)
+	}
+	return s
+}
+
+// better reports whether candidate a should win over b. Ties on score go to
+// whoever had the chore least recently, then to the earlier name.
+func better(a models.RotationCandidate, aLast time.Time, b models.RotationCandidate, bLast time.Time) bool {
+	if a.Score != b.Score {
+		return a.Score < b.Score
+	}
+	if !aLast.Equal(bLast) {
+		return aLast.Before(bLast)
+	}
+	return a.DisplayName < b.DisplayName
+}
+
+// AgeOn returns a child's age on the given day, preferring birthdate over the
+// stored age column
+func AgeOn(birthdate *time.Time, age *int, day time.Time) *int {
+	if birthdate == nil {
+		return age
+	}
+	years := day.Year() - birthdate.Year()
+	if day.Month() < birthdate.Month() || (day.Month() == birthdate.Month() && day.Day() < birthdate.Day()) {
+		years--
+	}
+	return &years
+}
@@ -0,0 +1,151 @@
+package rotation
+
+import (
+	"context"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// LoadChores returns active rotation-eligible chores, optionally limited to ids
+func LoadChores(ctx context.Context, q database.Querier, ids []uuid.UUID) ([]Chore, error) {
+	query := `
+		SELECT id, name, COALESCE(min_age, 0), COALESCE(estimated_minutes, 0), base_points
+		FROM chores
+		WHERE rotation_eligible = true AND active = true
+	`
+	params := []interface{}{}
+	if len(ids) > 0 {
+		query += " AND id = ANY($1)"
+		params = append(params, ids)
+	}
+	query += " ORDER BY name"
+
+	rows, err := q.Query(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chores := []Chore{}
+	for rows.Next() {
+		chore := Chore{LastAssigned: map[uuid.UUID]time.Time{}}
+		if err := rows.Scan(&chore.ID, &chore.Name, &chore.MinAge, &chore.EstimatedMinutes, &chore.Points); err != nil {
+			return nil, err
+		}
+		chores = append(chores, chore)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return chores, LoadLastAssigned(ctx, q, chores)
+}
+
+// LoadLastAssigned fills in the most recent date each child had each chore,
+// which the engine uses to break ties
+func LoadLastAssigned(ctx context.Context, q database.Querier, chores []Chore) error {
+	rows, err := q.Query(ctx, `
+		SELECT chore_id, assigned_to, MAX(due_date)
+		FROM assignments
+		WHERE assigned_to IS NOT NULL AND due_date IS NOT NULL
+		GROUP BY chore_id, assigned_to
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	byID := make(map[uuid.UUID]*Chore, len(chores))
+	for i := range chores {
+		if chores[i].LastAssigned == nil {
+			chores[i].LastAssigned = map[uuid.UUID]time.Time{}
+		}
+		byID[chores[i].ID] = &chores[i]
+	}
+	for rows.Next() {
+		var (
+			choreID, assignedTo uuid.UUID
+			last                time.Time
+		)
+		if err := rows.Scan(&choreID, &assignedTo, &last); err != nil {
+			return err
+		}
+		if chore, ok := byID[choreID]; ok {
+			chore.LastAssigned[assignedTo] = last
+		}
+	}
+	return rows.Err()
+}
+
+// LoadChildren returns active children with their age information. Presence
+// is left false and must be filled in per day by the caller.
+func LoadChildren(ctx context.Context, q database.Querier) ([]Child, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, username, display_name, age, birthdate
+		FROM users
+		WHERE is_parent = false AND is_active = true
+		ORDER BY display_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	children := []Child{}
+	for rows.Next() {
+		var child Child
+		if err := rows.Scan(&child.ID, &child.Username, &child.DisplayName, &child.Age, &child.Birthdate); err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	return children, rows.Err()
+}
+
+// LoadWindow sums each child's assigned minutes and points in [from, to)
+func LoadWindow(ctx context.Context, q database.Querier, from, to time.Time) (map[uuid.UUID]*models.RotationLoad, error) {
+	rows, err := q.Query(ctx, `
+		SELECT
+			a.assigned_to,
+			COALESCE(SUM(COALESCE(c.estimated_minutes, 0)), 0)::int,
+			COALESCE(SUM(a.points_offered), 0)::int,
+			COUNT(a.id)::int
+		FROM assignments a
+		JOIN chores c ON a.chore_id = c.id
+		WHERE a.assigned_to IS NOT NULL
+			AND a.due_date >= $1 AND a.due_date < $2
+			AND a.status NOT IN ('cancelled', 'skipped')
+		GROUP BY a.assigned_to
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	loads := map[uuid.UUID]*models.RotationLoad{}
+	for rows.Next() {
+		var (
+			userID uuid.UUID
+			load   models.RotationLoad
+		)
+		if err := rows.Scan(&userID, &load.Minutes, &load.Points, &load.Assignments); err != nil {
+			return nil, err
+		}
+		loads[userID] = &load
+	}
+	return loads, rows.Err()
+}
+
+// OnDay returns a copy of children with age and presence evaluated for day
+func OnDay(children []Child, day time.Time, present func(username, displayName string) bool) []Child {
+	out := make([]Child, len(children))
+	for i, child := range children {
+		out[i] = child
+		out[i].Age = AgeOn(child.Birthdate, child.Age, day)
+		out[i].Present = present(child.Username, child.DisplayName)
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// DeferHorizonDays is how far ahead we look for a day a child is present
+const DeferHorizonDays = 14
+
+// DayPresence is the set of kids present on a single schedule day
+type DayPresence struct {
+	Date        time.Time
+	Known       bool
+	KidsPresent []string
+	present     map[string]bool
+}
+
+// IsPresent reports whether a child is present. Schedule entries list kids by
+// name, so both username and display name are matched case-insensitively.
+// Days without a schedule entry treat everyone as present.
+func (p DayPresence) IsPresent(username, displayName string) bool {
+	if !p.Known {
+		return true
+	}
+	return p.present[strings.ToLower(username)] || p.present[strings.ToLower(displayName)]
+}
+
+// LoadPresence reads kids_present from family_schedule for a single date
+func LoadPresence(ctx context.Context, q database.Querier, date time.Time) (DayPresence, error) {
+	presence := DayPresence{Date: date}
+
+	var kids []string
+	err := q.QueryRow(ctx,
+		"SELECT kids_present FROM family_schedule WHERE date = $1",
+		date.Format("2006-01-02"),
+	).Scan(&kids)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return presence, nil
+		}
+		return presence, err
+	}
+
+	presence.Known = true
+	presence.KidsPresent = kids
+	presence.present = map[string]bool{}
+	for _, kid := range kids {
+		presence.present[strings.ToLower(strings.TrimSpace(kid))] = true
+	}
+	return presence, nil
+}
+
+// NextPresentDay finds the first day on or after from, within the defer
+// horizon, on which the child is present
+func NextPresentDay(ctx context.Context, q database.Querier, username, displayName string, from time.Time) (time.Time, bool, error) {
+	for i := 0; i <= DeferHorizonDays; i++ {
+		day := from.AddDate(0, 0, i)
+		presence, err := LoadPresence(ctx, q, day)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		if presence.IsPresent(username, displayName) {
+			return day, true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
@@ -0,0 +1,238 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/rotation"
+	"github.com/google/uuid"
+)
+
+// rotationWindowDays matches the default balance window of the rotation engine
+const rotationWindowDays = 14
+
+// absentAssignment is a pending assignment whose assignee may be away
+type absentAssignment struct {
+	ID            uuid.UUID
+	Chore         rotation.Chore
+	Rotation      bool
+	AssignedTo    uuid.UUID
+	Username      string
+	DisplayName   string
+	DueDate       time.Time
+	PointsOffered int
+}
+
+// ReconcileAbsences looks at pending assignments due in [from, to] and moves
+// any whose assignee is away on the due date. Rotation-eligible chores are
+// handed to a present sibling through the rotation engine; everything else is
+// deferred to the assignee's next day at home. Team assignments are left to
+// the parent. Due dates are read as days in loc, the family's time zone. q
+// should be a transaction.
+func ReconcileAbsences(ctx context.Context, q database.Querier, from, to time.Time, loc *time.Location, actor *uuid.UUID, dryRun bool) ([]models.PresenceAdjustment, error) {
+	rows, err := q.Query(ctx, `
+		SELECT
+			a.id, a.chore_id, c.name, c.rotation_eligible,
+			COALESCE(c.min_age, 0), COALESCE(c.estimated_minutes, 0),
+			a.assigned_to, u.username, u.display_name, a.due_date, a.points_offered
+		FROM assignments a
+		JOIN chores c ON a.chore_id = c.id
+		JOIN users u ON a.assigned_to = u.id
+		WHERE a.status = 'pending'
+			AND NOT a.is_team
+			AND a.due_date >= $1 AND a.due_date < $2
+		ORDER BY a.due_date, c.name
+		FOR UPDATE OF a
+	`, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []absentAssignment{}
+	for rows.Next() {
+		var a absentAssignment
+		err := rows.Scan(
+			&a.ID, &a.Chore.ID, &a.Chore.Name, &a.Rotation,
+			&a.Chore.MinAge, &a.Chore.EstimatedMinutes,
+			&a.AssignedTo, &a.Username, &a.DisplayName, &a.DueDate, &a.PointsOffered,
+		)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		a.DueDate = a.DueDate.In(loc)
+		a.Chore.Points = a.PointsOffered
+		candidates = append(candidates, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	adjustments := []models.PresenceAdjustment{}
+	if len(candidates) == 0 {
+		return adjustments, nil
+	}
+
+	children, err := rotation.LoadChildren(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	presenceByDate := map[string]DayPresence{}
+	loadsByDate := map[string]map[uuid.UUID]*models.RotationLoad{}
+
+	for _, a := range candidates {
+		dateKey := a.DueDate.Format("2006-01-02")
+		day := time.Date(a.DueDate.Year(), a.DueDate.Month(), a.DueDate.Day(), 0, 0, 0, 0, a.DueDate.Location())
+
+		presence, ok := presenceByDate[dateKey]
+		if !ok {
+			presence, err = LoadPresence(ctx, q, day)
+			if err != nil {
+				return nil, err
+			}
+			presenceByDate[dateKey] = presence
+		}
+
+		if presence.IsPresent(a.Username, a.DisplayName) {
+			continue
+		}
+
+		adjustment := models.PresenceAdjustment{
+			AssignmentID: a.ID,
+			ChoreID:      a.Chore.ID,
+			ChoreName:    a.Chore.Name,
+			FromUserID:   a.AssignedTo,
+			FromDueDate:  dateKey,
+		}
+
+		// Try handing rotation chores to a sibling who is home that day
+		if a.Rotation {
+			loads, ok := loadsByDate[dateKey]
+			if !ok {
+				loads, err = rotation.LoadWindow(ctx, q, day.AddDate(0, 0, -rotationWindowDays), day)
+				if err != nil {
+					return nil, err
+				}
+				loadsByDate[dateKey] = loads
+			}
+
+			chores := []rotation.Chore{a.Chore}
+			if err := rotation.LoadLastAssigned(ctx, q, chores); err != nil {
+				return nil, err
+			}
+
+			decision := rotation.Assign(rotation.OnDay(children, day, presence.IsPresent), chores, loads)[0]
+			if decision.AssignedTo != nil {
+				adjustment.Action = "reassigned"
+				adjustment.ToUserID = decision.AssignedTo
+				adjustment.Reason = fmt.Sprintf("%s is away on %s; %s", a.DisplayName, dateKey, decision.Reason)
+			}
+		}
+
+		// Otherwise push the assignment to the child's next day at home
+		if adjustment.Action == "" {
+			next, found, err := NextPresentDay(ctx, q, a.Username, a.DisplayName, day.AddDate(0, 0, 1))
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				nextKey := next.Format("2006-01-02")
+				adjustment.Action = "deferred"
+				adjustment.ToDueDate = &nextKey
+				adjustment.Reason = fmt.Sprintf("%s is away on %s; deferred to next day at home", a.DisplayName, dateKey)
+			} else {
+				adjustment.Action = "unresolved"
+				adjustment.Reason = fmt.Sprintf("%s is away on %s and not home within %d days", a.DisplayName, dateKey, DeferHorizonDays)
+			}
+		}
+
+		if !dryRun {
+			if err := applyAdjustment(ctx, q, a, adjustment, actor); err != nil {
+				return nil, err
+			}
+		}
+
+		adjustments = append(adjustments, adjustment)
+	}
+
+	return adjustments, nil
+}
+
+// applyAdjustment writes the adjustment to the assignment and the audit table
+func applyAdjustment(ctx context.Context, q database.Querier, a absentAssignment, adjustment models.PresenceAdjustment, actor *uuid.UUID) error {
+	var toDue *time.Time
+
+	switch adjustment.Action {
+	case "reassigned":
+		_, err := q.Exec(ctx, `
+			UPDATE assignments
+			SET assigned_to = $1,
+				updated_at = NOW()
+			WHERE id = $2
+		`, *adjustment.ToUserID, a.ID)
+		if err != nil {
+			return err
+		}
+	case "unresolved":
+		// Only note an unresolved absence once per due date
+		var exists bool
+		err := q.QueryRow(ctx, `
+			SELECT EXISTS(
+				SELECT 1 FROM presence_adjustments
+				WHERE assignment_id = $1 AND action = 'unresolved' AND from_due_date = $2
+			)
+		`, a.ID, a.DueDate).Scan(&exists)
+		if err != nil || exists {
+			return err
+		}
+	case "deferred":
+		next, _ := time.ParseInLocation("2006-01-02", *adjustment.ToDueDate, a.DueDate.Location())
+		due := time.Date(next.Year(), next.Month(), next.Day(),
+			a.DueDate.Hour(), a.DueDate.Minute(), a.DueDate.Second(), 0, a.DueDate.Location())
+		toDue = &due
+		_, err := q.Exec(ctx, `
+			UPDATE assignments
+			SET due_date = $1,
+				updated_at = NOW()
+			WHERE id = $2
+		`, due, a.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if adjustment.Action != "unresolved" {
+		event := lifecycle.EventReassigned
+		metadata := map[string]interface{}{"from_user_id": a.AssignedTo, "to_user_id": adjustment.ToUserID}
+		if adjustment.Action == "deferred" {
+			event = lifecycle.EventDeferred
+			metadata = map[string]interface{}{"from_due_date": a.DueDate, "to_due_date": toDue}
+		}
+		err := lifecycle.Record(ctx, q, lifecycle.Change{
+			AssignmentID: a.ID,
+			Event:        event,
+			From:         lifecycle.StatusPending,
+			ActorID:      actor,
+			Notes:        &adjustment.Reason,
+			Metadata:     metadata,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := q.Exec(ctx, `
+		INSERT INTO presence_adjustments (
+			id, assignment_id, action, from_user_id, to_user_id,
+			from_due_date, to_due_date, reason, created_by, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+	`, uuid.New(), a.ID, adjustment.Action, a.AssignedTo, adjustment.ToUserID,
+		a.DueDate, toDue, adjustment.Reason, actor)
+	return err
+}
@@ -0,0 +1,153 @@
+// Package scoring turns a verified assignment into point transaction lines:
+// the (possibly partial) base credit, a quality bonus, and the early
+// completion and extra effort bonuses for bonus-eligible chores.
+package scoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/ledger"
+	"github.com/google/uuid"
+)
+
+// Point transaction types written for a verified assignment
+const (
+	TypeCompletion = ledger.TypeChoreCompletion
+	TypeQuality    = ledger.TypeQualityBonus
+	TypeEarly      = ledger.TypeEarlyBonus
+	TypeEffort     = ledger.TypeEffortBonus
+)
+
+// Types lists every transaction type scoring can write
+var Types = []string{TypeCompletion, TypeQuality, TypeEarly, TypeEffort}
+
+// Rules are the family's bonus percentages, stored in system_settings. All
+// percentages are of the credited base points.
+type Rules struct {
+	// QualityBonusPercent maps a 1-5 rating to its bonus
+	QualityBonusPercent map[int]int
+	EarlyBonusPercent   int
+	MaxEffortPercent    int
+}
+
+// Input describes how a parent judged the work
+type Input struct {
+	PointsOffered int
+	// PointsOverride replaces the base credit outright
+	PointsOverride *int
+	// PartialPercent of the base is credited; 0 means full credit
+	PartialPercent int
+	// TimingPercent of the base is kept for late work; nil means full credit
+	TimingPercent *int
+	Quality       *int
+	BonusEligible bool
+	// Early is set when the chore was done before its due day
+	Early         bool
+	EffortPercent int
+}
+
+// Line is a single point transaction
+type Line struct {
+	Type        string `json:"type"`
+	Points      int    `json:"points"`
+	Description string `json:"description"`
+}
+
+// ErrNotBonusEligible is returned when an effort bonus is requested for a
+// chore that does not allow bonuses
+var ErrNotBonusEligible = errors.New("chore is not bonus eligible")
+
+// Score computes the lines for a verified assignment. The base line is always
+// present, even at zero points; bonus lines only when they are worth something.
+func Score(r Rules, in Input) ([]Line, error) {
+	if in.EffortPercent > 0 && !in.BonusEligible {
+		return nil, ErrNotBonusEligible
+	}
+	if in.EffortPercent > r.MaxEffortPercent {
+		return nil, fmt.Errorf("effort bonus cannot exceed %d%%", r.MaxEffortPercent)
+	}
+
+	base := in.PointsOffered
+	desc := "Completed chore"
+	switch {
+	case in.PointsOverride != nil:
+		base = *in.PointsOverride
+	case in.PartialPercent > 0 && in.PartialPercent < 100:
+		base = percentOf(in.PointsOffered, in.PartialPercent)
+		desc = fmt.Sprintf("Completed chore (%d%% partial credit)", in.PartialPercent)
+	}
+
+	if in.PointsOverride == nil && in.TimingPercent != nil && *in.TimingPercent < 100 {
+		base = percentOf(base, *in.TimingPercent)
+		desc += fmt.Sprintf(" (%d%% for finishing late)", *in.TimingPercent)
+	}
+
+	lines := []Line{{Type: TypeCompletion, Points: base, Description: desc}}
+
+	add := func(typ string, pct int, desc string) {
+		if pts := percentOf(base, pct); pts > 0 {
+			lines = append(lines, Line{Type: typ, Points: pts, Description: desc})
+		}
+	}
+
+	if in.Quality != nil {
+		add(TypeQuality, r.QualityBonusPercent[*in.Quality], fmt.Sprintf("Quality rating %d/5", *in.Quality))
+	}
+	if in.BonusEligible && in.Early {
+		add(TypeEarly, r.EarlyBonusPercent, "Finished before the due date")
+	}
+	if in.BonusEligible {
+		add(TypeEffort, in.EffortPercent, "Extra effort")
+	}
+
+	return lines, nil
+}
+
+// Total sums the points of all lines
+func Total(lines []Line) int {
+	total := 0
+	for _, l := range lines {
+		total += l.Points
+	}
+	return total
+}
+
+// percentOf rounds half up
+func percentOf(points, pct int) int {
+	if points <= 0 || pct <= 0 {
+		return 0
+	}
+	return (points*pct + 50) / 100
+}
+
+// LoadRules reads the bonus rules from system_settings
+func LoadRules(ctx context.Context, q database.Querier) Rules {
+	return Rules{
+		QualityBonusPercent: map[int]int{
+			4: database.SettingInt(ctx, q, "quality_bonus_percent_4", 10),
+			5: database.SettingInt(ctx, q, "quality_bonus_percent_5", 25),
+		},
+		EarlyBonusPercent: database.SettingInt(ctx, q, "early_completion_bonus_percent", 10),
+		MaxEffortPercent:  database.SettingInt(ctx, q, "effort_bonus_max_percent", 50),
+	}
+}
+
+// Award posts one point transaction per line to the user's ledger
+func Award(ctx context.Context, q database.Querier, userID, assignmentID uuid.UUID, lines []Line) error {
+	for _, l := range lines {
+		_, err := ledger.Post(ctx, q, ledger.Entry{
+			UserID:       userID,
+			Points:       l.Points,
+			Type:         l.Type,
+			Description:  l.Description,
+			AssignmentID: &assignmentID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+// Package sequence orders chores that depend on each other. A chore lists
+// the chores that have to be done before it; assignments due on the same day
+// are linked so a dependent assignment stays blocked until its prerequisites
+// are verified.
+package sequence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// Sequence states shown on assignments that have prerequisites
+const (
+	StateBlocked = "blocked"
+	StateReady   = "ready"
+)
+
+// ReleasedStatuses are the prerequisite statuses that no longer hold up a
+// dependent: verified, or closed without the work being done
+var ReleasedStatuses = []string{
+	lifecycle.StatusVerified,
+	lifecycle.StatusMissed,
+	lifecycle.StatusSkipped,
+	lifecycle.StatusCancelled,
+}
+
+// ErrCycle is returned when prerequisites would make a chore depend on itself
+var ErrCycle = errors.New("prerequisites would form a cycle")
+
+// LoadChore returns a chore's prerequisite chores by name
+func LoadChore(ctx context.Context, q database.Querier, choreID uuid.UUID) ([]models.ChorePrerequisite, error) {
+	rows, err := q.Query(ctx, `
+		SELECT c.id, c.name
+		FROM chore_prerequisites p
+		JOIN chores c ON p.prerequisite_chore_id = c.id
+		WHERE p.chore_id = $1
+		ORDER BY c.name
+	`, choreID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prerequisites: %w", err)
+	}
+	defer rows.Close()
+
+	prerequisites := []models.ChorePrerequisite{}
+	for rows.Next() {
+		var p models.ChorePrerequisite
+		if err := rows.Scan(&p.ChoreID, &p.Name); err != nil {
+			return nil, fmt.Errorf("failed to parse prerequisite: %w", err)
+		}
+		prerequisites = append(prerequisites, p)
+	}
+	return prerequisites, rows.Err()
+}
+
+// ReplaceChore sets a chore's prerequisites. It returns ErrCycle if any of
+// them already depends on the chore, directly or through other chores.
+func ReplaceChore(ctx context.Context, q database.Querier, choreID uuid.UUID, prerequisiteIDs []uuid.UUID) error {
+	if _, err := q.Exec(ctx, "DELETE FROM chore_prerequisites WHERE chore_id = $1", choreID); err != nil {
+		return fmt.Errorf("failed to clear prerequisites: %w", err)
+	}
+	if len(prerequisiteIDs) == 0 {
+		return nil
+	}
+
+	var cycle bool
+	err := q.QueryRow(ctx, `
+		WITH RECURSIVE upstream(id) AS (
+			SELECT unnest($2::uuid[])
+			UNION
+			SELECT p.prerequisite_chore_id
+			FROM chore_prerequisites p
+			JOIN upstream u ON p.chore_id = u.id
+		)
+		SELECT EXISTS(SELECT 1 FROM upstream WHERE id = $1)
+	`, choreID, prerequisiteIDs).Scan(&cycle)
+	if err != nil {
+		return fmt.Errorf("failed to check prerequisites: %w", err)
+	}
+	if cycle {
+		return ErrCycle
+	}
+
+	for _, id := range prerequisiteIDs {
+		_, err := q.Exec(ctx, `
+			INSERT INTO chore_prerequisites (chore_id, prerequisite_chore_id, created_at)
+			VALUES ($1, $2, NOW())
+			ON CONFLICT DO NOTHING
+		`, choreID, id)
+		if err != nil {
+			return fmt.Errorf("failed to add prerequisite: %w", err)
+		}
+	}
+	return nil
+}
+
+// WithPrerequisites adds every chore the given chores depend on, directly or
+// indirectly, so generating assignments for a dependent chore also creates
+// the work it waits for
+func WithPrerequisites(ctx context.Context, q database.Querier, choreIDs []uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.Query(ctx, `
+		WITH RECURSIVE upstream(id) AS (
+			SELECT unnest($1::uuid[])
+			UNION
+			SELECT p.prerequisite_chore_id
+			FROM chore_prerequisites p
+			JOIN upstream u ON p.chore_id = u.id
+		)
+		SELECT id FROM upstream
+	`, choreIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prerequisites: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Link connects the assignments due on the family-local day starting at
+// dayStart that nobody has started yet (open or pending) to the assignments
+// of their prerequisite chores due the same day. Prerequisites are matched
+// whoever they are assigned to: a chore that needs the table cleared waits
+// for whichever child clears it. Work already under way is never blocked
+// retroactively. Existing links are kept, so it is safe to call after every
+// assignment is created.
+func Link(ctx context.Context, q database.Querier, dayStart time.Time) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO assignment_dependencies (assignment_id, prerequisite_id, created_at)
+		SELECT a.id, p.id, NOW()
+		FROM assignments a
+		JOIN chore_prerequisites cp ON cp.chore_id = a.chore_id
+		JOIN assignments p ON p.chore_id = cp.prerequisite_chore_id
+			AND p.due_date >= $1 AND p.due_date < $2
+		WHERE a.due_date >= $1 AND a.due_date < $2
+			AND a.status = ANY($3)
+			AND p.status NOT IN ('skipped', 'cancelled')
+		ON CONFLICT DO NOTHING
+	`, dayStart, dayStart.AddDate(0, 0, 1), []string{lifecycle.StatusOpen, lifecycle.StatusPending})
+	if err != nil {
+		return fmt.Errorf("failed to link assignments: %w", err)
+	}
+	return nil
+}
+
+// LoadMany returns the prerequisites of several assignments, keyed by
+// assignment
+func LoadMany(ctx context.Context, q database.Querier, assignmentIDs []uuid.UUID) (map[uuid.UUID][]models.AssignmentPrerequisite, error) {
+	result := map[uuid.UUID][]models.AssignmentPrerequisite{}
+	if len(assignmentIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT d.assignment_id, p.id, p.chore_id, c.name, p.status,
+			p.assigned_to, u.display_name, p.status = ANY($2)
+		FROM assignment_dependencies d
+		JOIN assignments p ON d.prerequisite_id = p.id
+		JOIN chores c ON p.chore_id = c.id
+		LEFT JOIN users u ON p.assigned_to = u.id
+		WHERE d.assignment_id = ANY($1)
+		ORDER BY d.assignment_id, c.name
+	`, assignmentIDs, ReleasedStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prerequisites: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			assignmentID uuid.UUID
+			p            models.AssignmentPrerequisite
+		)
+		err := rows.Scan(&assignmentID, &p.AssignmentID, &p.ChoreID, &p.ChoreName, &p.Status,
+			&p.AssignedTo, &p.AssignedUserName, &p.Satisfied)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse prerequisite: %w", err)
+		}
+		result[assignmentID] = append(result[assignmentID], p)
+	}
+	return result, rows.Err()
+}
+
+// Load returns the prerequisites of a single assignment
+func Load(ctx context.Context, q database.Querier, assignmentID uuid.UUID) ([]models.AssignmentPrerequisite, error) {
+	byAssignment, err := LoadMany(ctx, q, []uuid.UUID{assignmentID})
+	if err != nil {
+		return nil, err
+	}
+	return byAssignment[assignmentID], nil
+}
+
+// Blockers returns the prerequisites an assignment is still waiting for
+func Blockers(ctx context.Context, q database.Querier, assignmentID uuid.UUID) ([]models.AssignmentPrerequisite, error) {
+	prerequisites, err := Load(ctx, q, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	blockers := []models.AssignmentPrerequisite{}
+	for _, p := range prerequisites {
+		if !p.Satisfied {
+			blockers = append(blockers, p)
+		}
+	}
+	return blockers, nil
+}
+
+// State returns blocked or ready for an assignment with prerequisites, and
+// an empty string for one without
+func State(prerequisites []models.AssignmentPrerequisite) string {
+	if len(prerequisites) == 0 {
+		return ""
+	}
+	for _, p := range prerequisites {
+		if !p.Satisfied {
+			return StateBlocked
+		}
+	}
+	return StateReady
+}
+
+// Dependent is an assignment that became ready when its last prerequisite
+// was released
+type Dependent struct {
+	AssignmentID uuid.UUID
+	AssignedTo   *uuid.UUID
+	ChoreName    string
+}
+
+// Released returns the open assignments waiting on prerequisiteID that have
+// no other unreleased prerequisites. Call it after the prerequisite's status
+// has changed.
+func Released(ctx context.Context, q database.Querier, prerequisiteID uuid.UUID) ([]Dependent, error) {
+	rows, err := q.Query(ctx, `
+		SELECT a.id, a.assigned_to, c.name
+		FROM assignment_dependencies d
+		JOIN assignments a ON d.assignment_id = a.id
+		JOIN chores c ON a.chore_id = c.id
+		WHERE d.prerequisite_id = $1
+			AND NOT (a.status = ANY($2))
+			AND NOT EXISTS (
+				SELECT 1 FROM assignment_dependencies o
+				JOIN assignments p ON o.prerequisite_id = p.id
+				WHERE o.assignment_id = a.id AND NOT (p.status = ANY($2))
+			)
+	`, prerequisiteID, ReleasedStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dependents: %w", err)
+	}
+	defer rows.Close()
+
+	dependents := []Dependent{}
+	for rows.Next() {
+		var d Dependent
+		if err := rows.Scan(&d.AssignmentID, &d.AssignedTo, &d.ChoreName); err != nil {
+			return nil, err
+		}
+		dependents = append(dependents, d)
+	}
+	return dependents, rows.Err()
+}
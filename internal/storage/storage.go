@@ -0,0 +1,45 @@
+// Package storage provides blob storage for uploaded files behind a common
+// interface, with local filesystem and S3-compatible backends.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrNotFound is returned when a key does not exist
+var ErrNotFound = errors.New("object not found")
+
+// Store stores blobs by key and hands out time-limited URLs to read them
+type Store interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that can fetch key without authentication
+	// until it expires
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// NewFromEnv builds the store selected by STORAGE_BACKEND ("local" or "s3")
+func NewFromEnv(signingKey string) (Store, error) {
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "./uploads"
+		}
+		return NewLocal(dir, "/api/files", signingKey)
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", backend)
+	}
+}
@@ -0,0 +1,226 @@
+// Package teams handles assignments shared by several children: tracking
+// each participant's part and splitting the points on verification.
+//
+// A team assignment keeps its first participant in assignments.assigned_to so
+// code that only knows about a single assignee still sees a lead child.
+package teams
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/JunoAX/housepoints-go/internal/scoring"
+	"github.com/google/uuid"
+)
+
+// Split modes
+const (
+	// SplitEven gives every participant the same share
+	SplitEven = "even"
+	// SplitWeighted shares points by each participant's weight
+	SplitWeighted = "weighted"
+	// SplitManual lets the verifying parent set each participant's points
+	SplitManual = "manual"
+)
+
+// Load returns the participants of a team assignment in the order they were
+// added
+func Load(ctx context.Context, q database.Querier, assignmentID uuid.UUID) ([]models.AssignmentParticipant, error) {
+	byAssignment, err := LoadMany(ctx, q, []uuid.UUID{assignmentID})
+	if err != nil {
+		return nil, err
+	}
+	return byAssignment[assignmentID], nil
+}
+
+// LoadMany returns the participants of several assignments, keyed by
+// assignment
+func LoadMany(ctx context.Context, q database.Querier, assignmentIDs []uuid.UUID) (map[uuid.UUID][]models.AssignmentParticipant, error) {
+	result := map[uuid.UUID][]models.AssignmentParticipant{}
+	if len(assignmentIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT p.assignment_id, p.user_id, u.username, u.display_name, u.color_theme,
+			p.weight::float8, p.completed_at, p.completion_notes, p.points_earned
+		FROM assignment_participants p
+		JOIN users u ON p.user_id = u.id
+		WHERE p.assignment_id = ANY($1)
+		ORDER BY p.assignment_id, p.position
+	`, assignmentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query participants: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			assignmentID uuid.UUID
+			p            models.AssignmentParticipant
+		)
+		err := rows.Scan(&assignmentID, &p.UserID, &p.Username, &p.DisplayName, &p.ColorTheme,
+			&p.Weight, &p.CompletedAt, &p.CompletionNotes, &p.PointsEarned)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse participant: %w", err)
+		}
+		result[assignmentID] = append(result[assignmentID], p)
+	}
+	return result, rows.Err()
+}
+
+// Add stores the participants of a new team assignment
+func Add(ctx context.Context, q database.Querier, assignmentID uuid.UUID, participants []models.AssignmentParticipantRequest) error {
+	for i, p := range participants {
+		weight := 1.0
+		if p.Weight != nil {
+			weight = *p.Weight
+		}
+		_, err := q.Exec(ctx, `
+			INSERT INTO assignment_participants (assignment_id, user_id, weight, position, created_at)
+			VALUES ($1, $2, $3, $4, NOW())
+		`, assignmentID, p.UserID, weight, i)
+		if err != nil {
+			return fmt.Errorf("failed to add participant: %w", err)
+		}
+	}
+	return nil
+}
+
+// IsParticipant reports whether a user is on the team
+func IsParticipant(participants []models.AssignmentParticipant, userID uuid.UUID) bool {
+	for _, p := range participants {
+		if p.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkDone records a participant finishing their part. With all set, every
+// participant still outstanding is marked done, for a parent completing the
+// chore on the team's behalf. It reports whether the whole team is done.
+func MarkDone(ctx context.Context, q database.Querier, assignmentID, userID uuid.UUID, all bool, notes *string) (bool, error) {
+	_, err := q.Exec(ctx, `
+		UPDATE assignment_participants
+		SET completed_at = NOW(),
+			completion_notes = COALESCE($3, completion_notes)
+		WHERE assignment_id = $1
+			AND completed_at IS NULL
+			AND (user_id = $2 OR $4)
+	`, assignmentID, userID, notes, all)
+	if err != nil {
+		return false, fmt.Errorf("failed to record participant completion: %w", err)
+	}
+
+	var outstanding int
+	err = q.QueryRow(ctx,
+		"SELECT COUNT(*) FROM assignment_participants WHERE assignment_id = $1 AND completed_at IS NULL",
+		assignmentID,
+	).Scan(&outstanding)
+	if err != nil {
+		return false, fmt.Errorf("failed to count outstanding participants: %w", err)
+	}
+	return outstanding == 0, nil
+}
+
+// Reset clears every participant's completion so the team can redo the chore
+func Reset(ctx context.Context, q database.Querier, assignmentID uuid.UUID) error {
+	_, err := q.Exec(ctx,
+		"UPDATE assignment_participants SET completed_at = NULL WHERE assignment_id = $1",
+		assignmentID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reset participants: %w", err)
+	}
+	return nil
+}
+
+// Weights returns each participant's share weight for a split mode. For a
+// manual split the weights are the points the parent assigned, which must
+// cover every participant.
+func Weights(mode string, participants []models.AssignmentParticipant, manual map[uuid.UUID]int) ([]float64, error) {
+	weights := make([]float64, len(participants))
+	for i, p := range participants {
+		switch mode {
+		case SplitManual:
+			points, ok := manual[p.UserID]
+			if !ok {
+				return nil, fmt.Errorf("missing points for participant %s", p.DisplayName)
+			}
+			if points < 0 {
+				return nil, fmt.Errorf("points for %s cannot be negative", p.DisplayName)
+			}
+			weights[i] = float64(points)
+		case SplitWeighted:
+			weights[i] = p.Weight
+		default:
+			weights[i] = 1
+		}
+	}
+	for id := range manual {
+		if !IsParticipant(participants, id) {
+			return nil, fmt.Errorf("user %s is not a participant", id)
+		}
+	}
+	return weights, nil
+}
+
+// Split divides total points by weight using the largest remainder method, so
+// the shares always add up to total. Ties go to the earlier participant.
+func Split(total int, weights []float64) []int {
+	shares := make([]int, len(weights))
+	sum := 0.0
+	for _, w := range weights {
+		sum += w
+	}
+	if total <= 0 || sum <= 0 {
+		return shares
+	}
+
+	type remainder struct {
+		index int
+		frac  float64
+	}
+	remainders := make([]remainder, len(weights))
+	allocated := 0
+	for i, w := range weights {
+		exact := float64(total) * w / sum
+		shares[i] = int(math.Floor(exact))
+		allocated += shares[i]
+		remainders[i] = remainder{i, exact - float64(shares[i])}
+	}
+
+	sort.SliceStable(remainders, func(a, b int) bool {
+		return remainders[a].frac > remainders[b].frac
+	})
+	for i := 0; allocated < total; i++ {
+		shares[remainders[i%len(remainders)].index]++
+		allocated++
+	}
+	return shares
+}
+
+// Allocate splits every scoring line across the participants by weight. Each
+// participant gets their own completion line, even at zero points, plus their
+// share of each bonus.
+func Allocate(lines []scoring.Line, weights []float64) [][]scoring.Line {
+	result := make([][]scoring.Line, len(weights))
+	for _, line := range lines {
+		for i, points := range Split(line.Points, weights) {
+			if points == 0 && line.Type != scoring.TypeCompletion {
+				continue
+			}
+			result[i] = append(result[i], scoring.Line{
+				Type:        line.Type,
+				Points:      points,
+				Description: line.Description + " (team share)",
+			})
+		}
+	}
+	return result
+}
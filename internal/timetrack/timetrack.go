@@ -0,0 +1,186 @@
+// Package timetrack records how long children actually spend on chores, as
+// segments between starting (or resuming) and pausing a timer, and turns
+// that history into suggested estimates.
+package timetrack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// MinSamples is the number of timed assignments needed before an estimate is
+// suggested
+const MinSamples = 3
+
+// ErrRunning is returned when starting a timer that is already running
+var ErrRunning = errors.New("timer is already running")
+
+// ErrNotRunning is returned when pausing a timer that is not running
+var ErrNotRunning = errors.New("timer is not running")
+
+// Start opens a time segment for a user. The caller should hold the
+// assignment row lock.
+func Start(ctx context.Context, q database.Querier, assignmentID, userID uuid.UUID) error {
+	var running bool
+	err := q.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM assignment_time_segments
+			WHERE assignment_id = $1 AND user_id = $2 AND ended_at IS NULL
+		)
+	`, assignmentID, userID).Scan(&running)
+	if err != nil {
+		return fmt.Errorf("failed to check timer: %w", err)
+	}
+	if running {
+		return ErrRunning
+	}
+
+	_, err = q.Exec(ctx, `
+		INSERT INTO assignment_time_segments (id, assignment_id, user_id, started_at, created_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+	`, uuid.New(), assignmentID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to start timer: %w", err)
+	}
+	return nil
+}
+
+// Stop closes the running segments of an assignment, only the given user's
+// when userID is set, and returns how many were closed
+func Stop(ctx context.Context, q database.Querier, assignmentID uuid.UUID, userID *uuid.UUID) (int64, error) {
+	result, err := q.Exec(ctx, `
+		UPDATE assignment_time_segments
+		SET ended_at = NOW()
+		WHERE assignment_id = $1
+			AND ended_at IS NULL
+			AND ($2::uuid IS NULL OR user_id = $2)
+	`, assignmentID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stop timer: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// Load returns an assignment's time segments, oldest first, with the time
+// worked so far
+func Load(ctx context.Context, q database.Querier, assignmentID uuid.UUID) (models.AssignmentTime, error) {
+	summary := models.AssignmentTime{Segments: []models.TimeSegment{}}
+
+	rows, err := q.Query(ctx, `
+		SELECT s.id, s.user_id, u.display_name, s.started_at, s.ended_at,
+			EXTRACT(EPOCH FROM (COALESCE(s.ended_at, NOW()) - s.started_at))::float8 / 60
+		FROM assignment_time_segments s
+		JOIN users u ON s.user_id = u.id
+		WHERE s.assignment_id = $1
+		ORDER BY s.started_at
+	`, assignmentID)
+	if err != nil {
+		return summary, fmt.Errorf("failed to query time segments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s models.TimeSegment
+		if err := rows.Scan(&s.ID, &s.UserID, &s.DisplayName, &s.StartedAt, &s.EndedAt, &s.Minutes); err != nil {
+			return summary, fmt.Errorf("failed to parse time segment: %w", err)
+		}
+		s.Minutes = Round(s.Minutes)
+		summary.ActualMinutes += s.Minutes
+		if s.EndedAt == nil {
+			summary.Running = true
+		}
+		summary.Segments = append(summary.Segments, s)
+	}
+	summary.ActualMinutes = Round(summary.ActualMinutes)
+	return summary, rows.Err()
+}
+
+// Sample is the time spent on one finished assignment
+type Sample struct {
+	AssignmentID     uuid.UUID
+	ChoreID          uuid.UUID
+	ChoreName        string
+	EstimatedMinutes *int
+	UserID           uuid.UUID
+	UserName         string
+	Minutes          float64
+}
+
+// LoadSamples returns the timed, non-team assignments completed between from
+// and to (inclusive dates), optionally for one child. Team chores are left
+// out because their minutes are shared between several children.
+func LoadSamples(ctx context.Context, q database.Querier, from, to time.Time, childID *uuid.UUID) ([]Sample, error) {
+	rows, err := q.Query(ctx, `
+		SELECT a.id, a.chore_id, c.name, c.estimated_minutes, a.assigned_to, u.display_name,
+			SUM(EXTRACT(EPOCH FROM (s.ended_at - s.started_at)))::float8 / 60
+		FROM assignment_time_segments s
+		JOIN assignments a ON s.assignment_id = a.id
+		JOIN chores c ON a.chore_id = c.id
+		JOIN users u ON a.assigned_to = u.id
+		WHERE s.ended_at IS NOT NULL
+			AND NOT a.is_team
+			AND a.status IN ('pending_verification', 'completed', 'verified')
+			AND DATE(a.completed_at) BETWEEN $1 AND $2
+			AND ($3::uuid IS NULL OR a.assigned_to = $3)
+		GROUP BY a.id, a.chore_id, c.name, c.estimated_minutes, a.assigned_to, u.display_name
+		HAVING SUM(EXTRACT(EPOCH FROM (s.ended_at - s.started_at))) > 0
+		ORDER BY c.name, u.display_name
+	`, from.Format("2006-01-02"), to.Format("2006-01-02"), childID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time samples: %w", err)
+	}
+	defer rows.Close()
+
+	samples := []Sample{}
+	for rows.Next() {
+		var s Sample
+		err := rows.Scan(&s.AssignmentID, &s.ChoreID, &s.ChoreName, &s.EstimatedMinutes,
+			&s.UserID, &s.UserName, &s.Minutes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse time sample: %w", err)
+		}
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
+
+// Median returns the middle of the values, or 0 for none
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// Suggest returns an estimate in whole minutes from the actual times, the
+// median rounded up so one slow day does not skew it. It returns nil until
+// there are MinSamples samples.
+func Suggest(minutes []float64) *int {
+	if len(minutes) < MinSamples {
+		return nil
+	}
+	suggested := int(math.Ceil(Median(minutes)))
+	if suggested < 1 {
+		suggested = 1
+	}
+	return &suggested
+}
+
+// Round keeps one decimal place
+func Round(minutes float64) float64 {
+	return math.Round(minutes*10) / 10
+}
@@ -0,0 +1,127 @@
+// Package timing judges when a chore was finished against its due day and
+// optional time-of-day window, in the family's timezone, and turns that into
+// a share of the points: full credit up to the deadline, a linear decay
+// after a grace period, and nothing past the cutoff. Finishing before the
+// window opens counts as early for the early completion bonus.
+package timing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+)
+
+// Timing outcomes
+const (
+	Early   = "early"
+	OnTime  = "on_time"
+	Late    = "late"
+	Expired = "expired"
+)
+
+// Window is the allowed time of day, in minutes after midnight
+type Window struct {
+	Start int
+	End   int
+}
+
+// Curve controls how late work loses points. Points are full for
+// GraceMinutes past the deadline, then fall linearly to zero at
+// CutoffMinutes past it.
+type Curve struct {
+	GraceMinutes  int
+	CutoffMinutes int
+}
+
+// Result explains how the timing of a completion affects its points
+type Result struct {
+	Timing      string     `json:"timing"`
+	Percent     int        `json:"percent"` // share of the base points
+	OpensAt     *time.Time `json:"opens_at,omitempty"`
+	Deadline    time.Time  `json:"deadline"`
+	MinutesLate int        `json:"minutes_late,omitempty"`
+	Explanation string     `json:"explanation"`
+}
+
+// LoadCurve reads the late curve from system_settings. A chore's own cutoff,
+// when set, replaces the family default.
+func LoadCurve(ctx context.Context, q database.Querier, choreCutoff *int) Curve {
+	curve := Curve{
+		GraceMinutes:  database.SettingInt(ctx, q, "late_grace_minutes", 0),
+		CutoffMinutes: database.SettingInt(ctx, q, "late_cutoff_minutes", 24*60),
+	}
+	if choreCutoff != nil {
+		curve.CutoffMinutes = *choreCutoff
+	}
+	return curve
+}
+
+// Evaluate judges a completion at the given time. The due day is due's
+// calendar date in loc; the window, if any, is placed on that day. Without a
+// window the whole due day counts as on time.
+func Evaluate(curve Curve, due time.Time, window *Window, at time.Time, loc *time.Location) Result {
+	y, m, d := due.In(loc).Date()
+	opens := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	deadline := time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	span := "the due day"
+	if window != nil {
+		opens = time.Date(y, m, d, 0, window.Start, 0, 0, loc)
+		deadline = time.Date(y, m, d, 0, window.End, 0, 0, loc)
+		span = fmt.Sprintf("the %s-%s window", FormatClock(window.Start), FormatClock(window.End))
+	}
+
+	r := Result{Percent: 100, Deadline: deadline}
+	if window != nil {
+		r.OpensAt = &opens
+	}
+
+	switch {
+	case at.Before(opens):
+		r.Timing = Early
+		r.Explanation = fmt.Sprintf("Finished before %s started", span)
+		return r
+	case !at.After(deadline):
+		r.Timing = OnTime
+		r.Explanation = fmt.Sprintf("Finished within %s", span)
+		return r
+	}
+
+	r.MinutesLate = int(at.Sub(deadline).Minutes())
+	switch {
+	case r.MinutesLate <= curve.GraceMinutes:
+		r.Timing = OnTime
+		r.Explanation = fmt.Sprintf("Finished %d minutes after %s, within the %d minute grace period",
+			r.MinutesLate, span, curve.GraceMinutes)
+	case r.MinutesLate >= curve.CutoffMinutes || curve.CutoffMinutes <= curve.GraceMinutes:
+		r.Timing = Expired
+		r.Percent = 0
+		r.Explanation = fmt.Sprintf("Finished %d minutes after %s, past the %d minute cutoff, so no points",
+			r.MinutesLate, span, curve.CutoffMinutes)
+	default:
+		r.Timing = Late
+		r.Percent = 100 * (curve.CutoffMinutes - r.MinutesLate) / (curve.CutoffMinutes - curve.GraceMinutes)
+		r.Explanation = fmt.Sprintf("Finished %d minutes after %s; points fall to zero %d minutes after it, so %d%% of the points",
+			r.MinutesLate, span, curve.CutoffMinutes, r.Percent)
+	}
+	return r
+}
+
+// ParseClock parses a time of day as HH:MM into minutes after midnight.
+// 24:00 is accepted as the end of the day.
+func ParseClock(s string) (int, error) {
+	var h, m int
+	if _, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || len(s) != 5 {
+		return 0, fmt.Errorf("invalid time %q, use HH:MM", s)
+	}
+	if h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("invalid time %q, use HH:MM", s)
+	}
+	return h*60 + m, nil
+}
+
+// FormatClock formats minutes after midnight as HH:MM
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
@@ -0,0 +1,287 @@
+// Package trades lets children hand assignments to each other. A child
+// offers a pending assignment, optionally with a points sweetener; a sibling
+// accepts it, optionally swapping one of their own assignments back; and a
+// parent approves the trade first when the family requires it.
+package trades
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/JunoAX/housepoints-go/internal/ledger"
+	"github.com/JunoAX/housepoints-go/internal/lifecycle"
+	"github.com/JunoAX/housepoints-go/internal/models"
+	"github.com/google/uuid"
+)
+
+// Trade statuses
+const (
+	StatusOpen            = "open"
+	StatusPendingApproval = "pending_approval"
+	StatusCompleted       = "completed"
+	StatusRejected        = "rejected"
+	StatusCancelled       = "cancelled"
+)
+
+// Trade events recorded in assignment_trade_events
+const (
+	EventOffered   = "offered"
+	EventAccepted  = "accepted"
+	EventApproved  = "approved"
+	EventRejected  = "rejected"
+	EventCancelled = "cancelled"
+	EventCompleted = "completed"
+)
+
+// TransactionType is the point transaction written for a sweetener
+const TransactionType = ledger.TypeTradeSweetener
+
+// ErrInsufficientPoints is returned when the child offering a trade can no
+// longer pay its sweetener
+var ErrInsufficientPoints = errors.New("not enough points for the sweetener")
+
+// ErrStale is returned when a traded assignment is no longer pending with the
+// child it was offered by
+var ErrStale = errors.New("assignment is no longer available to trade")
+
+// Live reports whether a trade can still be acted on
+func Live(status string) bool {
+	return status == StatusOpen || status == StatusPendingApproval
+}
+
+// Record writes a negotiation step
+func Record(ctx context.Context, q database.Querier, tradeID uuid.UUID, event string, actorID *uuid.UUID, notes *string) error {
+	_, err := q.Exec(ctx, `
+		INSERT INTO assignment_trade_events (id, trade_id, event_type, actor_id, notes, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`, uuid.New(), tradeID, event, actorID, notes)
+	if err != nil {
+		return fmt.Errorf("failed to record trade event: %w", err)
+	}
+	return nil
+}
+
+const selectTrades = `
+	SELECT
+		t.id, t.assignment_id, c.name, a.due_date, a.points_offered,
+		t.offered_by, ou.display_name, t.sweetener_points, t.note, t.status,
+		t.accepted_by, au.display_name, t.accepted_at,
+		t.exchange_assignment_id, xc.name,
+		t.decided_by, t.decided_at, t.decision_notes, t.created_at, t.updated_at
+	FROM assignment_trades t
+	JOIN assignments a ON t.assignment_id = a.id
+	JOIN chores c ON a.chore_id = c.id
+	JOIN users ou ON t.offered_by = ou.id
+	LEFT JOIN users au ON t.accepted_by = au.id
+	LEFT JOIN assignments xa ON t.exchange_assignment_id = xa.id
+	LEFT JOIN chores xc ON xa.chore_id = xc.id
+`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTrade(row scanner) (models.AssignmentTrade, error) {
+	var t models.AssignmentTrade
+	err := row.Scan(
+		&t.ID, &t.AssignmentID, &t.ChoreName, &t.DueDate, &t.PointsOffered,
+		&t.OfferedBy, &t.OfferedByName, &t.SweetenerPoints, &t.Note, &t.Status,
+		&t.AcceptedBy, &t.AcceptedByName, &t.AcceptedAt,
+		&t.ExchangeAssignmentID, &t.ExchangeChoreName,
+		&t.DecidedBy, &t.DecidedAt, &t.DecisionNotes, &t.CreatedAt, &t.UpdatedAt,
+	)
+	return t, err
+}
+
+// Load returns a trade with its negotiation history. A missing trade returns
+// the driver's no rows error.
+func Load(ctx context.Context, q database.Querier, tradeID uuid.UUID) (models.AssignmentTrade, error) {
+	t, err := scanTrade(q.QueryRow(ctx, selectTrades+" WHERE t.id = $1", tradeID))
+	if err != nil {
+		return t, err
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT e.event_type, e.actor_id, u.display_name, e.notes, e.created_at
+		FROM assignment_trade_events e
+		LEFT JOIN users u ON e.actor_id = u.id
+		WHERE e.trade_id = $1
+		ORDER BY e.created_at, e.id
+	`, tradeID)
+	if err != nil {
+		return t, fmt.Errorf("failed to query trade events: %w", err)
+	}
+	defer rows.Close()
+
+	t.Events = []models.TradeEvent{}
+	for rows.Next() {
+		var e models.TradeEvent
+		if err := rows.Scan(&e.Event, &e.ActorID, &e.ActorName, &e.Notes, &e.CreatedAt); err != nil {
+			return t, fmt.Errorf("failed to parse trade event: %w", err)
+		}
+		t.Events = append(t.Events, e)
+	}
+	return t, rows.Err()
+}
+
+// List returns trades in the given statuses, newest first. With userID set,
+// only trades that user offered or accepted are included.
+func List(ctx context.Context, q database.Querier, statuses []string, userID *uuid.UUID) ([]models.AssignmentTrade, error) {
+	rows, err := q.Query(ctx, selectTrades+`
+		WHERE t.status = ANY($1)
+			AND ($2::uuid IS NULL OR t.offered_by = $2 OR t.accepted_by = $2)
+		ORDER BY t.created_at DESC
+		LIMIT 100
+	`, statuses, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trades: %w", err)
+	}
+	defer rows.Close()
+
+	trades := []models.AssignmentTrade{}
+	for rows.Next() {
+		t, err := scanTrade(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse trade: %w", err)
+		}
+		trades = append(trades, t)
+	}
+	return trades, rows.Err()
+}
+
+// PaySweetener moves the sweetener from the child who offered the trade to
+// the one who took it, with a point transaction on each side
+func PaySweetener(ctx context.Context, q database.Querier, t models.AssignmentTrade, to uuid.UUID) error {
+	if t.SweetenerPoints <= 0 {
+		return nil
+	}
+
+	_, err := ledger.Post(ctx, q, ledger.Entry{
+		UserID:        t.OfferedBy,
+		Points:        -t.SweetenerPoints,
+		Type:          TransactionType,
+		Description:   fmt.Sprintf("Sweetener for trading away: %s", t.ChoreName),
+		AssignmentID:  &t.AssignmentID,
+		RelatedUserID: &to,
+		NoOverdraft:   true,
+	})
+	if errors.Is(err, ledger.ErrInsufficientPoints) {
+		return ErrInsufficientPoints
+	}
+	if err != nil {
+		return fmt.Errorf("failed to deduct sweetener: %w", err)
+	}
+
+	_, err = ledger.Post(ctx, q, ledger.Entry{
+		UserID:        to,
+		Points:        t.SweetenerPoints,
+		Type:          TransactionType,
+		Description:   fmt.Sprintf("Sweetener for taking over: %s", t.ChoreName),
+		AssignmentID:  &t.AssignmentID,
+		RelatedUserID: &t.OfferedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to credit sweetener: %w", err)
+	}
+	return nil
+}
+
+// Complete hands the offered assignment to the child who accepted it, and any
+// swapped assignment the other way, pays the sweetener and closes the trade.
+// Both assignments must still be pending with their original owners.
+func Complete(ctx context.Context, q database.Querier, t models.AssignmentTrade, actorID *uuid.UUID) error {
+	if t.AcceptedBy == nil {
+		return fmt.Errorf("trade has not been accepted")
+	}
+
+	type handover struct {
+		assignmentID uuid.UUID
+		from, to     uuid.UUID
+	}
+	handovers := []handover{{t.AssignmentID, t.OfferedBy, *t.AcceptedBy}}
+	if t.ExchangeAssignmentID != nil {
+		handovers = append(handovers, handover{*t.ExchangeAssignmentID, *t.AcceptedBy, t.OfferedBy})
+	}
+
+	for _, h := range handovers {
+		var status string
+		err := q.QueryRow(ctx,
+			"SELECT status FROM assignments WHERE id = $1 FOR UPDATE",
+			h.assignmentID,
+		).Scan(&status)
+		if err != nil {
+			return fmt.Errorf("failed to query assignment: %w", err)
+		}
+		if status != lifecycle.StatusPending {
+			return ErrStale
+		}
+
+		from := h.from
+		err = lifecycle.Reassign(ctx, q, lifecycle.Change{
+			AssignmentID: h.assignmentID,
+			From:         status,
+			ActorID:      actorID,
+			Notes:        t.Note,
+			Metadata:     map[string]interface{}{"trade_id": t.ID},
+		}, &from, h.to)
+		if errors.Is(err, lifecycle.ErrAssigneeChanged) {
+			return ErrStale
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := PaySweetener(ctx, q, t, *t.AcceptedBy); err != nil {
+		return err
+	}
+
+	_, err := q.Exec(ctx, `
+		UPDATE assignment_trades
+		SET status = $1,
+			updated_at = NOW()
+		WHERE id = $2
+	`, StatusCompleted, t.ID)
+	if err != nil {
+		return fmt.Errorf("failed to complete trade: %w", err)
+	}
+	return Record(ctx, q, t.ID, EventCompleted, actorID, nil)
+}
+
+// CancelFor cancels the live trades that involve an assignment, offered or
+// swapped, because a parent has closed or reassigned it
+func CancelFor(ctx context.Context, q database.Querier, assignmentID uuid.UUID, actorID *uuid.UUID, notes *string) error {
+	rows, err := q.Query(ctx, `
+		UPDATE assignment_trades
+		SET status = $1,
+			updated_at = NOW()
+		WHERE (assignment_id = $2 OR exchange_assignment_id = $2)
+			AND status = ANY($3)
+		RETURNING id
+	`, StatusCancelled, assignmentID, []string{StatusOpen, StatusPendingApproval})
+	if err != nil {
+		return fmt.Errorf("failed to cancel trades: %w", err)
+	}
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := Record(ctx, q, id, EventCancelled, actorID, notes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Package verification decides whether a child's completed chore can skip
+// parent verification, based on the child's trust level and the family's
+// verification policy.
+package verification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/JunoAX/housepoints-go/internal/database"
+	"github.com/google/uuid"
+)
+
+// Trust levels. A child is only considered for auto-approval when
+// users.auto_approve_work is set; the level then decides which chores qualify.
+const (
+	// TrustStandard auto-approves chores that do not require verification
+	TrustStandard = "standard"
+	// TrustHigh also auto-approves chores that require verification
+	TrustHigh = "high"
+)
+
+// Policy is the family-wide auto-approval configuration, stored in
+// system_settings
+type Policy struct {
+	// MaxPoints caps the points of auto-approved chores; 0 means no cap
+	MaxPoints int `json:"auto_approve_max_points"`
+	// SpotCheckPercent of otherwise auto-approved completions still go to a parent
+	SpotCheckPercent int `json:"auto_approve_spot_check_percent"`
+	// RevokeOnRejection turns auto-approval off when a parent rejects work
+	RevokeOnRejection bool `json:"auto_approve_revoke_on_rejection"`
+}
+
+// Subject is the child whose work is being judged
+type Subject struct {
+	AutoApprove bool
+	TrustLevel  string
+}
+
+// Work is the completed assignment
+type Work struct {
+	Points               int
+	RequiresVerification bool
+}
+
+// Decision is the outcome of the policy
+type Decision struct {
+	AutoApprove bool   `json:"auto_approved"`
+	SpotCheck   bool   `json:"spot_check"`
+	Reason      string `json:"reason"`
+}
+
+// Decide applies the policy. roll is a uniform random number in [0, 1) used
+// for spot-checks.
+func Decide(p Policy, s Subject, w Work, roll float64) Decision {
+	if !s.AutoApprove {
+		return Decision{Reason: "child is not trusted for auto-approval"}
+	}
+	if w.RequiresVerification && s.TrustLevel != TrustHigh {
+		return Decision{Reason: "chore requires verification and child has standard trust"}
+	}
+	if p.MaxPoints > 0 && w.Points > p.MaxPoints {
+		return Decision{Reason: fmt.Sprintf("chore is worth %d points, above the %d point auto-approve limit", w.Points, p.MaxPoints)}
+	}
+	if p.SpotCheckPercent > 0 && roll*100 < float64(p.SpotCheckPercent) {
+		return Decision{SpotCheck: true, Reason: fmt.Sprintf("random spot-check (%d%% of trusted completions)", p.SpotCheckPercent)}
+	}
+	return Decision{AutoApprove: true, Reason: fmt.Sprintf("auto-approved for %s trust", s.TrustLevel)}
+}
+
+// LoadPolicy reads the policy from system_settings
+func LoadPolicy(ctx context.Context, q database.Querier) Policy {
+	return Policy{
+		MaxPoints:         database.SettingInt(ctx, q, "auto_approve_max_points", 0),
+		SpotCheckPercent:  database.SettingInt(ctx, q, "auto_approve_spot_check_percent", 10),
+		RevokeOnRejection: database.SettingBool(ctx, q, "auto_approve_revoke_on_rejection", true),
+	}
+}
+
+// LoadSubject reads a user's trust settings
+func LoadSubject(ctx context.Context, q database.Querier, userID uuid.UUID) (Subject, error) {
+	var s Subject
+	err := q.QueryRow(ctx, `
+		SELECT COALESCE(auto_approve_work, false), COALESCE(trust_level, 'standard')
+		FROM users
+		WHERE id = $1
+	`, userID).Scan(&s.AutoApprove, &s.TrustLevel)
+	return s, err
+}
+
+// Revoke turns off auto-approval for a user after rejected work. It reports
+// whether the user was trusted before.
+func Revoke(ctx context.Context, q database.Querier, userID uuid.UUID, reason string) (bool, error) {
+	result, err := q.Exec(ctx, `
+		UPDATE users
+		SET auto_approve_work = false,
+			trust_revoked_at = NOW(),
+			trust_revoked_reason = $1,
+			updated_at = NOW()
+		WHERE id = $2 AND auto_approve_work = true
+	`, reason, userID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
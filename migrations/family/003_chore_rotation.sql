@@ -0,0 +1,23 @@
+-- Migration: Chore rotation engine
+-- Records why each child received each rotation-eligible chore so parents can
+-- review the distribution after the fact.
+
+CREATE TABLE IF NOT EXISTS rotation_decisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id UUID NOT NULL,
+    assignment_id UUID REFERENCES assignments(id) ON DELETE SET NULL,
+    chore_id UUID NOT NULL REFERENCES chores(id) ON DELETE CASCADE,
+    assigned_to UUID REFERENCES users(id) ON DELETE SET NULL,
+    assignment_date DATE NOT NULL,
+    window_days INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    candidates JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rotation_decisions_date ON rotation_decisions(assignment_date DESC);
+CREATE INDEX IF NOT EXISTS idx_rotation_decisions_run ON rotation_decisions(run_id);
+CREATE INDEX IF NOT EXISTS idx_rotation_decisions_assignment ON rotation_decisions(assignment_id);
+
+COMMENT ON TABLE rotation_decisions IS 'Explanation of each rotation engine assignment, one row per chore per day';
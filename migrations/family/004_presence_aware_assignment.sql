@@ -0,0 +1,34 @@
+-- Migration: Presence-aware assignment
+-- Audit trail for assignments moved because the assignee was away according to
+-- family_schedule, plus bookkeeping for the per-family daily job runner.
+
+CREATE TABLE IF NOT EXISTS presence_adjustments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('reassigned', 'deferred', 'unresolved')),
+    from_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    to_user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    from_due_date TIMESTAMPTZ NOT NULL,
+    to_due_date TIMESTAMPTZ,
+    reason TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_presence_adjustments_assignment ON presence_adjustments(assignment_id);
+CREATE INDEX IF NOT EXISTS idx_presence_adjustments_created ON presence_adjustments(created_at DESC);
+
+-- One row per job per family-local day; used for idempotency and backfill.
+-- Family databases already have an unrelated job_runs table, hence the prefix.
+CREATE TABLE IF NOT EXISTS family_job_runs (
+    job_name VARCHAR(100) NOT NULL,
+    run_date DATE NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'completed', 'failed')),
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    error TEXT,
+    PRIMARY KEY (job_name, run_date)
+);
+
+COMMENT ON TABLE presence_adjustments IS 'Assignments reassigned or deferred because the assignee was away';
+COMMENT ON TABLE family_job_runs IS 'Daily background job runs per family-local date';
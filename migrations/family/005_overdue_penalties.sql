@@ -0,0 +1,19 @@
+-- Migration: Overdue detection, penalties and streaks
+-- Assignments past their due date move to 'overdue', then to 'missed' after a
+-- grace period. Missed chores with penalty_points record a negative 'penalty'
+-- point transaction, at most once per assignment and child. A team's penalty
+-- is split across its participants.
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_point_transactions_penalty_assignment
+    ON point_transactions(related_assignment_id, user_id)
+    WHERE transaction_type = 'penalty';
+
+CREATE INDEX IF NOT EXISTS idx_assignments_status_due ON assignments(status, due_date);
+
+-- missed_grace_days: days an overdue assignment can still be completed
+-- penalties_enabled: apply chore penalty_points when an assignment is missed
+INSERT INTO system_settings (setting_key, setting_value, setting_type)
+VALUES
+    ('missed_grace_days', '1', 'int'),
+    ('penalties_enabled', 'true', 'bool')
+ON CONFLICT (setting_key) DO NOTHING;
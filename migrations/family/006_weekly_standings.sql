@@ -0,0 +1,21 @@
+-- Migration: Weekly points rollover
+-- Each week's standings are snapshotted before users.weekly_points is reset,
+-- so past weekly leaderboards and winners stay queryable.
+
+CREATE TABLE IF NOT EXISTS weekly_standings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    week_start DATE NOT NULL,
+    week_end DATE NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rank INTEGER NOT NULL,
+    points INTEGER NOT NULL DEFAULT 0,
+    chores_completed INTEGER NOT NULL DEFAULT 0,
+    is_winner BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (week_start, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_weekly_standings_week ON weekly_standings(week_start DESC, rank);
+CREATE INDEX IF NOT EXISTS idx_weekly_standings_user ON weekly_standings(user_id, week_start DESC);
+
+COMMENT ON TABLE weekly_standings IS 'Snapshot of each completed week''s leaderboard, taken at rollover';
@@ -0,0 +1,28 @@
+-- Migration: Assignment state machine
+-- Every assignment status change is recorded with its actor, notes and points
+-- delta. Rejections move assignments to 'needs_rework' (see 013) instead of
+-- silently resetting them to 'pending'.
+
+CREATE TABLE IF NOT EXISTS assignment_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    event_type VARCHAR(30) NOT NULL,
+    from_status VARCHAR(30),
+    to_status VARCHAR(30),
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    notes TEXT,
+    points_delta INTEGER NOT NULL DEFAULT 0,
+    metadata JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_events_assignment ON assignment_events(assignment_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_assignment_events_actor ON assignment_events(actor_id, created_at DESC);
+
+-- Seed a creation event for assignments that predate the history
+INSERT INTO assignment_events (assignment_id, event_type, from_status, to_status, actor_id, created_at)
+SELECT a.id, 'created', NULL, a.status, a.assigned_by, a.created_at
+FROM assignments a
+WHERE NOT EXISTS (SELECT 1 FROM assignment_events e WHERE e.assignment_id = a.id);
+
+COMMENT ON TABLE assignment_events IS 'Status transitions and other lifecycle events for each assignment';
@@ -0,0 +1,21 @@
+-- Migration: Photo proof for assignments
+-- Photos are stored in blob storage (local filesystem or S3-compatible) after
+-- EXIF stripping; this table keeps their keys and who uploaded them.
+
+CREATE TABLE IF NOT EXISTS assignment_photos (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('before', 'after')),
+    storage_key TEXT NOT NULL,
+    thumbnail_key TEXT NOT NULL,
+    content_type VARCHAR(50) NOT NULL DEFAULT 'image/jpeg',
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_photos_assignment ON assignment_photos(assignment_id, kind, created_at);
+
+COMMENT ON TABLE assignment_photos IS 'Before/after photo proof uploaded for assignments';
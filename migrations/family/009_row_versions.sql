@@ -0,0 +1,26 @@
+-- Migration: Optimistic concurrency for assignments and rewards
+-- Every update bumps a version counter, which the API exposes as an ETag so
+-- clients can send If-Match and get 412 when acting on stale state.
+
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE rewards ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_row_version() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version := OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_assignments_version ON assignments;
+CREATE TRIGGER trg_assignments_version
+    BEFORE UPDATE ON assignments
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+DROP TRIGGER IF EXISTS trg_rewards_version ON rewards;
+CREATE TRIGGER trg_rewards_version
+    BEFORE UPDATE ON rewards
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+COMMENT ON COLUMN assignments.version IS 'Incremented on every update; exposed as the ETag';
+COMMENT ON COLUMN rewards.version IS 'Incremented on every update; exposed as the ETag';
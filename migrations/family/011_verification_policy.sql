@@ -0,0 +1,19 @@
+-- Migration: Verification policy and trust levels
+-- Children with auto_approve_work skip parent verification according to their
+-- trust level and the family policy below. Trust is revoked automatically
+-- when a parent rejects their work.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS trust_level VARCHAR(20) NOT NULL DEFAULT 'standard'
+    CHECK (trust_level IN ('standard', 'high'));
+ALTER TABLE users ADD COLUMN IF NOT EXISTS trust_revoked_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS trust_revoked_reason TEXT;
+
+-- auto_approve_max_points: 0 means no limit
+-- auto_approve_spot_check_percent: share of trusted completions still sent to a parent
+-- auto_approve_revoke_on_rejection: turn auto-approval off after a rejection
+INSERT INTO system_settings (setting_key, setting_value, setting_type)
+VALUES
+    ('auto_approve_max_points', '0', 'int'),
+    ('auto_approve_spot_check_percent', '10', 'int'),
+    ('auto_approve_revoke_on_rejection', 'true', 'bool')
+ON CONFLICT (setting_key) DO NOTHING;
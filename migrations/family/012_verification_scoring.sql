@@ -0,0 +1,22 @@
+-- Migration: Structured verification scoring
+-- Parents rate verified work 1-5 and may give partial credit. Base credit and
+-- each bonus are written as separate point_transactions lines
+-- (chore_completion, quality_bonus, early_bonus, effort_bonus).
+
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS quality_rating SMALLINT
+    CHECK (quality_rating BETWEEN 1 AND 5);
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS partial_credit_percent SMALLINT
+    CHECK (partial_credit_percent BETWEEN 1 AND 100);
+
+CREATE INDEX IF NOT EXISTS idx_assignments_quality
+    ON assignments(assigned_to, verified_at)
+    WHERE quality_rating IS NOT NULL;
+
+-- Bonus percentages are of the credited base points
+INSERT INTO system_settings (setting_key, setting_value, setting_type)
+VALUES
+    ('quality_bonus_percent_4', '10', 'int'),
+    ('quality_bonus_percent_5', '25', 'int'),
+    ('early_completion_bonus_percent', '10', 'int'),
+    ('effort_bonus_max_percent', '50', 'int')
+ON CONFLICT (setting_key) DO NOTHING;
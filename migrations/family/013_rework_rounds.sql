@@ -0,0 +1,58 @@
+-- Migration: Send-back-for-rework flow
+-- Rejected work moves to 'needs_rework' with a reason and an optional redo
+-- deadline. Every completion and review is kept as a round in
+-- assignment_submissions, and children are told about send-backs through
+-- the existing notifications table (body in message, assignment in metadata).
+
+UPDATE assignments SET status = 'needs_rework' WHERE status = 'rejected';
+
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS rework_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS rework_reason TEXT;
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS rework_due_date TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS assignment_submissions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    round INTEGER NOT NULL,
+    submitted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    submitted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completion_notes TEXT,
+    outcome VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (outcome IN ('pending', 'verified', 'needs_rework')),
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMPTZ,
+    review_notes TEXT,
+    rejection_reason TEXT,
+    redo_due_date TIMESTAMPTZ,
+    quality_rating SMALLINT CHECK (quality_rating BETWEEN 1 AND 5),
+    partial_credit_percent SMALLINT CHECK (partial_credit_percent BETWEEN 1 AND 100),
+    points_awarded INTEGER,
+    UNIQUE (assignment_id, round)
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_submissions_reviewed
+    ON assignment_submissions(reviewed_at)
+    WHERE reviewed_at IS NOT NULL;
+
+-- Seed a first round for work completed before rounds were kept
+INSERT INTO assignment_submissions (
+    assignment_id, round, submitted_by, submitted_at, completion_notes, outcome,
+    reviewed_at, review_notes, quality_rating, partial_credit_percent, points_awarded
+)
+SELECT a.id, 1, a.assigned_to, a.completed_at, a.completion_notes,
+    CASE a.status
+        WHEN 'verified' THEN 'verified'
+        WHEN 'needs_rework' THEN 'needs_rework'
+        ELSE 'pending'
+    END,
+    COALESCE(a.verified_at, CASE WHEN a.status = 'needs_rework' THEN a.updated_at END),
+    a.verification_notes, a.quality_rating, a.partial_credit_percent,
+    CASE WHEN a.status = 'verified' THEN a.points_earned END
+FROM assignments a
+WHERE a.completed_at IS NOT NULL
+    AND a.status IN ('pending_verification', 'completed', 'verified', 'needs_rework')
+    AND NOT EXISTS (SELECT 1 FROM assignment_submissions s WHERE s.assignment_id = a.id);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications(user_id, created_at DESC);
+
+COMMENT ON TABLE assignment_submissions IS 'Each completion of an assignment and the parent review of it';
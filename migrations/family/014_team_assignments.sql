@@ -0,0 +1,26 @@
+-- Migration: Shared and team chores
+-- A team assignment lists its children in assignment_participants. Each child
+-- marks their own part done; once everyone has, the assignment goes to
+-- verification and the points are split evenly, by weight or as the parent
+-- decides, with a separate point transaction per child. The first participant
+-- stays in assignments.assigned_to as the lead.
+
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS is_team BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS split_mode VARCHAR(10)
+    CHECK (split_mode IN ('even', 'weighted', 'manual'));
+
+CREATE TABLE IF NOT EXISTS assignment_participants (
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    weight NUMERIC(6, 2) NOT NULL DEFAULT 1 CHECK (weight > 0),
+    position INTEGER NOT NULL DEFAULT 0,
+    completed_at TIMESTAMPTZ,
+    completion_notes TEXT,
+    points_earned INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (assignment_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_participants_user ON assignment_participants(user_id);
+
+COMMENT ON TABLE assignment_participants IS 'Children sharing a team assignment and their part of it';
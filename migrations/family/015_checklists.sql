@@ -0,0 +1,40 @@
+-- Migration: Chore checklists
+-- Chores define ordered checklist items. Each assignment gets its own copy
+-- when it is created, so editing a chore's checklist does not change work
+-- already handed out. Completion is blocked until every required item is
+-- checked, and items can require a photo.
+
+CREATE TABLE IF NOT EXISTS chore_checklist_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    chore_id UUID NOT NULL REFERENCES chores(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    description TEXT,
+    required BOOLEAN NOT NULL DEFAULT true,
+    requires_photo BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (chore_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS assignment_checklist_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    chore_item_id UUID REFERENCES chore_checklist_items(id) ON DELETE SET NULL,
+    position INTEGER NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    description TEXT,
+    required BOOLEAN NOT NULL DEFAULT true,
+    requires_photo BOOLEAN NOT NULL DEFAULT false,
+    checked_at TIMESTAMPTZ,
+    checked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    photo_id UUID REFERENCES assignment_photos(id) ON DELETE SET NULL,
+    UNIQUE (assignment_id, position)
+);
+
+-- Photos taken for a checklist item
+ALTER TABLE assignment_photos DROP CONSTRAINT IF EXISTS assignment_photos_kind_check;
+ALTER TABLE assignment_photos ADD CONSTRAINT assignment_photos_kind_check
+    CHECK (kind IN ('before', 'after', 'checklist'));
+
+COMMENT ON TABLE chore_checklist_items IS 'Ordered checklist steps defined on a chore';
+COMMENT ON TABLE assignment_checklist_items IS 'Per-assignment copy of a chore checklist and its progress';
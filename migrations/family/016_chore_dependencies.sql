@@ -0,0 +1,29 @@
+-- Migration: Chore dependencies
+-- A chore can list prerequisite chores (unload the dishwasher before loading
+-- it). Assignments generated for the same day are linked through
+-- assignment_dependencies, and a dependent assignment stays blocked until
+-- every prerequisite assignment is verified or closed without the work
+-- (missed, skipped, cancelled).
+
+CREATE TABLE IF NOT EXISTS chore_prerequisites (
+    chore_id UUID NOT NULL REFERENCES chores(id) ON DELETE CASCADE,
+    prerequisite_chore_id UUID NOT NULL REFERENCES chores(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (chore_id, prerequisite_chore_id),
+    CHECK (chore_id <> prerequisite_chore_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chore_prerequisites_prerequisite ON chore_prerequisites(prerequisite_chore_id);
+
+CREATE TABLE IF NOT EXISTS assignment_dependencies (
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    prerequisite_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (assignment_id, prerequisite_id),
+    CHECK (assignment_id <> prerequisite_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_dependencies_prerequisite ON assignment_dependencies(prerequisite_id);
+
+COMMENT ON TABLE chore_prerequisites IS 'Chores that must be done before another chore';
+COMMENT ON TABLE assignment_dependencies IS 'Same-day assignments a dependent assignment waits for';
@@ -0,0 +1,26 @@
+-- Migration: Time tracking
+-- Starting an assignment moves it to in_progress and opens a time segment
+-- for the child working on it. Pausing closes the segment and resuming opens
+-- a new one; completing the chore closes whatever is still running. Actual
+-- minutes are compared with chores.estimated_minutes in reports and feed the
+-- suggested estimates.
+
+CREATE TABLE IF NOT EXISTS assignment_time_segments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ended_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_time_segments_assignment ON assignment_time_segments(assignment_id);
+CREATE INDEX IF NOT EXISTS idx_time_segments_user ON assignment_time_segments(user_id, started_at);
+
+-- At most one running timer per child per assignment
+CREATE UNIQUE INDEX IF NOT EXISTS idx_time_segments_running
+    ON assignment_time_segments(assignment_id, user_id)
+    WHERE ended_at IS NULL;
+
+COMMENT ON TABLE assignment_time_segments IS 'Periods a child actively worked on an assignment';
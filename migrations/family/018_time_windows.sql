@@ -0,0 +1,31 @@
+-- Migration: Time windows and late point curves
+-- A chore can carry a time-of-day window (minutes after midnight in the
+-- family timezone) during which it counts as on time. Work finished before
+-- the window opens is early; after it closes points stay full for
+-- late_grace_minutes, then fall linearly to zero at late_cutoff_minutes
+-- (or the chore's own cutoff). The outcome is computed at completion and
+-- stored on the assignment so verification scores the same thing.
+
+ALTER TABLE chores ADD COLUMN IF NOT EXISTS window_start_minute INTEGER
+    CHECK (window_start_minute BETWEEN 0 AND 1440);
+ALTER TABLE chores ADD COLUMN IF NOT EXISTS window_end_minute INTEGER
+    CHECK (window_end_minute BETWEEN 0 AND 1440);
+ALTER TABLE chores ADD COLUMN IF NOT EXISTS late_cutoff_minutes INTEGER
+    CHECK (late_cutoff_minutes >= 0);
+
+ALTER TABLE chores DROP CONSTRAINT IF EXISTS chores_window_check;
+ALTER TABLE chores ADD CONSTRAINT chores_window_check CHECK (
+    (window_start_minute IS NULL AND window_end_minute IS NULL)
+    OR window_start_minute < window_end_minute
+);
+
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS timing VARCHAR(10)
+    CHECK (timing IN ('early', 'on_time', 'late', 'expired'));
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS timing_percent INTEGER
+    CHECK (timing_percent BETWEEN 0 AND 100);
+
+INSERT INTO system_settings (setting_key, setting_value, setting_type)
+VALUES
+    ('late_grace_minutes', '0', 'int'),
+    ('late_cutoff_minutes', '1440', 'int')
+ON CONFLICT (setting_key) DO NOTHING;
@@ -0,0 +1,52 @@
+-- Migration: Assignment trading
+-- A child can offer one of their pending assignments to a sibling, with an
+-- optional points sweetener paid to whoever takes it. The sibling accepts,
+-- optionally handing back one of their own assignments as a swap. When the
+-- family requires it (trade_requires_approval) a parent approves the trade
+-- before the assignments change hands. Every step is kept in
+-- assignment_trade_events, and the handover is recorded as a reassignment in
+-- assignment_events.
+
+CREATE TABLE IF NOT EXISTS assignment_trades (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    assignment_id UUID NOT NULL REFERENCES assignments(id) ON DELETE CASCADE,
+    offered_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    sweetener_points INTEGER NOT NULL DEFAULT 0 CHECK (sweetener_points >= 0),
+    note TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'pending_approval', 'completed', 'rejected', 'cancelled')),
+    accepted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    accepted_at TIMESTAMPTZ,
+    exchange_assignment_id UUID REFERENCES assignments(id) ON DELETE SET NULL,
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    decision_notes TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One live offer per assignment
+CREATE UNIQUE INDEX IF NOT EXISTS idx_assignment_trades_live
+    ON assignment_trades(assignment_id)
+    WHERE status IN ('open', 'pending_approval');
+CREATE INDEX IF NOT EXISTS idx_assignment_trades_status ON assignment_trades(status, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS assignment_trade_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    trade_id UUID NOT NULL REFERENCES assignment_trades(id) ON DELETE CASCADE,
+    event_type VARCHAR(20) NOT NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    notes TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_assignment_trade_events_trade ON assignment_trade_events(trade_id, created_at);
+
+INSERT INTO system_settings (setting_key, setting_value, setting_type)
+VALUES
+    ('trade_requires_approval', 'false', 'bool'),
+    ('trade_max_sweetener', '50', 'int')
+ON CONFLICT (setting_key) DO NOTHING;
+
+COMMENT ON TABLE assignment_trades IS 'Assignments offered by one child to their siblings';
+COMMENT ON TABLE assignment_trade_events IS 'Every step of a trade negotiation';
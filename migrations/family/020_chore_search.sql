@@ -0,0 +1,14 @@
+-- Migration: Chore search
+-- Full-text search over a chore's name, description and instructions, with
+-- the name weighted highest, and an index so tag filters can use the tags
+-- array.
+
+ALTER TABLE chores ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(description, '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(instructions, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_chores_search ON chores USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_chores_tags ON chores USING GIN (tags);
@@ -0,0 +1,37 @@
+-- Migration: Point ledger as the source of truth
+-- Balances on users are a cache of sums over point_transactions, posted by
+-- the server together with each transaction (internal/ledger). A trigger that
+-- also moves balances would count every transaction twice, so drop any
+-- trigger on point_transactions that writes to users. Run the balance
+-- reconciliation afterwards to repair drift it left behind.
+--
+-- The legacy available_points triggers go too. They recompute
+-- available_points as total_points less completed redemptions and
+-- conversions whenever users, reward_redemptions or point_conversions change,
+-- which overwrites what the ledger posts and what reconciliation repairs.
+
+DO $$
+DECLARE
+    t RECORD;
+BEGIN
+    FOR t IN
+        SELECT tg.tgname
+        FROM pg_trigger tg
+        JOIN pg_proc p ON p.oid = tg.tgfoid
+        WHERE tg.tgrelid = 'point_transactions'::regclass
+            AND NOT tg.tgisinternal
+            AND p.prosrc ILIKE '%users%'
+    LOOP
+        EXECUTE format('DROP TRIGGER %I ON point_transactions', t.tgname);
+    END LOOP;
+END $$;
+
+DROP TRIGGER IF EXISTS update_available_on_total ON users;
+DROP TRIGGER IF EXISTS update_available_on_redemption ON reward_redemptions;
+DROP TRIGGER IF EXISTS update_available_on_conversion ON point_conversions;
+DROP FUNCTION IF EXISTS update_available_on_total_change();
+DROP FUNCTION IF EXISTS update_available_points();
+
+-- Reconciliation sums each user's ledger by type
+CREATE INDEX IF NOT EXISTS idx_point_transactions_user_type
+    ON point_transactions(user_id, transaction_type, created_at);
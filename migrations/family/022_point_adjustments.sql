@@ -0,0 +1,35 @@
+-- Migration: Manual point adjustments
+-- Parents correct balances through point_adjustments instead of SQL. Every
+-- adjustment carries a reason code and is posted to the ledger as an
+-- 'adjustment' point transaction whose extra_data links back to it. An
+-- adjustment larger than adjustment_approval_threshold points waits for a
+-- second parent to approve it; 0 posts every adjustment immediately. When
+-- there is no other parent it is posted at once with approval_bypassed set.
+-- Each adjustment and decision is also written to audit_log.
+
+CREATE TABLE IF NOT EXISTS point_adjustments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    points INTEGER NOT NULL CHECK (points <> 0),
+    reason_code VARCHAR(30) NOT NULL,
+    note TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending_approval'
+        CHECK (status IN ('pending_approval', 'posted', 'rejected')),
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    decision_notes TEXT,
+    transaction_id UUID REFERENCES point_transactions(id) ON DELETE SET NULL,
+    approval_bypassed BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_point_adjustments_status ON point_adjustments(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_point_adjustments_user ON point_adjustments(user_id, created_at DESC);
+
+INSERT INTO system_settings (setting_key, setting_value, setting_type)
+VALUES ('adjustment_approval_threshold', '100', 'int')
+ON CONFLICT (setting_key) DO NOTHING;
+
+COMMENT ON TABLE point_adjustments IS 'Manual point corrections made by parents, with their approvals';
@@ -0,0 +1,31 @@
+-- Migration: Transaction reversals
+-- A point transaction is undone by posting a compensating transaction of the
+-- same type with the opposite points, whose reverses_transaction_id is the
+-- original. Each transaction can be reversed once, and a reversal cannot
+-- itself be reversed. Family databases already have an unrelated legacy
+-- point_transactions.reference column, which is left alone.
+
+ALTER TABLE point_transactions
+    ADD COLUMN IF NOT EXISTS reverses_transaction_id UUID;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint WHERE conname = 'point_transactions_reverses_transaction_id_fkey'
+    ) THEN
+        ALTER TABLE point_transactions
+            ADD CONSTRAINT point_transactions_reverses_transaction_id_fkey
+            FOREIGN KEY (reverses_transaction_id) REFERENCES point_transactions(id);
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_point_transactions_reverses
+    ON point_transactions(reverses_transaction_id)
+    WHERE reverses_transaction_id IS NOT NULL;
+
+-- One penalty per assignment and child still holds, but its reversal is a
+-- penalty too
+DROP INDEX IF EXISTS idx_point_transactions_penalty_assignment;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_point_transactions_penalty_assignment
+    ON point_transactions(related_assignment_id, user_id)
+    WHERE transaction_type = 'penalty' AND reverses_transaction_id IS NULL;
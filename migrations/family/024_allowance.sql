@@ -0,0 +1,71 @@
+-- Migration: Allowance bank
+-- Children convert points into money at the family exchange rate, and parents
+-- record payouts. Money is kept in money_transactions, a ledger per child in
+-- minor units of the family currency, separate from points: a child's balance
+-- is the sum of their entries in each currency. A conversion is posted as a
+-- 'conversion' point transaction and a matching money credit. Requests live in
+-- allowance_conversions: family databases already have an unrelated legacy
+-- point_conversions table. A pending request holds its points until decided.
+
+CREATE TABLE IF NOT EXISTS money_transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount_minor BIGINT NOT NULL CHECK (amount_minor <> 0),
+    currency VARCHAR(3) NOT NULL,
+    transaction_type VARCHAR(20) NOT NULL,
+    description TEXT NOT NULL,
+    conversion_id UUID,
+    payout_id UUID,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_money_transactions_user ON money_transactions(user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS allowance_conversions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    points INTEGER NOT NULL CHECK (points > 0),
+    amount_minor BIGINT NOT NULL CHECK (amount_minor > 0),
+    currency VARCHAR(3) NOT NULL,
+    rate_points INTEGER NOT NULL,
+    rate_minor_units INTEGER NOT NULL,
+    note TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'completed', 'rejected', 'cancelled')),
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMPTZ,
+    decision_notes TEXT,
+    point_transaction_id UUID REFERENCES point_transactions(id) ON DELETE SET NULL,
+    money_transaction_id UUID REFERENCES money_transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_allowance_conversions_status ON allowance_conversions(status, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS allowance_payouts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount_minor BIGINT NOT NULL CHECK (amount_minor > 0),
+    currency VARCHAR(3) NOT NULL,
+    method VARCHAR(20) NOT NULL CHECK (method IN ('cash', 'transfer', 'gift_card')),
+    note TEXT,
+    paid_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    money_transaction_id UUID REFERENCES money_transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_allowance_payouts_user ON allowance_payouts(user_id, created_at DESC);
+
+-- conversion_requires_approval: a parent approves each conversion
+-- conversion_min_points: smallest conversion a child can request
+INSERT INTO system_settings (setting_key, setting_value, setting_type)
+VALUES
+    ('conversion_requires_approval', 'true', 'bool'),
+    ('conversion_min_points', '0', 'int')
+ON CONFLICT (setting_key) DO NOTHING;
+
+COMMENT ON TABLE money_transactions IS 'Allowance money ledger per child, in minor units';
+COMMENT ON TABLE allowance_conversions IS 'Requests to turn points into allowance money';
+COMMENT ON TABLE allowance_payouts IS 'Money parents have paid out to children';
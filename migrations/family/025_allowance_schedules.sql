@@ -0,0 +1,42 @@
+-- Migration: Scheduled allowance deposits
+-- A schedule pays a child a fixed amount every week on a chosen weekday,
+-- either in points (an 'allowance' point transaction) or in money (an
+-- 'allowance' money transaction). It can require a minimum completion rate
+-- for the last full week. allowance_deposits records every scheduled day,
+-- paid or skipped, once per schedule, so reruns and backfills never pay twice.
+
+CREATE TABLE IF NOT EXISTS allowance_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('points', 'money')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    weekday INTEGER NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    min_completion_rate NUMERIC(5, 1) CHECK (min_completion_rate BETWEEN 0 AND 100),
+    description TEXT,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_allowance_schedules_weekday ON allowance_schedules(weekday) WHERE active = true;
+
+CREATE TABLE IF NOT EXISTS allowance_deposits (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES allowance_schedules(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    deposit_date DATE NOT NULL,
+    kind VARCHAR(10) NOT NULL,
+    amount BIGINT NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('paid', 'skipped')),
+    completion_rate NUMERIC(5, 1),
+    point_transaction_id UUID REFERENCES point_transactions(id) ON DELETE SET NULL,
+    money_transaction_id UUID REFERENCES money_transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (schedule_id, deposit_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_allowance_deposits_user ON allowance_deposits(user_id, deposit_date DESC);
+
+COMMENT ON TABLE allowance_schedules IS 'Recurring weekly allowance per child, in points or money';
+COMMENT ON TABLE allowance_deposits IS 'One row per schedule and scheduled day, paid or skipped';
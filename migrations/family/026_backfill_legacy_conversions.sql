@@ -0,0 +1,42 @@
+-- Migration: Post legacy conversions to the point ledger
+-- Conversions recorded by older versions live only in the legacy
+-- point_conversions table. The balances on users already account for them,
+-- but the ledger does not, so reconciliation would repair total_points_converted
+-- to zero and hand the converted points back. Post each one as a 'conversion'
+-- transaction dated when it happened. Balances are left alone: this only
+-- brings the ledger in line with them. Rows already posted are skipped, so the
+-- migration can be rerun.
+
+DO $$
+BEGIN
+    IF to_regclass('point_conversions') IS NULL THEN
+        RETURN;
+    END IF;
+
+    INSERT INTO point_transactions (
+        id, user_id, points, transaction_type, description,
+        related_user_id, extra_data, created_at
+    )
+    SELECT
+        gen_random_uuid(),
+        pc.user_id,
+        -pc.points_converted,
+        'conversion',
+        format('Converted to $%s', pc.dollar_amount),
+        pc.converted_by,
+        jsonb_build_object(
+            'legacy_conversion_id', pc.id,
+            'dollar_amount', pc.dollar_amount,
+            'conversion_rate', pc.conversion_rate,
+            'payment_method', pc.payment_method
+        ),
+        COALESCE(pc.created_at, NOW())
+    FROM point_conversions pc
+    WHERE pc.user_id IS NOT NULL
+        AND pc.points_converted <> 0
+        AND NOT EXISTS (
+            SELECT 1 FROM point_transactions pt
+            WHERE pt.transaction_type = 'conversion'
+                AND pt.extra_data->>'legacy_conversion_id' = pc.id::text
+        );
+END $$;
@@ -0,0 +1,22 @@
+-- Platform Database Schema
+-- Version: 002
+-- Description: Per-family settings used by background jobs (timezone, week start, currency)
+
+CREATE TABLE IF NOT EXISTS family_settings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    family_id UUID NOT NULL UNIQUE REFERENCES families(id) ON DELETE CASCADE,
+
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    week_start_day INTEGER NOT NULL DEFAULT 1 CHECK (week_start_day BETWEEN 0 AND 6),
+    theme_color VARCHAR(20) NOT NULL DEFAULT '#3498db',
+    custom_domain VARCHAR(255),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_family_settings_updated_at BEFORE UPDATE ON family_settings
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE family_settings IS 'Family-level configuration such as timezone and week start';
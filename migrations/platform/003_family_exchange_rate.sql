@@ -0,0 +1,9 @@
+-- Platform Database Schema
+-- Version: 003
+-- Description: Family exchange rate for converting points to allowance money.
+-- exchange_points points are worth exchange_minor_units of the family's
+-- currency in minor units (cents); 0 points turns conversion off.
+
+ALTER TABLE family_settings
+    ADD COLUMN IF NOT EXISTS exchange_points INTEGER NOT NULL DEFAULT 0 CHECK (exchange_points >= 0),
+    ADD COLUMN IF NOT EXISTS exchange_minor_units INTEGER NOT NULL DEFAULT 0 CHECK (exchange_minor_units >= 0);